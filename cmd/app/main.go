@@ -3,10 +3,22 @@ package main
 import (
 	_ "github.com/mebr0/tiny-url/docs"
 	"github.com/mebr0/tiny-url/internal/app"
+	"os"
+	// Time zones of statistics are resolved even on images without zoneinfo
+	_ "time/tzdata"
 )
 
 const configPath = "configs/main.yml"
 
 func main() {
+	// Only apply migrations of database with "migrate" subcommand
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := app.Migrate(configPath); err != nil {
+			os.Exit(1)
+		}
+
+		return
+	}
+
 	app.Run(configPath)
 }
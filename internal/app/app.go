@@ -3,17 +3,27 @@ package app
 import (
 	"context"
 	"errors"
-	"github.com/mebr0/tiny-url/internal/cache"
+	"fmt"
 	"github.com/mebr0/tiny-url/internal/config"
+	"github.com/mebr0/tiny-url/internal/domain"
 	"github.com/mebr0/tiny-url/internal/handler"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/metrics"
 	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/internal/server"
 	"github.com/mebr0/tiny-url/internal/service"
+	"github.com/mebr0/tiny-url/internal/tracing"
 	"github.com/mebr0/tiny-url/pkg/auth"
-	"github.com/mebr0/tiny-url/pkg/cache/redis"
-	"github.com/mebr0/tiny-url/pkg/database/mongodb"
+	"github.com/mebr0/tiny-url/pkg/bot"
+	"github.com/mebr0/tiny-url/pkg/geo"
 	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/mebr0/tiny-url/pkg/metadata"
+	"github.com/mebr0/tiny-url/pkg/probe"
+	"github.com/mebr0/tiny-url/pkg/safehttp"
+	"github.com/mebr0/tiny-url/pkg/webhook"
 	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/signal"
@@ -37,25 +47,60 @@ func Run(configPath string) {
 	// Load configs
 	cfg := config.LoadConfig(configPath)
 
+	if err := logging.Configure(cfg.Log.Level, cfg.Log.Redact); err != nil {
+		log.Error(err)
+		return
+	}
+
+	// Seed rotation of URL variants
+	rand.Seed(time.Now().UnixNano())
+
+	// Spans are not recorded without exporter
+	exporter, err := tracing.NewExporter(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint,
+		cfg.Tracing.Insecure)
+
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	if exporter != nil {
+		tracerProvider := tracing.NewProvider(exporter)
+
+		defer func() {
+			if err := tracerProvider.Shutdown(context.Background()); err != nil {
+				log.Errorf("failed to flush spans: %v", err)
+			}
+		}()
+	}
+
 	// Deps
-	mongoClient, err := mongodb.NewClient(cfg.Mongo.URI, cfg.Mongo.User, cfg.Mongo.Password)
+	store, err := newStorage(cfg)
 
 	if err != nil {
 		log.Error(err)
 		return
 	}
 
-	redisClient, err := redis.NewClient(cfg.Redis.URI, cfg.Redis.Password, cfg.Redis.Database)
+	cacheStore, err := newCaches(cfg)
 
 	if err != nil {
 		log.Error(err)
 		return
 	}
 
-	db := mongoClient.Database(cfg.Mongo.Name)
+	if err := store.migrateSchema(); err != nil {
+		log.Error(err)
+		return
+	}
 
 	passwordHasher := hash.NewSHA1PasswordHasher(cfg.Auth.PasswordSalt)
 	urlHasher := hash.NewMD5URLEncoder()
+	// Addresses given by users are fetched only if they are public
+	outbound := safehttp.NewTransport()
+	metadataFetcher := metadata.NewHTMLFetcher(cfg.Metadata.Timeout, outbound)
+	healthProber := probe.NewHTTPProber(cfg.Health.Timeout, cfg.Health.HostInterval, outbound)
+	webhookSender := webhook.NewHTTPSender(cfg.Webhook.Timeout, outbound)
 
 	tokenManager, err := auth.NewJWTManager(cfg.Auth.JWT.Key)
 
@@ -64,11 +109,97 @@ func Run(configPath string) {
 		return
 	}
 
+	mailer, err := newMailer(cfg)
+
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	successor, err := checkDeletionPolicy(cfg, store.repos.Users)
+
+	if err != nil {
+		log.Error(err)
+		return
+	}
+
+	// Country of clients is unknown without geo database
+	var geoResolver geo.Resolver = geo.NewNopResolver()
+
+	if cfg.Geo.Database != "" {
+		geoResolver, err = geo.NewCIDRResolver(cfg.Geo.Database)
+
+		if err != nil {
+			log.Error(err)
+			return
+		}
+	}
+
+	// Bots are detected by built-in signatures only without signatures file
+	var botDetector bot.Detector = bot.NewSignatureDetector(bot.DefaultSignatures)
+	var botSignatures *bot.FileDetector
+
+	if cfg.Bot.Signatures != "" {
+		botSignatures, err = bot.NewFileDetector(cfg.Bot.Signatures)
+
+		if err != nil {
+			log.Error(err)
+			return
+		}
+
+		botDetector = botSignatures
+	}
+
 	// Init handlers
-	repos := repo.NewRepos(db)
-	caches := cache.NewCaches(redisClient, cfg.Redis.TTL)
-	services := service.NewServices(repos, caches, passwordHasher, tokenManager, urlHasher, cfg.Auth.AccessTokenTTL, cfg.URL.AliasLength, cfg.URL.DefaultExpiration, cfg.URL.CountLimit)
-	handlers := handler.NewHandler(services, tokenManager)
+	repos := store.repos
+	services := service.NewServices(service.Deps{
+		Repos:               repos,
+		Caches:              cacheStore.caches,
+		Hasher:              passwordHasher,
+		TokenManager:        tokenManager,
+		URLEncoder:          urlHasher,
+		MetadataFetcher:     metadataFetcher,
+		HealthProber:        healthProber,
+		WebhookSender:       webhookSender,
+		Mailer:              mailer,
+		AccessTokenTTL:      cfg.Auth.AccessTokenTTL,
+		VerificationTTL:     cfg.Auth.VerificationTokenTTL,
+		PasswordResetTTL:    cfg.Auth.PasswordResetTokenTTL,
+		MailLinkURL:         cfg.Mail.LinkURL,
+		UsersDeletionPolicy: cfg.Users.DeletionPolicy,
+		UsersSuccessor:      successor,
+		AliasLength:         cfg.URL.AliasLength,
+		DefaultExpiration:   cfg.URL.DefaultExpiration,
+		URLCountLimit:       cfg.URL.CountLimit,
+		MetadataWorkers:     cfg.Metadata.Workers,
+		MetadataQueueSize:   cfg.Metadata.QueueSize,
+		HealthInterval:      cfg.Health.Interval,
+		HealthConcurrency:   cfg.Health.Concurrency,
+		ExpirationInterval:  cfg.URL.ExpirationInterval,
+		WebhookWorkers:      cfg.Webhook.Workers,
+		WebhookBatchSize:    cfg.Webhook.BatchSize,
+		WebhookPollInterval: cfg.Webhook.PollInterval,
+		WebhookMaxAttempts:  cfg.Webhook.MaxAttempts,
+		WebhookBackoff:      cfg.Webhook.Backoff,
+		Dependencies:        dependencies(store, cacheStore),
+		ReadinessTimeout:    cfg.Readiness.Timeout,
+		AuditAdmins:         cfg.Auth.Admins,
+	})
+	handlers := handler.NewHandler(services, tokenManager, geoResolver, botDetector)
+
+	// Background workers
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	go cacheStore.caches.Run(workersCtx)
+	go services.Metadata.Run(workersCtx)
+	go services.Health.Run(workersCtx)
+	go services.URLs.Run(workersCtx)
+	go services.Webhooks.Run(workersCtx)
+
+	if botSignatures != nil {
+		go reloadBotSignatures(workersCtx, botSignatures, cfg.Bot.ReloadInterval)
+	}
 
 	// HTTP Server
 	srv := server.NewServer(cfg, handlers.Init(cfg))
@@ -78,6 +209,18 @@ func Run(configPath string) {
 		}
 	}()
 
+	// Metrics server is disabled without port
+	var metricsSrv *server.Server
+
+	if cfg.Metrics.Port != "" {
+		metricsSrv = server.NewMetricsServer(cfg, metrics.Handler())
+		go func() {
+			if err := metricsSrv.Run(); !errors.Is(err, http.ErrServerClosed) {
+				log.Errorf("error occurred while running metrics server: %s\n", err.Error())
+			}
+		}()
+	}
+
 	log.Info("Server started")
 
 	// Graceful Shutdown
@@ -86,6 +229,10 @@ func Run(configPath string) {
 
 	<-quit
 
+	// Instance is reported as not ready, so orchestrator stops routing requests to it before server stops
+	services.Readiness.Stop()
+	time.Sleep(cfg.Readiness.ShutdownDelay)
+
 	const timeout = 5 * time.Second
 
 	ctx, shutdown := context.WithTimeout(context.Background(), timeout)
@@ -95,11 +242,129 @@ func Run(configPath string) {
 		log.Errorf("failed to stop server: %v", err)
 	}
 
-	if err := mongoClient.Disconnect(context.Background()); err != nil {
-		log.Errorf("failed to disconnect from mongo: %v", err)
+	if metricsSrv != nil {
+		if err := metricsSrv.Stop(ctx); err != nil {
+			log.Errorf("failed to stop metrics server: %v", err)
+		}
+	}
+
+	stopWorkers()
+	services.Wait()
+
+	if err := store.close(context.Background()); err != nil {
+		log.Errorf("failed to disconnect from database: %v", err)
+	}
+
+	if err := cacheStore.close(); err != nil {
+		log.Errorf("failed to disconnect from cache: %v", err)
+	}
+}
+
+// checkDeletionPolicy fails on unknown policy for urls of deleted users, transfer without successor or successor
+// not registered, urls are deleted by default. Returns id of successor, zero one without successor
+func checkDeletionPolicy(cfg *config.Config, users repo.Users) (primitive.ObjectID, error) {
+	switch cfg.Users.DeletionPolicy {
+	case "", domain.UserURLsDelete:
+	case domain.UserURLsTransfer:
+		if cfg.Users.Successor == "" {
+			return primitive.ObjectID{}, errors.New("successor is required to transfer urls of deleted users")
+		}
+	default:
+		return primitive.ObjectID{}, fmt.Errorf("unknown deletion policy %s", cfg.Users.DeletionPolicy)
+	}
+
+	if cfg.Users.Successor == "" {
+		return primitive.ObjectID{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	successor, err := users.GetByEmail(ctx, cfg.Users.Successor)
+
+	if err != nil {
+		if err == repo.ErrUserNotFound {
+			return primitive.ObjectID{}, fmt.Errorf("successor %s is not registered", cfg.Users.Successor)
+		}
+
+		return primitive.ObjectID{}, err
 	}
 
-	if err := redisClient.Close(); err != nil {
-		log.Errorf("failed to disconnect from redis: %v", err)
+	return successor.ID, nil
+}
+
+// reloadBotSignatures reads changed signatures file every interval until ctx is done,
+// previous signatures are kept if file cannot be read
+func reloadBotSignatures(ctx context.Context, detector *bot.FileDetector, interval time.Duration) {
+	if interval <= 0 {
+		return
 	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := detector.Reload()
+
+			if err != nil {
+				log.WithError(err).Warn("Could not reload bot signatures")
+				continue
+			}
+
+			if reloaded {
+				log.Info("Bot signatures reloaded")
+			}
+		}
+	}
+}
+
+// dependencies of instance checked by readiness probe
+func dependencies(store *storage, cacheStore *caches) []service.Dependency {
+	deps := make([]service.Dependency, 0, 2)
+
+	if store.ping != nil {
+		deps = append(deps, service.Dependency{Name: store.name, Ping: store.ping})
+	}
+
+	if cacheStore.ping != nil {
+		deps = append(deps, service.Dependency{Name: cacheStore.name, Ping: cacheStore.ping})
+	}
+
+	return deps
+}
+
+// Migrate applies migrations of database without starting application
+func Migrate(configPath string) error {
+	cfg := config.LoadConfig(configPath)
+
+	if err := logging.Configure(cfg.Log.Level, cfg.Log.Redact); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	store, err := newStorage(cfg)
+
+	if err != nil {
+		log.Error(err)
+		return err
+	}
+
+	defer func() {
+		if err := store.close(context.Background()); err != nil {
+			log.Errorf("failed to disconnect from database: %v", err)
+		}
+	}()
+
+	if err := store.migrateSchema(); err != nil {
+		log.Error(err)
+		return err
+	}
+
+	log.Info("Migrations finished")
+
+	return nil
 }
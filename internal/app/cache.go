@@ -0,0 +1,59 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/cache"
+	"github.com/mebr0/tiny-url/internal/config"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/metrics"
+	"github.com/mebr0/tiny-url/pkg/cache/redis"
+)
+
+// Supported drivers of cache, redis is used by default
+const (
+	cacheDriverRedis  = "redis"
+	cacheDriverMemory = "memory"
+)
+
+// caches chosen by cache driver with closing of their connection.
+// In-memory caches are not pinged
+type caches struct {
+	name   string
+	caches *cache.Caches
+	ping   func(ctx context.Context) error
+	close  func() error
+}
+
+func newCaches(cfg *config.Config) (*caches, error) {
+	switch cfg.Cache.Driver {
+	case "", cacheDriverRedis:
+		redisClient, err := redis.NewClient(cfg.Redis.URI, cfg.Redis.Password, cfg.Redis.Database)
+
+		if err != nil {
+			return nil, err
+		}
+
+		redisClient.AddHook(metrics.RedisHook{})
+		redisClient.AddHook(logging.RedisHook{})
+
+		return &caches{
+			name:   cacheDriverRedis,
+			caches: cache.NewRedisCaches(redisClient, cfg.Redis.TTL, cfg.Cache.MissingTTL, cfg.Cache.Size, cfg.Cache.TTL),
+			ping: func(ctx context.Context) error {
+				return redisClient.Ping(ctx).Err()
+			},
+			close: redisClient.Close,
+		}, nil
+	case cacheDriverMemory:
+		return &caches{
+			name:   cacheDriverMemory,
+			caches: cache.NewMemoryCaches(cfg.Cache.Size, cfg.Cache.TTL, cfg.Cache.MissingTTL),
+			close: func() error {
+				return nil
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown cache driver %s", cfg.Cache.Driver)
+	}
+}
@@ -0,0 +1,43 @@
+package app
+
+import (
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/config"
+	"github.com/mebr0/tiny-url/pkg/mail"
+	log "github.com/sirupsen/logrus"
+	"os"
+)
+
+// Supported drivers of mail, emails are written to log by default
+const (
+	mailDriverSMTP = "smtp"
+	mailDriverFile = "file"
+	mailDriverLog  = "log"
+)
+
+func newMailer(cfg *config.Config) (mail.Sender, error) {
+	switch cfg.Mail.Driver {
+	case mailDriverSMTP:
+		return mail.NewSMTPSender(cfg.Mail.SMTP.Host, cfg.Mail.SMTP.Port, cfg.Mail.SMTP.Username,
+			cfg.Mail.SMTP.Password, cfg.Mail.From)
+	case mailDriverFile:
+		if err := os.MkdirAll(cfg.Mail.Dir, 0700); err != nil {
+			return nil, err
+		}
+
+		return mail.NewFileSender(cfg.Mail.Dir, cfg.Mail.From), nil
+	case "", mailDriverLog:
+		return mail.NewWriterSender(mailLog{}, cfg.Mail.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %s", cfg.Mail.Driver)
+	}
+}
+
+// mailLog writes every email as single log entry
+type mailLog struct{}
+
+func (mailLog) Write(p []byte) (int, error) {
+	log.WithField("email", string(p)).Info("Email is written to log instead of sending")
+
+	return len(p), nil
+}
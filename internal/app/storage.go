@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/config"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/metrics"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/database/boltdb"
+	"github.com/mebr0/tiny-url/pkg/database/mongodb"
+	"github.com/mebr0/tiny-url/pkg/database/postgres"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+	"time"
+)
+
+// Supported drivers of storage, mongo is used by default
+const (
+	driverMongo    = "mongo"
+	driverPostgres = "postgres"
+	driverBolt     = "bolt"
+)
+
+// storage is connection to database chosen by storage driver.
+// Embedded database is not pinged, since it is always available after opening
+type storage struct {
+	name    string
+	repos   *repo.Repos
+	migrate func(ctx context.Context) ([]int, error)
+	ping    func(ctx context.Context) error
+	close   func(ctx context.Context) error
+}
+
+func newStorage(cfg *config.Config) (*storage, error) {
+	switch cfg.Storage.Driver {
+	case "", driverMongo:
+		// Commands are traced without their contents, which may contain credentials of users
+		monitor := options.Client().SetMonitor(mongodb.ChainMonitors(
+			metrics.MongoMonitor(),
+			logging.MongoMonitor(),
+			otelmongo.NewMonitor(otelmongo.WithCommandAttributeDisabled(true)),
+		))
+		mongoClient, err := mongodb.NewClient(cfg.Mongo.URI, cfg.Mongo.User, cfg.Mongo.Password, monitor)
+
+		if err != nil {
+			return nil, err
+		}
+
+		db := mongoClient.Database(cfg.Mongo.Name)
+
+		return &storage{
+			name:  driverMongo,
+			repos: repo.NewMongoRepos(db),
+			migrate: func(ctx context.Context) ([]int, error) {
+				return repo.MigrateMongo(ctx, db)
+			},
+			ping: func(ctx context.Context) error {
+				return mongoClient.Ping(ctx, nil)
+			},
+			close: mongoClient.Disconnect,
+		}, nil
+	case driverPostgres:
+		db, err := postgres.NewClient(cfg.Postgres.DSN)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage{
+			name:  driverPostgres,
+			repos: repo.NewPostgresRepos(db),
+			migrate: func(ctx context.Context) ([]int, error) {
+				return repo.MigratePostgres(ctx, db)
+			},
+			ping:  db.PingContext,
+			close: closeSQL(db),
+		}, nil
+	case driverBolt:
+		db, err := boltdb.NewClient(cfg.Storage.DataDir)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return &storage{
+			name:  driverBolt,
+			repos: repo.NewBoltRepos(db),
+			migrate: func(ctx context.Context) ([]int, error) {
+				return repo.MigrateBolt(db)
+			},
+			close: func(ctx context.Context) error {
+				return db.Close()
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown storage driver %s", cfg.Storage.Driver)
+	}
+}
+
+// migrateSchema applies migrations of database and logs applied versions
+func (s *storage) migrateSchema() error {
+	const timeout = 5 * time.Minute
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	versions, err := s.migrate(ctx)
+
+	for _, version := range versions {
+		log.Infof("Migration %d applied", version)
+	}
+
+	return err
+}
+
+func closeSQL(db *sql.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.Close()
+	}
+}
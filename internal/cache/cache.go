@@ -9,18 +9,69 @@ import (
 
 //go:generate mockgen -source=cache.go -destination=mocks/mock.go
 
+// URLs cache returns ErrNotFound on miss and ErrMissing for aliases known to be missing in database
 type URLs interface {
 	Set(ctx context.Context, url domain.URL) error
+	SetMissing(ctx context.Context, alias string) error
 	Get(ctx context.Context, alias string) (domain.URL, error)
 	Delete(ctx context.Context, alias string) error
 }
 
+// Invalidator is implemented by caches keeping local copies of urls on every instance. Saved url may be changed
+// or only loaded from database, so changes are announced explicitly
+type Invalidator interface {
+	// Invalidate makes other instances drop local copies of alias older than version, negative version drops any
+	Invalidate(ctx context.Context, alias string, version int64) error
+}
+
 type Caches struct {
 	URLs URLs
+
+	// Listener of invalidations from other instances, if caches need it
+	listen func(ctx context.Context)
 }
 
-func NewCaches(client *redis.Client, defaultTTL time.Duration) *Caches {
+// NewRedisCaches keeps urls in redis and missing aliases for missingTTL. With positive localSize
+// the most used of them are also kept in process memory for localTTL and invalidated through redis on deletion
+func NewRedisCaches(client *redis.Client, defaultTTL time.Duration, missingTTL time.Duration, localSize int, localTTL time.Duration) *Caches {
+	remote := newURLsCache(client, defaultTTL, missingTTL)
+
+	if localSize <= 0 {
+		return &Caches{
+			URLs: remote,
+		}
+	}
+
+	tiered := newURLsTieredCache(newURLsMemoryCache(localSize, localTTL, missingTTL), remote, client)
+
 	return &Caches{
-		URLs: newURLsCache(client, defaultTTL),
+		URLs:   tiered,
+		listen: tiered.Run,
+	}
+}
+
+// NewMemoryCaches keeps at most size urls and missing aliases in process memory, for running without redis
+func NewMemoryCaches(size int, defaultTTL time.Duration, missingTTL time.Duration) *Caches {
+	return &Caches{
+		URLs: newURLsMemoryCache(size, defaultTTL, missingTTL),
+	}
+}
+
+// urlTTL is default TTL cut by expiration of url, so url is not served from cache past its expiration.
+// Already expired urls are kept for default TTL
+func urlTTL(url domain.URL, defaultTTL time.Duration) time.Duration {
+	untilExpiration := time.Until(url.ExpiredAt)
+
+	if untilExpiration > 0 && untilExpiration < defaultTTL {
+		return untilExpiration
+	}
+
+	return defaultTTL
+}
+
+// Run listens for invalidations from other instances until context is done
+func (c *Caches) Run(ctx context.Context) {
+	if c.listen != nil {
+		c.listen(ctx)
 	}
 }
@@ -0,0 +1,8 @@
+package cache
+
+import "errors"
+
+var (
+	ErrNotFound = errors.New("not found in cache")
+	ErrMissing  = errors.New("missing in database")
+)
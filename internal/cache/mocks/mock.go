@@ -77,3 +77,17 @@ func (mr *MockURLsMockRecorder) Set(ctx, url interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockURLs)(nil).Set), ctx, url)
 }
+
+// SetMissing mocks base method.
+func (m *MockURLs) SetMissing(ctx context.Context, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMissing", ctx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMissing indicates an expected call of SetMissing.
+func (mr *MockURLsMockRecorder) SetMissing(ctx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMissing", reflect.TypeOf((*MockURLs)(nil).SetMissing), ctx, alias)
+}
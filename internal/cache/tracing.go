@@ -0,0 +1,36 @@
+package cache
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/mebr0/tiny-url/internal/cache"
+
+var tracer = otel.Tracer(tracerName)
+
+// startSpan starts span of redis operation with url by alias
+func startSpan(ctx context.Context, operation string, alias string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "redis "+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemRedis,
+			semconv.DBOperationKey.String(operation),
+			attribute.String("url.alias", alias),
+		),
+	)
+}
+
+// endSpan ends span marking it failed on error, absence of url in cache is not a failure
+func endSpan(span trace.Span, err error) {
+	if err != nil && err != ErrNotFound && err != ErrMissing {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
@@ -0,0 +1,166 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"sync"
+	"time"
+)
+
+// URLsMemoryCache keeps urls in process memory, evicting least recently used ones above size
+type URLsMemoryCache struct {
+	mu         sync.Mutex
+	size       int
+	defaultTTL time.Duration
+	missingTTL time.Duration
+	items      map[string]*list.Element
+	order      *list.List
+}
+
+// memoryItem of missing alias has no data
+type memoryItem struct {
+	alias     string
+	data      []byte
+	version   int64
+	expiresAt time.Time
+}
+
+func newURLsMemoryCache(size int, defaultTTL time.Duration, missingTTL time.Duration) *URLsMemoryCache {
+	return &URLsMemoryCache{
+		size:       size,
+		defaultTTL: defaultTTL,
+		missingTTL: missingTTL,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+func (c *URLsMemoryCache) Set(ctx context.Context, url domain.URL) error {
+	// Encoded url is kept, so callers never share slices of cached one
+	data, err := url.MarshalBinary()
+
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Newer version of url or missing alias is kept
+	if e, ok := c.items[url.Alias]; ok {
+		item := e.Value.(*memoryItem)
+
+		if !item.expired() && (item.data == nil || item.version > url.Version) {
+			return nil
+		}
+	}
+
+	c.put(&memoryItem{
+		alias:     url.Alias,
+		data:      data,
+		version:   url.Version,
+		expiresAt: time.Now().Add(urlTTL(url, c.defaultTTL)),
+	})
+
+	return nil
+}
+
+func (c *URLsMemoryCache) SetMissing(ctx context.Context, alias string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.put(&memoryItem{
+		alias:     alias,
+		expiresAt: time.Now().Add(c.missingTTL),
+	})
+
+	return nil
+}
+
+func (c *URLsMemoryCache) Get(ctx context.Context, alias string) (domain.URL, error) {
+	var url domain.URL
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[alias]
+
+	if !ok {
+		return url, ErrNotFound
+	}
+
+	item := e.Value.(*memoryItem)
+
+	if item.expired() {
+		c.remove(e)
+
+		return url, ErrNotFound
+	}
+
+	c.order.MoveToFront(e)
+
+	if item.data == nil {
+		return url, ErrMissing
+	}
+
+	if err := url.UnmarshalBinary(item.data); err != nil {
+		return url, err
+	}
+
+	return url, nil
+}
+
+func (c *URLsMemoryCache) Delete(ctx context.Context, alias string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[alias]; ok {
+		c.remove(e)
+	}
+
+	return nil
+}
+
+// invalidate drops url older than version or missing alias, negative version drops any
+func (c *URLsMemoryCache) invalidate(alias string, version int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.items[alias]
+
+	if !ok {
+		return
+	}
+
+	item := e.Value.(*memoryItem)
+
+	if version < 0 || item.data == nil || item.version < version {
+		c.remove(e)
+	}
+}
+
+// put saves item as the most recently used, mutex must be held
+func (c *URLsMemoryCache) put(item *memoryItem) {
+	if e, ok := c.items[item.alias]; ok {
+		e.Value = item
+		c.order.MoveToFront(e)
+
+		return
+	}
+
+	c.items[item.alias] = c.order.PushFront(item)
+
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+func (c *URLsMemoryCache) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.items, e.Value.(*memoryItem).alias)
+}
+
+func (i *memoryItem) expired() bool {
+	return time.Now().After(i.expiresAt)
+}
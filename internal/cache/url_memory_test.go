@@ -0,0 +1,131 @@
+package cache
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestURLsMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := newURLsMemoryCache(2, time.Minute, time.Minute)
+
+	url := domain.URL{Alias: "docs", Original: "https://docs.example.com", Tags: []string{"guide"}}
+
+	require.NoError(t, c.Set(ctx, url))
+
+	found, err := c.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, url, found)
+
+	// Changes of returned url do not affect cached one
+	found.Tags[0] = "changed"
+
+	found, err = c.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, "guide", found.Tags[0])
+
+	require.NoError(t, c.Delete(ctx, url.Alias))
+
+	_, err = c.Get(ctx, url.Alias)
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestURLsMemoryCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	c := newURLsMemoryCache(2, time.Minute, time.Minute)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "a"}))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "b"}))
+
+	// Usage of a makes b the least recently used
+	_, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "c"}))
+
+	_, err = c.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for _, alias := range []string{"a", "c"} {
+		_, err = c.Get(ctx, alias)
+		require.NoError(t, err)
+	}
+}
+
+func TestURLsMemoryCacheExpiration(t *testing.T) {
+	ctx := context.Background()
+	c := newURLsMemoryCache(2, time.Millisecond, time.Minute)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "a"}))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Url is expired in cache with its own expiration
+	c = newURLsMemoryCache(2, time.Minute, time.Minute)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "b", ExpiredAt: time.Now().Add(time.Millisecond)}))
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = c.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestURLsMemoryCacheMissing(t *testing.T) {
+	ctx := context.Background()
+	c := newURLsMemoryCache(2, time.Minute, time.Millisecond)
+
+	require.NoError(t, c.SetMissing(ctx, "a"))
+
+	_, err := c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrMissing)
+
+	// Missing alias is forgotten sooner than url
+	time.Sleep(5 * time.Millisecond)
+
+	_, err = c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Missing alias is not replaced by url loaded before its deletion
+	require.NoError(t, c.SetMissing(ctx, "b"))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "b"}))
+
+	_, err = c.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrMissing)
+
+	// Created url is cached after missing alias is deleted
+	require.NoError(t, c.Delete(ctx, "b"))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "b"}))
+
+	_, err = c.Get(ctx, "b")
+	require.NoError(t, err)
+}
+
+func TestURLsMemoryCacheVersion(t *testing.T) {
+	ctx := context.Background()
+	c := newURLsMemoryCache(2, time.Minute, time.Minute)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "a", Original: "https://new.example.com", Version: 2}))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "a", Original: "https://old.example.com", Version: 1}))
+
+	found, err := c.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, int64(2), found.Version)
+
+	// Invalidation with the same version keeps url, with newer one drops it
+	c.invalidate("a", 2)
+
+	_, err = c.Get(ctx, "a")
+	require.NoError(t, err)
+
+	c.invalidate("a", 3)
+
+	_, err = c.Get(ctx, "a")
+	require.ErrorIs(t, err, ErrNotFound)
+}
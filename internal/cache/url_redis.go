@@ -4,29 +4,138 @@ import (
 	"context"
 	"github.com/go-redis/redis/v8"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"math/rand"
 	"time"
 )
 
+// Value of alias missing in database, urls are always encoded as JSON objects
+const missingValue = "missing"
+
+// setScript saves url unless newer version of it or missing alias is cached.
+// KEYS[1] is alias, ARGV are encoded url, its version, value of missing alias and TTL in milliseconds
+var setScript = redis.NewScript(`
+local current = redis.call("GET", KEYS[1])
+
+if current == ARGV[3] then
+	return 0
+end
+
+if current then
+	local ok, cached = pcall(cjson.decode, current)
+
+	if ok and type(cached) == "table" and tonumber(cached.version or 0) > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[4])
+
+return 1
+`)
+
+// Part of TTL before expiration during which urls are refreshed early with growing probability
+const earlyExpirationPart = 10
+
 type URLsCache struct {
 	client     *redis.Client
 	defaultTTL time.Duration
+	missingTTL time.Duration
 }
 
-func newURLsCache(client *redis.Client, defaultTTL time.Duration) *URLsCache {
+func newURLsCache(client *redis.Client, defaultTTL time.Duration, missingTTL time.Duration) *URLsCache {
 	return &URLsCache{
 		client:     client,
 		defaultTTL: defaultTTL,
+		missingTTL: missingTTL,
 	}
 }
 
 func (c *URLsCache) Set(ctx context.Context, url domain.URL) error {
-	return c.client.Set(ctx, url.Alias, url, c.defaultTTL).Err()
+	_, err := c.set(ctx, url)
+
+	return err
+}
+
+// set saves url and returns whether it was newer than cached one
+func (c *URLsCache) set(ctx context.Context, url domain.URL) (bool, error) {
+	ctx, span := startSpan(ctx, "set", url.Alias)
+
+	stored, err := c.compareAndSet(ctx, url)
+
+	endSpan(span, err)
+
+	return stored, err
+}
+
+func (c *URLsCache) compareAndSet(ctx context.Context, url domain.URL) (bool, error) {
+	data, err := url.MarshalBinary()
+
+	if err != nil {
+		return false, err
+	}
+
+	ttl := urlTTL(url, c.defaultTTL).Milliseconds()
+
+	// Expiring url is kept at least for millisecond, as zero TTL is rejected
+	if ttl == 0 {
+		ttl = 1
+	}
+
+	return setScript.Run(ctx, c.client, []string{url.Alias}, data, url.Version, missingValue, ttl).Bool()
+}
+
+func (c *URLsCache) SetMissing(ctx context.Context, alias string) error {
+	ctx, span := startSpan(ctx, "set", alias)
+
+	err := c.client.Set(ctx, alias, missingValue, c.missingTTL).Err()
+
+	endSpan(span, err)
+
+	return err
 }
 
 func (c *URLsCache) Get(ctx context.Context, alias string) (domain.URL, error) {
+	ctx, span := startSpan(ctx, "get", alias)
+
+	url, err := c.get(ctx, alias)
+
+	endSpan(span, err)
+
+	return url, err
+}
+
+func (c *URLsCache) get(ctx context.Context, alias string) (domain.URL, error) {
 	var url domain.URL
 
-	if err := c.client.Get(ctx, alias).Scan(&url); err != nil {
+	// Value and its TTL are read in one round trip
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, alias)
+	ttl := pipe.PTTL(ctx, alias)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		if err == redis.Nil {
+			return url, ErrNotFound
+		}
+
+		return url, err
+	}
+
+	data, err := get.Bytes()
+
+	if err != nil {
+		return url, err
+	}
+
+	if string(data) == missingValue {
+		return url, ErrMissing
+	}
+
+	// One of concurrent readers refreshes hot url before it expires for everyone
+	if c.expiresEarly(ttl.Val()) {
+		return url, ErrNotFound
+	}
+
+	if err := url.UnmarshalBinary(data); err != nil {
 		return url, err
 	}
 
@@ -34,5 +143,23 @@ func (c *URLsCache) Get(ctx context.Context, alias string) (domain.URL, error) {
 }
 
 func (c *URLsCache) Delete(ctx context.Context, alias string) error {
-	return c.client.Del(ctx, alias).Err()
+	ctx, span := startSpan(ctx, "del", alias)
+
+	err := c.client.Del(ctx, alias).Err()
+
+	endSpan(span, err)
+
+	return err
+}
+
+// expiresEarly whether url with remaining ttl should be treated as expired,
+// probability grows linearly to 1 in the last part of default TTL
+func (c *URLsCache) expiresEarly(ttl time.Duration) bool {
+	window := c.defaultTTL / earlyExpirationPart
+
+	if ttl < 0 || ttl >= window {
+		return false
+	}
+
+	return rand.Float64() >= float64(ttl)/float64(window)
 }
@@ -0,0 +1,130 @@
+package cache
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestURLsCache(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+
+	c := newURLsCache(client, time.Minute, time.Second)
+
+	url := domain.URL{Alias: "docs", Original: "https://docs.example.com", ExpiredAt: time.Now().Add(time.Hour)}
+
+	_, err := c.Get(ctx, url.Alias)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.NoError(t, c.Set(ctx, url))
+
+	found, err := c.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, url.Original, found.Original)
+
+	require.NoError(t, c.SetMissing(ctx, "missing"))
+
+	_, err = c.Get(ctx, "missing")
+	require.ErrorIs(t, err, ErrMissing)
+	require.Equal(t, time.Second, client.TTL(ctx, "missing").Val())
+}
+
+func TestURLsCacheVersion(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+
+	c := newURLsCache(client, time.Minute, time.Second)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "docs", Original: "https://new.example.com", Version: 2}))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "docs", Original: "https://old.example.com", Version: 1}))
+
+	found, err := c.Get(ctx, "docs")
+	require.NoError(t, err)
+	require.Equal(t, "https://new.example.com", found.Original)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "docs", Original: "https://newer.example.com", Version: 3}))
+
+	found, err = c.Get(ctx, "docs")
+	require.NoError(t, err)
+	require.Equal(t, "https://newer.example.com", found.Original)
+
+	// Url loaded before deletion does not replace missing alias
+	require.NoError(t, c.SetMissing(ctx, "docs"))
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "docs", Version: 3}))
+
+	_, err = c.Get(ctx, "docs")
+	require.ErrorIs(t, err, ErrMissing)
+}
+
+func TestURLsCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+
+	c := newURLsCache(client, time.Hour, time.Second)
+
+	// TTL does not outlive expiration of url
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "soon", ExpiredAt: time.Now().Add(time.Minute)}))
+	require.InDelta(t, time.Minute, client.PTTL(ctx, "soon").Val(), float64(time.Second))
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "later", ExpiredAt: time.Now().Add(2 * time.Hour)}))
+	require.Equal(t, time.Hour, client.TTL(ctx, "later").Val())
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "expired", ExpiredAt: time.Now().Add(-time.Hour)}))
+	require.Equal(t, time.Hour, client.TTL(ctx, "expired").Val())
+}
+
+func TestURLsCacheEarlyExpiration(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+
+	c := newURLsCache(client, 10*time.Second, time.Second)
+
+	require.NoError(t, c.Set(ctx, domain.URL{Alias: "docs"}))
+
+	// Early expiration is certain at the very end of TTL
+	server.FastForward(10*time.Second - time.Millisecond)
+
+	_, err := c.Get(ctx, "docs")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	require.False(t, c.expiresEarly(5*time.Second))
+	require.True(t, c.expiresEarly(0))
+}
+
+func TestURLsCacheSpans(t *testing.T) {
+	ctx := context.Background()
+	server, client := newTestRedis(t)
+
+	_, exporter := tracing.NewInMemoryProvider()
+
+	c := newURLsCache(client, time.Minute, time.Second)
+
+	// Absence of url is not an error
+	_, err := c.Get(ctx, "docs")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	server.SetError("server is down")
+
+	require.Error(t, c.Delete(ctx, "docs"))
+
+	// Client of redis records its own spans of connections
+	spans := make(tracetest.SpanStubs, 0)
+
+	for _, span := range exporter.GetSpans() {
+		if span.InstrumentationLibrary.Name == tracerName {
+			spans = append(spans, span)
+		}
+	}
+
+	require.Len(t, spans, 2)
+	require.Equal(t, "redis get", spans[0].Name)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, "redis del", spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}
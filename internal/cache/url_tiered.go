@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"github.com/mebr0/tiny-url/internal/domain"
+	log "github.com/sirupsen/logrus"
+	"strconv"
+	"strings"
+)
+
+// Channel of aliases changed by any instance
+const invalidationsChannel = "tiny-url:urls:invalidations"
+
+// URLsTieredCache keeps hot urls and missing aliases in process memory in front of redis.
+// Changed and deleted urls are published to other instances by Invalidate, so they drop their outdated local copies.
+// Lost messages are bounded by TTL of local cache
+type URLsTieredCache struct {
+	local  *URLsMemoryCache
+	remote *URLsCache
+	client *redis.Client
+}
+
+func newURLsTieredCache(local *URLsMemoryCache, remote *URLsCache, client *redis.Client) *URLsTieredCache {
+	return &URLsTieredCache{
+		local:  local,
+		remote: remote,
+		client: client,
+	}
+}
+
+func (c *URLsTieredCache) Set(ctx context.Context, url domain.URL) error {
+	stored, err := c.remote.set(ctx, url)
+
+	// Outdated url is not kept locally either
+	if err != nil || !stored {
+		return err
+	}
+
+	return c.local.Set(ctx, url)
+}
+
+func (c *URLsTieredCache) SetMissing(ctx context.Context, alias string) error {
+	if err := c.remote.SetMissing(ctx, alias); err != nil {
+		return err
+	}
+
+	return c.local.SetMissing(ctx, alias)
+}
+
+func (c *URLsTieredCache) Get(ctx context.Context, alias string) (domain.URL, error) {
+	url, err := c.local.Get(ctx, alias)
+
+	if err == nil || err == ErrMissing {
+		return url, err
+	}
+
+	url, err = c.remote.Get(ctx, alias)
+
+	if err == ErrMissing {
+		if err := c.local.SetMissing(ctx, alias); err != nil {
+			return url, err
+		}
+
+		return url, ErrMissing
+	}
+
+	if err != nil {
+		return url, err
+	}
+
+	if err := c.local.Set(ctx, url); err != nil {
+		return url, err
+	}
+
+	return url, nil
+}
+
+func (c *URLsTieredCache) Delete(ctx context.Context, alias string) error {
+	if err := c.local.Delete(ctx, alias); err != nil {
+		return err
+	}
+
+	return c.remote.Delete(ctx, alias)
+}
+
+func (c *URLsTieredCache) Invalidate(ctx context.Context, alias string, version int64) error {
+	if version < 0 {
+		return c.publish(ctx, alias)
+	}
+
+	return c.publish(ctx, alias+":"+strconv.FormatInt(version, 10))
+}
+
+// Run drops local copies of urls changed by other instances until context is done
+func (c *URLsTieredCache) Run(ctx context.Context) {
+	sub := c.client.Subscribe(ctx, invalidationsChannel)
+
+	defer func() {
+		if err := sub.Close(); err != nil {
+			log.WithError(err).Warn("Could not unsubscribe from invalidations")
+		}
+	}()
+
+	// Channel of subscription reconnects on failures by itself
+	messages := sub.Channel()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+
+			c.local.invalidate(parseInvalidation(msg.Payload))
+		}
+	}
+}
+
+// publish sends alias with version of its new state, alias without version is dropped regardless of version
+func (c *URLsTieredCache) publish(ctx context.Context, invalidation string) error {
+	return c.client.Publish(ctx, invalidationsChannel, invalidation).Err()
+}
+
+func parseInvalidation(invalidation string) (string, int64) {
+	i := strings.LastIndex(invalidation, ":")
+
+	if i < 0 {
+		return invalidation, -1
+	}
+
+	version, err := strconv.ParseInt(invalidation[i+1:], 10, 64)
+
+	if err != nil {
+		return invalidation, -1
+	}
+
+	return invalidation[:i], version
+}
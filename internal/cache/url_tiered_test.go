@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+
+	t.Cleanup(func() {
+		_ = client.Close()
+		server.Close()
+	})
+
+	return server, client
+}
+
+func TestURLsTieredCache(t *testing.T) {
+	ctx := context.Background()
+	_, client := newTestRedis(t)
+
+	remote := newURLsCache(client, time.Minute, time.Minute)
+	c := newURLsTieredCache(newURLsMemoryCache(10, time.Minute, time.Minute), remote, client)
+
+	url := domain.URL{Alias: "docs", Original: "https://docs.example.com"}
+
+	require.NoError(t, remote.Set(ctx, url))
+
+	// Miss of local cache is filled from redis
+	found, err := c.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, url, found)
+
+	require.NoError(t, remote.Delete(ctx, url.Alias))
+
+	found, err = c.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, url, found)
+
+	require.NoError(t, c.Delete(ctx, url.Alias))
+
+	_, err = c.Get(ctx, url.Alias)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// Missing alias from redis is kept locally too
+	require.NoError(t, remote.SetMissing(ctx, url.Alias))
+
+	_, err = c.Get(ctx, url.Alias)
+	require.ErrorIs(t, err, ErrMissing)
+
+	_, err = c.local.Get(ctx, url.Alias)
+	require.ErrorIs(t, err, ErrMissing)
+}
+
+func TestURLsTieredCacheInvalidation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, client := newTestRedis(t)
+
+	// Two instances share redis, each with own local cache
+	first := newURLsTieredCache(newURLsMemoryCache(10, time.Minute, time.Minute), newURLsCache(client, time.Minute, time.Minute), client)
+	second := newURLsTieredCache(newURLsMemoryCache(10, time.Minute, time.Minute), newURLsCache(client, time.Minute, time.Minute), client)
+
+	done := make(chan struct{})
+
+	go func() {
+		second.Run(ctx)
+		close(done)
+	}()
+
+	url := domain.URL{Alias: "docs", Original: "https://docs.example.com"}
+
+	require.NoError(t, second.Set(ctx, url))
+
+	// Wait for subscription of second instance
+	require.Eventually(t, func() bool {
+		channels, err := client.PubSubNumSub(ctx, invalidationsChannel).Result()
+		return err == nil && channels[invalidationsChannel] == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Url loaded by first instance is not announced, local copy of second one is kept
+	other := domain.URL{Alias: "other", Original: "https://other.example.com"}
+	require.NoError(t, second.Set(ctx, other))
+
+	loaded := url
+	loaded.Version = 1
+	require.NoError(t, first.Set(ctx, loaded))
+	require.NoError(t, first.Invalidate(ctx, other.Alias, -1))
+
+	// Messages are received in order, so url is not invalidated once the next message is handled
+	require.Eventually(t, func() bool {
+		_, err := second.local.Get(ctx, other.Alias)
+		return err == ErrNotFound
+	}, time.Second, 10*time.Millisecond)
+
+	found, err := second.local.Get(ctx, url.Alias)
+	require.NoError(t, err)
+	require.Equal(t, url, found)
+
+	require.NoError(t, first.Delete(ctx, url.Alias))
+	require.NoError(t, first.Invalidate(ctx, url.Alias, -1))
+
+	require.Eventually(t, func() bool {
+		_, err := second.local.Get(ctx, url.Alias)
+		return err == ErrNotFound
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestParseInvalidation(t *testing.T) {
+	alias, version := parseInvalidation("docs:3")
+	require.Equal(t, "docs", alias)
+	require.Equal(t, int64(3), version)
+
+	alias, version = parseInvalidation("docs")
+	require.Equal(t, "docs", alias)
+	require.Equal(t, int64(-1), version)
+}
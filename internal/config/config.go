@@ -15,7 +15,8 @@ var (
 
 type Config struct {
 	Log struct {
-		Level string `yaml:"level" envconfig:"LOG_LEVEL"`
+		Level  string   `yaml:"level" envconfig:"LOG_LEVEL"`
+		Redact []string `yaml:"redact" envconfig:"LOG_REDACT"`
 	} `yaml:"log"`
 
 	HTTP struct {
@@ -25,6 +26,26 @@ type Config struct {
 		MaxHeaderMegabytes int           `yaml:"max-header-megabytes" envconfig:"HTTP_MAX_HEADER_MEGABYTES"`
 	} `yaml:"http"`
 
+	Metrics struct {
+		Port string `yaml:"port" envconfig:"METRICS_PORT"`
+	} `yaml:"metrics"`
+
+	Tracing struct {
+		Exporter string `yaml:"exporter" envconfig:"TRACING_EXPORTER"`
+		Endpoint string `yaml:"endpoint" envconfig:"TRACING_ENDPOINT"`
+		Insecure bool   `yaml:"insecure" envconfig:"TRACING_INSECURE"`
+	} `yaml:"tracing"`
+
+	Readiness struct {
+		Timeout       time.Duration `yaml:"timeout" envconfig:"READINESS_TIMEOUT"`
+		ShutdownDelay time.Duration `yaml:"shutdown-delay" envconfig:"READINESS_SHUTDOWN_DELAY"`
+	} `yaml:"readiness"`
+
+	Storage struct {
+		Driver  string `yaml:"driver" envconfig:"STORAGE_DRIVER"`
+		DataDir string `yaml:"data-dir" envconfig:"STORAGE_DATA_DIR"`
+	} `yaml:"storage"`
+
 	Mongo struct {
 		URI      string `yaml:"uri" envconfig:"MONGO_URI"`
 		User     string `yaml:"user" envconfig:"MONGO_USER"`
@@ -32,6 +53,17 @@ type Config struct {
 		Name     string `yaml:"name" envconfig:"MONGO_NAME"`
 	} `yaml:"mongo"`
 
+	Postgres struct {
+		DSN string `yaml:"dsn" envconfig:"POSTGRES_DSN"`
+	} `yaml:"postgres"`
+
+	Cache struct {
+		Driver     string        `yaml:"driver" envconfig:"CACHE_DRIVER"`
+		Size       int           `yaml:"size" envconfig:"CACHE_SIZE"`
+		TTL        time.Duration `yaml:"ttl" envconfig:"CACHE_TTL"`
+		MissingTTL time.Duration `yaml:"missing-ttl" envconfig:"CACHE_MISSING_TTL"`
+	} `yaml:"cache"`
+
 	Redis struct {
 		URI      string        `yaml:"uri" envconfig:"REDIS_URI"`
 		Password string        `yaml:"password" envconfig:"REDIS_PASSWORD"`
@@ -40,18 +72,71 @@ type Config struct {
 	} `yaml:"redis"`
 
 	Auth struct {
-		AccessTokenTTL time.Duration `yaml:"access-token-ttl" envconfig:"AUTH_ACCESS_TOKEN_TTL"`
-		PasswordSalt   string        `yaml:"password-salt" envconfig:"AUTH_PASSWORD_SALT"`
-		JWT            struct {
+		AccessTokenTTL        time.Duration `yaml:"access-token-ttl" envconfig:"AUTH_ACCESS_TOKEN_TTL"`
+		VerificationTokenTTL  time.Duration `yaml:"verification-token-ttl" envconfig:"AUTH_VERIFICATION_TOKEN_TTL"`
+		PasswordResetTokenTTL time.Duration `yaml:"password-reset-token-ttl" envconfig:"AUTH_PASSWORD_RESET_TOKEN_TTL"`
+		PasswordSalt          string        `yaml:"password-salt" envconfig:"AUTH_PASSWORD_SALT"`
+		Admins                []string      `yaml:"admins" envconfig:"AUTH_ADMINS"`
+		JWT                   struct {
 			Key string `yaml:"key" envconfig:"AUTH_JWT_KEY"`
 		} `yaml:"jwt"`
 	} `yaml:"auth"`
 
+	Mail struct {
+		Driver  string `yaml:"driver" envconfig:"MAIL_DRIVER"`
+		From    string `yaml:"from" envconfig:"MAIL_FROM"`
+		LinkURL string `yaml:"link-url" envconfig:"MAIL_LINK_URL"`
+		Dir     string `yaml:"dir" envconfig:"MAIL_DIR"`
+		SMTP    struct {
+			Host     string `yaml:"host" envconfig:"MAIL_SMTP_HOST"`
+			Port     int    `yaml:"port" envconfig:"MAIL_SMTP_PORT"`
+			Username string `yaml:"username" envconfig:"MAIL_SMTP_USERNAME"`
+			Password string `yaml:"password" envconfig:"MAIL_SMTP_PASSWORD"`
+		} `yaml:"smtp"`
+	} `yaml:"mail"`
+
+	Users struct {
+		DeletionPolicy string `yaml:"deletion-policy" envconfig:"USERS_DELETION_POLICY"`
+		Successor      string `yaml:"successor" envconfig:"USERS_SUCCESSOR"`
+	} `yaml:"users"`
+
 	URL struct {
-		AliasLength       int `yaml:"alias-length" envconfig:"URL_ALIAS_LENGTH"`
-		DefaultExpiration int `yaml:"default-expiration" envconfig:"URL_DEFAULT_EXPIRATION"`
-		CountLimit        int `yaml:"count-limit" envconfig:"URL_COUNT_LIMIT"`
+		AliasLength        int           `yaml:"alias-length" envconfig:"URL_ALIAS_LENGTH"`
+		DefaultExpiration  int           `yaml:"default-expiration" envconfig:"URL_DEFAULT_EXPIRATION"`
+		CountLimit         int           `yaml:"count-limit" envconfig:"URL_COUNT_LIMIT"`
+		ExpirationInterval time.Duration `yaml:"expiration-interval" envconfig:"URL_EXPIRATION_INTERVAL"`
 	} `yaml:"url"`
+
+	Metadata struct {
+		Timeout   time.Duration `yaml:"timeout" envconfig:"METADATA_TIMEOUT"`
+		Workers   int           `yaml:"workers" envconfig:"METADATA_WORKERS"`
+		QueueSize int           `yaml:"queue-size" envconfig:"METADATA_QUEUE_SIZE"`
+	} `yaml:"metadata"`
+
+	Health struct {
+		Interval     time.Duration `yaml:"interval" envconfig:"HEALTH_INTERVAL"`
+		Timeout      time.Duration `yaml:"timeout" envconfig:"HEALTH_TIMEOUT"`
+		Concurrency  int           `yaml:"concurrency" envconfig:"HEALTH_CONCURRENCY"`
+		HostInterval time.Duration `yaml:"host-interval" envconfig:"HEALTH_HOST_INTERVAL"`
+	} `yaml:"health"`
+
+	Webhook struct {
+		Timeout      time.Duration `yaml:"timeout" envconfig:"WEBHOOK_TIMEOUT"`
+		Workers      int           `yaml:"workers" envconfig:"WEBHOOK_WORKERS"`
+		BatchSize    int           `yaml:"batch-size" envconfig:"WEBHOOK_BATCH_SIZE"`
+		MaxAttempts  int           `yaml:"max-attempts" envconfig:"WEBHOOK_MAX_ATTEMPTS"`
+		Backoff      time.Duration `yaml:"backoff" envconfig:"WEBHOOK_BACKOFF"`
+		PollInterval time.Duration `yaml:"poll-interval" envconfig:"WEBHOOK_POLL_INTERVAL"`
+	} `yaml:"webhook"`
+
+	Geo struct {
+		Database string `yaml:"database" envconfig:"GEO_DATABASE"`
+	} `yaml:"geo"`
+
+	Bot struct {
+		Signatures     string        `yaml:"signatures" envconfig:"BOT_SIGNATURES"`
+		ReloadInterval time.Duration `yaml:"reload-interval" envconfig:"BOT_RELOAD_INTERVAL"`
+	} `yaml:"bot"`
 }
 
 func LoadConfig(configPath string) *Config {
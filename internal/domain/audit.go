@@ -0,0 +1,159 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// Types of audited actions
+const (
+	AuditUserRegistered     = "user.registered"
+	AuditUserLoggedIn       = "user.logged_in"
+	AuditUserVerified       = "user.verified"
+	AuditUserPasswordReset  = "user.password_reset"
+	AuditUserUpdated        = "user.updated"
+	AuditUserPasswordChange = "user.password_changed"
+	AuditUserDeleted        = "user.deleted"
+	AuditURLCreated         = "url.created"
+	AuditURLProlonged       = "url.prolonged"
+	AuditURLRulesUpdated    = "url.rules_updated"
+	AuditURLVariantsUpdated = "url.variants_updated"
+	AuditURLVariantPromoted = "url.variant_promoted"
+	AuditURLDeleted         = "url.deleted"
+	AuditURLImported        = "url.imported"
+	AuditURLTransferred     = "url.transferred"
+)
+
+type AuditEntry struct {
+	// Unique id
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// Id of user performed action
+	Actor primitive.ObjectID `json:"actor" bson:"actor" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// Type of action
+	Action string `json:"action" bson:"action" example:"url.prolonged"`
+	// Alias of url or id of user affected by action
+	Target string `json:"target" bson:"target" example:"qwerty"`
+	// Address of client
+	IP string `json:"ip" bson:"ip" example:"127.0.0.1"`
+	// User agent of client
+	UserAgent string `json:"userAgent" bson:"userAgent" example:"Mozilla/5.0"`
+	// Time of action
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+	// Changed fields of target
+	Changes []AuditChange `json:"changes" bson:"changes"`
+} // @name AuditEntry
+
+type AuditChange struct {
+	// Name of field
+	Field string `json:"field" bson:"field" example:"expiredAt"`
+	// Value before action, null if field was absent
+	Before json.RawMessage `json:"before" bson:"before" swaggertype:"object"`
+	// Value after action, null if field was removed
+	After json.RawMessage `json:"after" bson:"after" swaggertype:"object"`
+} // @name AuditChange
+
+// AuditFilter selects audit entries, zero fields match any entry
+type AuditFilter struct {
+	Actor  primitive.ObjectID
+	Action string
+	Target string
+	From   time.Time
+	To     time.Time
+	Limit  int64
+}
+
+// Match reports whether entry is selected by filter, limit is not considered
+func (f AuditFilter) Match(entry AuditEntry) bool {
+	if !f.Actor.IsZero() && entry.Actor != f.Actor {
+		return false
+	}
+
+	if f.Action != "" && entry.Action != f.Action {
+		return false
+	}
+
+	if f.Target != "" && entry.Target != f.Target {
+		return false
+	}
+
+	if !f.From.IsZero() && entry.CreatedAt.Before(f.From) {
+		return false
+	}
+
+	if !f.To.IsZero() && !entry.CreatedAt.Before(f.To) {
+		return false
+	}
+
+	return true
+}
+
+// AuditClient describes origin of request performing action
+type AuditClient struct {
+	IP        string
+	UserAgent string
+}
+
+// NewAuditChanges compares json representations of target before and after action field by field.
+// Nil target has no fields, ignored fields are never reported
+func NewAuditChanges(before, after interface{}, ignored ...string) ([]AuditChange, error) {
+	beforeFields, err := auditFields(before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := auditFields(after)
+
+	if err != nil {
+		return nil, err
+	}
+
+	skip := make(map[string]bool, len(ignored))
+
+	for _, field := range ignored {
+		skip[field] = true
+	}
+
+	changes := make([]AuditChange, 0)
+
+	for field, value := range afterFields {
+		if skip[field] || bytes.Equal(beforeFields[field], value) {
+			continue
+		}
+
+		changes = append(changes, AuditChange{Field: field, Before: beforeFields[field], After: value})
+	}
+
+	for field, value := range beforeFields {
+		if _, ok := afterFields[field]; skip[field] || ok {
+			continue
+		}
+
+		changes = append(changes, AuditChange{Field: field, Before: value})
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes, nil
+}
+
+func auditFields(v interface{}) (map[string]json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+
+	if v == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(v)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return fields, json.Unmarshal(data, &fields)
+}
@@ -0,0 +1,37 @@
+package domain
+
+// ErrorKind groups errors by cause, clients are answered with the same status for every error of kind
+type ErrorKind int
+
+const (
+	// KindInternal errors are failures of server, their text is never shown to clients
+	KindInternal ErrorKind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindGone
+	KindValidation
+	KindUpstream
+	KindTooLarge
+)
+
+// Error is expected failure with stable machine-readable code, its message is safe to show to clients
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func NewError(kind ErrorKind, code string, message string) *Error {
+	return &Error{
+		Kind:    kind,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
@@ -0,0 +1,33 @@
+package domain
+
+// Statuses of instance readiness
+const (
+	ReadinessReady    = "ready"
+	ReadinessNotReady = "not ready"
+	ReadinessStopping = "stopping"
+)
+
+// Statuses of dependencies
+const (
+	DependencyUp   = "up"
+	DependencyDown = "down"
+)
+
+type Readiness struct {
+	// Whether instance can serve requests: ready, not ready or stopping
+	Status string `json:"status" example:"ready"`
+	// Statuses of dependencies by their names
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+} // @name Readiness
+
+// DependencyStatus has no reason of failed check, since probes are unauthenticated and errors of drivers
+// may reveal addresses and credentials. Reason is logged instead
+type DependencyStatus struct {
+	// Whether dependency responded in time: up or down
+	Status string `json:"status" example:"up"`
+} // @name DependencyStatus
+
+// Ready whether instance can serve requests
+func (r Readiness) Ready() bool {
+	return r.Status == ReadinessReady
+}
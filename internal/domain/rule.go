@@ -0,0 +1,79 @@
+package domain
+
+import "strings"
+
+type RedirectRule struct {
+	// Operating system of client
+	OS string `json:"os,omitempty" bson:"os,omitempty" binding:"omitempty,oneof=ios android windows macos linux other" example:"ios"`
+	// Type of client device
+	Device string `json:"device,omitempty" bson:"device,omitempty" binding:"omitempty,oneof=mobile tablet desktop other" example:"mobile"`
+	// Language from Accept-Language header
+	Language string `json:"language,omitempty" bson:"language,omitempty" binding:"omitempty,min=2,max=35" example:"en"`
+	// ISO 3166-1 alpha-2 country code of client
+	Country string `json:"country,omitempty" bson:"country,omitempty" binding:"omitempty,iso3166_1_alpha2" example:"US"`
+	// URL for redirection when all conditions match
+	Destination string `json:"destination" bson:"destination" binding:"required,url" format:"valid URL" example:"https://apps.apple.com/app/id0000000000"`
+} // @name RedirectRule
+
+type URLRules struct {
+	// Ordered list of rules, first matched is used
+	Rules []RedirectRule `json:"rules" binding:"max=20,dive"`
+} // @name URLRules
+
+// Client describes requester of redirection
+type Client struct {
+	OS     string
+	Device string
+	// Lowercase language tags in order of preference
+	Languages []string
+	Country   string
+}
+
+// Matches whether client satisfies all conditions of rule
+func (r RedirectRule) Matches(client Client) bool {
+	if r.OS != "" && r.OS != client.OS {
+		return false
+	}
+
+	if r.Device != "" && r.Device != client.Device {
+		return false
+	}
+
+	if r.Country != "" && !strings.EqualFold(r.Country, client.Country) {
+		return false
+	}
+
+	if r.Language != "" {
+		for _, language := range client.Languages {
+			if strings.EqualFold(r.Language, language) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return true
+}
+
+// MatchRule returns the first rule matched by client
+func (url URL) MatchRule(client Client) (RedirectRule, bool) {
+	for _, rule := range url.Rules {
+		if rule.Matches(client) {
+			return rule, true
+		}
+	}
+
+	return RedirectRule{}, false
+}
+
+// UsesCountry whether any rule depends on country of client
+func (url URL) UsesCountry() bool {
+	for _, rule := range url.Rules {
+		if rule.Country != "" {
+			return true
+		}
+	}
+
+	return false
+}
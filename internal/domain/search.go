@@ -0,0 +1,26 @@
+package domain
+
+type URLSearchResult struct {
+	// Found URL
+	URL URL `json:"url"`
+	// Relevance of URL to query, higher is better
+	Score float64 `json:"score" example:"1.5"`
+	// Fields of URL containing terms of query
+	Highlights []Highlight `json:"highlights"`
+} // @name URLSearchResult
+
+type Highlight struct {
+	// Name of field
+	Field string `json:"field" example:"original"`
+	// Value of field
+	Value string `json:"value" example:"https://docs.example.com/"`
+	// Ranges of characters matching query
+	Matches []TextRange `json:"matches"`
+} // @name Highlight
+
+type TextRange struct {
+	// Offset of first matched character
+	Start int `json:"start" example:"8"`
+	// Offset after last matched character
+	End int `json:"end" example:"12"`
+} // @name TextRange
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Intervals of statistics series
+const (
+	StatsHour = "hour"
+	StatsDay  = "day"
+	StatsWeek = "week"
+)
+
+// Values of clicks without known referrer or country
+const (
+	ReferrerDirect = "direct"
+	CountryUnknown = "unknown"
+)
+
+// Click describes redirection counted in statistics
+type Click struct {
+	// Name of chosen variant, empty if none
+	Variant string
+	Time    time.Time
+	// Host of Referer header
+	Referrer string
+	Country  string
+	Browser  string
+	OS       string
+	// Identity of visitor, only its hash is kept
+	Visitor string
+	// Whether redirection was requested by bot, such as link preview or crawler
+	Bot bool
+}
+
+// StatsHit is click counted in rollup of its alias and hour, clicks of bots are only counted
+type StatsHit struct {
+	Alias    string
+	Owner    primitive.ObjectID
+	Hour     time.Time
+	Bot      bool
+	Referrer string
+	Country  string
+	Browser  string
+	OS       string
+	// Register of visitors sketch raised to rank by visitor of click
+	VisitorRegister int
+	VisitorRank     uint8
+}
+
+// StatsRollup aggregates clicks of alias during one hour
+type StatsRollup struct {
+	Alias     string             `bson:"alias"`
+	Owner     primitive.ObjectID `bson:"owner"`
+	Hour      time.Time          `bson:"hour"`
+	Clicks    int64              `bson:"clicks"`
+	Bots      int64              `bson:"bots"`
+	Referrers map[string]int64   `bson:"referrers"`
+	Countries map[string]int64   `bson:"countries"`
+	Browsers  map[string]int64   `bson:"browsers"`
+	OS        map[string]int64   `bson:"os"`
+	// Non-zero registers of HyperLogLog sketch of visitors by index
+	Visitors map[string]uint8 `bson:"visitors"`
+}
+
+// StatsFilter selects rollups of owner with hour in range, empty alias matches every url
+type StatsFilter struct {
+	Owner primitive.ObjectID
+	Alias string
+	From  time.Time
+	To    time.Time
+}
+
+type StatsQuery struct {
+	// Alias of url, empty for all urls of owner
+	Alias string
+	From  time.Time
+	To    time.Time
+	// Time zone of series buckets
+	Location *time.Location
+	// One of StatsHour, StatsDay and StatsWeek
+	Interval string
+	// Count of top values of every breakdown
+	Limit int
+}
+
+type Stats struct {
+	// Alias of url, empty for all urls of user
+	Alias string `json:"alias,omitempty" example:"qwerty"`
+	// Start of range rounded down to hour
+	From time.Time `json:"from" format:"yyyy-MM-ddThh:mm:ssZ" example:"2021-05-09T00:00:00+06:00"`
+	// End of range rounded up to hour
+	To time.Time `json:"to" format:"yyyy-MM-ddThh:mm:ssZ" example:"2021-05-16T00:00:00+06:00"`
+	// Time zone of series
+	Timezone string `json:"timezone" example:"Asia/Almaty"`
+	// Length of series bucket
+	Interval string `json:"interval" enums:"hour,day,week" example:"day"`
+	// Count of redirections of people
+	Clicks int64 `json:"clicks" example:"120"`
+	// Count of requests of bots, such as link previews and crawlers
+	Bots int64 `json:"bots" example:"12"`
+	// Estimated count of unique visitors
+	Visitors int64 `json:"visitors" example:"85"`
+	// Clicks and visitors by bucket, empty buckets included
+	Series []StatsPoint `json:"series"`
+	// Top hosts of referring pages
+	Referrers []StatsCount `json:"referrers"`
+	// Top ISO 3166-1 alpha-2 country codes
+	Countries []StatsCount `json:"countries"`
+	// Top browsers
+	Browsers []StatsCount `json:"browsers"`
+	// Top operating systems
+	OS []StatsCount `json:"os"`
+} // @name Stats
+
+type StatsPoint struct {
+	// Start of bucket in time zone of series
+	Time time.Time `json:"time" format:"yyyy-MM-ddThh:mm:ssZ" example:"2021-05-09T00:00:00+06:00"`
+	// Count of redirections of people
+	Clicks int64 `json:"clicks" example:"20"`
+	// Count of requests of bots
+	Bots int64 `json:"bots" example:"2"`
+	// Estimated count of unique visitors
+	Visitors int64 `json:"visitors" example:"14"`
+} // @name StatsPoint
+
+type StatsCount struct {
+	// Value of breakdown
+	Value string `json:"value" example:"google.com"`
+	// Count of redirections
+	Clicks int64 `json:"clicks" example:"42"`
+} // @name StatsCount
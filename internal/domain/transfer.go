@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Formats of exported and imported urls
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	// FormatBookmarks is Netscape bookmark file exported by browsers, supported only by import
+	FormatBookmarks = "html"
+)
+
+// Ways of resolving conflict of imported url with existing one
+const (
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// Outcomes of imported rows
+const (
+	ImportCreated     = "created"
+	ImportOverwritten = "overwritten"
+	ImportRenamed     = "renamed"
+	ImportSkipped     = "skipped"
+	ImportFailed      = "failed"
+)
+
+// URLRecord is url in exported file, fields of owner state like health are left out
+type URLRecord struct {
+	// Alias for redirection, generated on import if empty
+	Alias string `json:"alias,omitempty" binding:"omitempty,max=32" example:"qwerty"`
+	// Original URL
+	Original string `json:"original" binding:"required,url" format:"valid URL" example:"https://google.com/"`
+	// Time of creation, time of import if empty
+	CreatedAt time.Time `json:"createdAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+	// Expiration time, default expiration is used on import if empty
+	ExpiredAt time.Time `json:"expiredAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-06-09T09:29:18.169Z"`
+	// Labels for grouping and search
+	Tags []string `json:"tags,omitempty" binding:"max=10,dive,min=1,max=32" example:"docs"`
+	// Forward query parameters of redirection request to original URL
+	ForwardQuery bool `json:"forwardQuery" example:"false"`
+	// Forward path after alias of redirection request to original URL
+	ForwardPath bool `json:"forwardPath" example:"false"`
+	// Rules of redirection depending on client
+	Rules []RedirectRule `json:"rules,omitempty" binding:"max=20,dive"`
+	// Weighted destinations of split testing
+	Variants []Variant `json:"variants,omitempty" binding:"max=10,unique=Name"`
+	// Keep redirecting client to the same variant
+	StickyVariants bool `json:"stickyVariants" example:"false"`
+	// Title of page, metadata is fetched on import if empty
+	Title string `json:"title,omitempty" example:"Google"`
+	// Description of page
+	Description string `json:"description,omitempty" example:"Search the world's information"`
+	// Absolute URL of page icon
+	Favicon string `json:"favicon,omitempty" format:"valid URL" example:"https://google.com/favicon.ico"`
+	// Absolute URL of Open Graph image
+	Image string `json:"image,omitempty" format:"valid URL" example:"https://google.com/logo.png"`
+	// Count of redirections
+	Clicks int64 `json:"clicks" binding:"gte=0" example:"10"`
+} // @name URLRecord
+
+type URLImportOptions struct {
+	// Check rows without changing any url
+	DryRun bool
+	// Way of resolving conflict with existing url, one of ConflictSkip, ConflictOverwrite and ConflictRename
+	OnConflict string
+}
+
+type URLImportResult struct {
+	// Whether nothing was changed
+	DryRun bool `json:"dryRun" example:"false"`
+	// Count of created urls, renamed ones included
+	Created int `json:"created" example:"8"`
+	// Count of replaced urls
+	Overwritten int `json:"overwritten" example:"1"`
+	// Count of rows left out due to conflict
+	Skipped int `json:"skipped" example:"1"`
+	// Count of invalid rows
+	Failed int `json:"failed" example:"0"`
+	// Outcomes of rows in order of file
+	Rows []URLImportRow `json:"rows"`
+} // @name URLImportResult
+
+type URLImportRow struct {
+	// Number of row starting from 1
+	Row int `json:"row" example:"1"`
+	// Alias of imported url, differs from alias of row if renamed
+	Alias string `json:"alias,omitempty" example:"qwerty"`
+	// Outcome of row
+	Status string `json:"status" enums:"created,overwritten,renamed,skipped,failed" example:"created"`
+	// Reason of failure
+	Error string `json:"error,omitempty" example:"invalid original"`
+} // @name URLImportRow
+
+// NewURLRecord create record for export of url
+func NewURLRecord(url URL) URLRecord {
+	return URLRecord{
+		Alias:          url.Alias,
+		Original:       url.Original,
+		CreatedAt:      url.CreatedAt,
+		ExpiredAt:      url.ExpiredAt,
+		Tags:           url.Tags,
+		ForwardQuery:   url.ForwardQuery,
+		ForwardPath:    url.ForwardPath,
+		Rules:          url.Rules,
+		Variants:       url.Variants,
+		StickyVariants: url.StickyVariants,
+		Title:          url.Metadata.Title,
+		Description:    url.Metadata.Description,
+		Favicon:        url.Metadata.Favicon,
+		Image:          url.Metadata.Image,
+		Clicks:         url.Clicks,
+	}
+}
+
+// URL create url of owner from imported record, zero times are replaced by now and default duration in seconds
+func (r URLRecord) URL(alias string, owner primitive.ObjectID, defaultDuration int) URL {
+	now := time.Now()
+
+	url := URL{
+		Alias:     alias,
+		Original:  r.Original,
+		CreatedAt: r.CreatedAt,
+		ExpiredAt: r.ExpiredAt,
+		Owner:     owner,
+		Tags:      r.Tags,
+		Metadata: URLMetadata{
+			Title:       r.Title,
+			Description: r.Description,
+			Favicon:     r.Favicon,
+			Image:       r.Image,
+		},
+		Clicks:         r.Clicks,
+		ForwardQuery:   r.ForwardQuery,
+		ForwardPath:    r.ForwardPath,
+		Rules:          r.Rules,
+		Variants:       r.Variants,
+		StickyVariants: r.StickyVariants,
+	}
+
+	if url.CreatedAt.IsZero() {
+		url.CreatedAt = now
+	}
+
+	if url.ExpiredAt.IsZero() {
+		url.ExpiredAt = now.Add(time.Duration(defaultDuration) * time.Second)
+	}
+
+	// Url expired before import is already known to be expired
+	url.ExpirationNotified = url.ExpiredAt.Before(now)
+
+	return url
+}
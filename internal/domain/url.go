@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
 	"time"
 )
 
@@ -17,32 +18,127 @@ type URL struct {
 	ExpiredAt time.Time `json:"expiredAt" bson:"expiredAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-06-09T09:29:18.169Z"`
 	// Id of owner
 	Owner primitive.ObjectID `json:"owner" bson:"owner" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// Labels for grouping and search
+	Tags []string `json:"tags,omitempty" bson:"tags,omitempty" example:"docs"`
+	// Metadata of original URL page
+	Metadata URLMetadata `json:"metadata" bson:"metadata"`
+	// Health of original URL
+	Health URLHealth `json:"health" bson:"health"`
+	// Count of redirections
+	Clicks int64 `json:"clicks" bson:"clicks" example:"10"`
+	// Whether expiration was announced
+	ExpirationNotified bool `json:"-" bson:"expirationNotified"`
+	// Forward query parameters of redirection request to original URL.
+	// Parameters of original URL take precedence on collision
+	ForwardQuery bool `json:"forwardQuery" bson:"forwardQuery" example:"false"`
+	// Forward path after alias of redirection request to original URL
+	ForwardPath bool `json:"forwardPath" bson:"forwardPath" example:"false"`
+	// Rules of redirection depending on client, original URL is used if none matches
+	Rules []RedirectRule `json:"rules,omitempty" bson:"rules,omitempty"`
+	// Weighted destinations of split testing, used if none of rules matches
+	Variants []Variant `json:"variants,omitempty" bson:"variants,omitempty"`
+	// Keep redirecting client to the same variant
+	StickyVariants bool `json:"stickyVariants" bson:"stickyVariants" example:"false"`
+	// Incremented on every change, so older state of url never replaces newer one in cache
+	Version int64 `json:"version" bson:"version" example:"1"`
 } // @name URL
 
+type URLMetadata struct {
+	// Title of page
+	Title string `json:"title,omitempty" bson:"title,omitempty" example:"Google"`
+	// Description of page
+	Description string `json:"description,omitempty" bson:"description,omitempty" example:"Search the world's information"`
+	// Absolute URL of page icon
+	Favicon string `json:"favicon,omitempty" bson:"favicon,omitempty" format:"valid URL" example:"https://google.com/favicon.ico"`
+	// Absolute URL of Open Graph image
+	Image string `json:"image,omitempty" bson:"image,omitempty" format:"valid URL" example:"https://google.com/logo.png"`
+	// Time of last fetching
+	FetchedAt time.Time `json:"fetchedAt" bson:"fetchedAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+} // @name URLMetadata
+
 type URLCreate struct {
 	// Original URL
 	Original string `json:"original" binding:"required,url" format:"valid URL" example:"https://google.com/"`
 	// Duration of life of URL in seconds
-	Duration int                `json:"duration" binding:"gte=0" example:"3600"`
-	Owner    primitive.ObjectID `swaggerignore:"true"`
+	Duration int `json:"duration" binding:"gte=0" example:"3600"`
+	// UTM parameters added to original URL
+	UTM UTM `json:"utm"`
+	// Forward query parameters of redirection request to original URL
+	ForwardQuery bool `json:"forwardQuery" example:"false"`
+	// Forward path after alias of redirection request to original URL
+	ForwardPath bool `json:"forwardPath" example:"false"`
+	// Ordered rules of redirection depending on client
+	Rules []RedirectRule `json:"rules" binding:"max=20,dive"`
+	// Labels for grouping and search
+	Tags  []string           `json:"tags" binding:"max=10,dive,min=1,max=32" example:"docs"`
+	Owner primitive.ObjectID `swaggerignore:"true"`
 } // @name URLCreate
 
+type UTM struct {
+	// Referrer of traffic
+	Source string `json:"source,omitempty" example:"newsletter"`
+	// Marketing medium
+	Medium string `json:"medium,omitempty" example:"email"`
+	// Name of campaign
+	Campaign string `json:"campaign,omitempty" example:"spring_sale"`
+	// Paid search keywords
+	Term string `json:"term,omitempty" example:"running+shoes"`
+	// Differentiation of ads or links
+	Content string `json:"content,omitempty" example:"logolink"`
+} // @name UTM
+
 type URLProlong struct {
 	// Duration of life of URL in seconds
 	Duration int `json:"duration" binding:"gte=0" example:"3600"`
 } // @name URLProlong
 
+type URLHealth struct {
+	// Status code returned by original URL
+	StatusCode int `json:"statusCode,omitempty" bson:"statusCode,omitempty" example:"200"`
+	// Response time in milliseconds
+	Latency int64 `json:"latency,omitempty" bson:"latency,omitempty" example:"120"`
+	// Error occurred while checking
+	Error string `json:"error,omitempty" bson:"error,omitempty" example:"connection refused"`
+	// Whether original URL is unavailable
+	Broken bool `json:"broken" bson:"broken" example:"false"`
+	// Time of last check
+	CheckedAt time.Time `json:"checkedAt" bson:"checkedAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+} // @name URLHealth
+
 // NewURL create new URL from URLCreate and alias
 func NewURL(toCreate URLCreate, alias string) URL {
 	return URL{
-		Alias:     alias,
-		Original:  toCreate.Original,
-		CreatedAt: time.Now(),
-		ExpiredAt: time.Now().Add(time.Duration(toCreate.Duration) * time.Second),
-		Owner:     toCreate.Owner,
+		Alias:        alias,
+		Original:     toCreate.Original,
+		CreatedAt:    time.Now(),
+		ExpiredAt:    time.Now().Add(time.Duration(toCreate.Duration) * time.Second),
+		Owner:        toCreate.Owner,
+		ForwardQuery: toCreate.ForwardQuery,
+		ForwardPath:  toCreate.ForwardPath,
+		Rules:        toCreate.Rules,
+		Tags:         toCreate.Tags,
 	}
 }
 
+// Values query parameters of non-empty UTM fields
+func (utm UTM) Values() url.Values {
+	values := url.Values{}
+
+	for key, value := range map[string]string{
+		"utm_source":   utm.Source,
+		"utm_medium":   utm.Medium,
+		"utm_campaign": utm.Campaign,
+		"utm_term":     utm.Term,
+		"utm_content":  utm.Content,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	return values
+}
+
 // MarshalBinary implement encoding.BinaryMarshaler for redis scanning
 func (url URL) MarshalBinary() ([]byte, error) {
 	return json.Marshal(url)
@@ -5,6 +5,12 @@ import (
 	"time"
 )
 
+// Policies for urls of deleted users
+const (
+	UserURLsDelete   = "delete"
+	UserURLsTransfer = "transfer"
+)
+
 type User struct {
 	// Unique id
 	ID primitive.ObjectID `json:"id" bson:"_id,omitempty" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
@@ -12,12 +18,16 @@ type User struct {
 	Name string `json:"name" bson:"name" example:"Sirius"`
 	// Unique email
 	Email string `json:"email" bson:"email" format:"email" example:"sirius@gmail.com"`
-	// Secret password
-	Password string `json:"password" bson:"password" example:"qweqweqwe"`
+	// Hash of secret password, never returned
+	Password string `json:"-" bson:"password"`
 	// Time of registration
 	RegisteredAt time.Time `json:"registeredAt" bson:"registeredAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-07T18:30:05.365Z"`
 	// Last login time
 	LastLogin time.Time `json:"lastLogin" bson:"lastLogin" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-07T18:30:05.365Z"`
+	// Whether user confirmed ownership of email
+	Verified bool `json:"verified" bson:"verified" example:"true"`
+	// Generation of access tokens, only tokens of current one are accepted
+	TokenGeneration int64 `json:"-" bson:"tokenGeneration"`
 } // @name User
 
 type UserRegister struct {
@@ -29,6 +39,27 @@ type UserRegister struct {
 	Password string `json:"password" binding:"required,alphanum,min=8" example:"qweqweqwe"`
 } // @name UserRegister
 
+type UserUpdate struct {
+	// New first name, empty keeps current
+	Name string `json:"name" binding:"omitempty,alpha,min=4" example:"Sirius"`
+	// New email, applied after it is verified, empty keeps current
+	Email string `json:"email" binding:"omitempty,email" format:"email" example:"sirius@gmail.com"`
+} // @name UserUpdate
+
+type PasswordChange struct {
+	// Current secret password
+	Password string `json:"password" binding:"required" example:"qweqweqwe"`
+	// New secret password
+	NewPassword string `json:"newPassword" binding:"required,alphanum,min=8" example:"asdasdasd"`
+} // @name PasswordChange
+
+type UserDelete struct {
+	// Current secret password
+	Password string `json:"password" binding:"required" example:"qweqweqwe"`
+	// Whether urls are deleted or transferred to successor, empty keeps policy of server
+	URLs string `json:"urls" binding:"omitempty,oneof=delete transfer" enums:"delete,transfer" example:"transfer"`
+} // @name UserDelete
+
 type UserLogin struct {
 	// Unique email
 	Email string `json:"email" binding:"required,email" format:"email" example:"sirius@gmail.com"`
@@ -36,6 +67,23 @@ type UserLogin struct {
 	Password string `json:"password" binding:"required,alphanum" example:"qweqweqwe"`
 } // @name UserLogin
 
+type UserVerify struct {
+	// Token from verification email
+	Token string `json:"token" binding:"required" example:"hWbe1Xl0C3vC1T4Rz2mZ3s8Hn0mG7qYgI4dXrJv3Z6A"`
+} // @name UserVerify
+
+type PasswordForgot struct {
+	// Email of user
+	Email string `json:"email" binding:"required,email" format:"email" example:"sirius@gmail.com"`
+} // @name PasswordForgot
+
+type PasswordReset struct {
+	// Token from password reset email
+	Token string `json:"token" binding:"required" example:"hWbe1Xl0C3vC1T4Rz2mZ3s8Hn0mG7qYgI4dXrJv3Z6A"`
+	// New secret password
+	Password string `json:"password" binding:"required,alphanum,min=8" example:"qweqweqwe"`
+} // @name PasswordReset
+
 type Tokens struct {
 	// Token used for accessing operations and/or resources
 	AccessToken string `json:"accessToken" example:"access token"`
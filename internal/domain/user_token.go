@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Purposes of user tokens
+const (
+	TokenVerification  = "verification"
+	TokenPasswordReset = "password_reset"
+)
+
+// UserToken is single-use secret sent to email of user, only its hash is stored
+type UserToken struct {
+	// SHA-256 of token in hex
+	Hash string `bson:"_id"`
+	// Id of user token is issued to
+	User    primitive.ObjectID `bson:"user"`
+	Purpose string             `bson:"purpose"`
+	// Email token is sent to, verification token proves ownership of it
+	Email string `bson:"email"`
+	// Time of issuing
+	CreatedAt time.Time `bson:"createdAt"`
+	// Time after which token is not accepted
+	ExpiredAt time.Time `bson:"expiredAt"`
+}
+
+func (t UserToken) Expired() bool {
+	return !t.ExpiredAt.After(time.Now())
+}
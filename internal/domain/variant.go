@@ -0,0 +1,120 @@
+package domain
+
+type Variant struct {
+	// Unique name of variant within URL
+	Name string `json:"name" bson:"name" example:"a"`
+	// URL for redirection
+	Destination string `json:"destination" bson:"destination" format:"valid URL" example:"https://google.com/landing-a"`
+	// Relative weight of variant in rotation
+	Weight int `json:"weight" bson:"weight" example:"50"`
+	// Count of redirections to variant
+	Clicks int64 `json:"clicks" bson:"clicks" example:"10"`
+} // @name Variant
+
+type VariantCreate struct {
+	// Unique name of variant within URL
+	Name string `json:"name" binding:"required,alphanum,max=32" example:"a"`
+	// URL for redirection
+	Destination string `json:"destination" binding:"required,url" format:"valid URL" example:"https://google.com/landing-a"`
+	// Relative weight of variant in rotation
+	Weight int `json:"weight" binding:"required,min=1,max=1000" example:"50"`
+} // @name VariantCreate
+
+type URLVariants struct {
+	// Destinations of split testing, empty list disables it
+	Variants []VariantCreate `json:"variants" binding:"max=10,unique=Name,dive"`
+	// Keep redirecting client to the same variant using cookie
+	Sticky bool `json:"sticky" example:"true"`
+} // @name URLVariants
+
+type VariantStats struct {
+	// Unique name of variant within URL
+	Name string `json:"name" example:"a"`
+	// URL for redirection
+	Destination string `json:"destination" format:"valid URL" example:"https://google.com/landing-a"`
+	// Relative weight of variant in rotation
+	Weight int `json:"weight" example:"50"`
+	// Count of redirections to variant
+	Clicks int64 `json:"clicks" example:"10"`
+	// Part of redirections to variant among all variants
+	Share float64 `json:"share" example:"0.5"`
+} // @name VariantStats
+
+// NewVariants create variants with zero clicks from VariantCreate list
+func NewVariants(toCreate []VariantCreate) []Variant {
+	variants := make([]Variant, 0, len(toCreate))
+
+	for _, v := range toCreate {
+		variants = append(variants, Variant{
+			Name:        v.Name,
+			Destination: v.Destination,
+			Weight:      v.Weight,
+		})
+	}
+
+	return variants
+}
+
+// Variant returns variant by name
+func (url URL) Variant(name string) (Variant, bool) {
+	for _, v := range url.Variants {
+		if v.Name == name {
+			return v, true
+		}
+	}
+
+	return Variant{}, false
+}
+
+// TotalWeight sum of weights of all variants
+func (url URL) TotalWeight() int {
+	total := 0
+
+	for _, v := range url.Variants {
+		total += v.Weight
+	}
+
+	return total
+}
+
+// PickVariant returns variant covering point n of range [0, TotalWeight)
+func (url URL) PickVariant(n int) (Variant, bool) {
+	for _, v := range url.Variants {
+		if n < v.Weight {
+			return v, true
+		}
+
+		n -= v.Weight
+	}
+
+	return Variant{}, false
+}
+
+// VariantStats clicks of variants with their share
+func (url URL) VariantStats() []VariantStats {
+	var total int64
+
+	for _, v := range url.Variants {
+		total += v.Clicks
+	}
+
+	stats := make([]VariantStats, 0, len(url.Variants))
+
+	for _, v := range url.Variants {
+		var share float64
+
+		if total > 0 {
+			share = float64(v.Clicks) / float64(total)
+		}
+
+		stats = append(stats, VariantStats{
+			Name:        v.Name,
+			Destination: v.Destination,
+			Weight:      v.Weight,
+			Clicks:      v.Clicks,
+			Share:       share,
+		})
+	}
+
+	return stats
+}
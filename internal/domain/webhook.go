@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Types of events of url lifecycle
+const (
+	EventURLCreated   = "url.created"
+	EventURLProlonged = "url.prolonged"
+	EventURLDeleted   = "url.deleted"
+	EventURLExpired   = "url.expired"
+	EventURLMilestone = "url.milestone"
+	EventURLBroken    = "url.broken"
+)
+
+type Webhook struct {
+	// Unique id
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// URL receiving events
+	URL string `json:"url" bson:"url" format:"valid URL" example:"https://example.com/hooks/tiny-url"`
+	// Secret used for signing payloads
+	Secret string `json:"-" bson:"secret"`
+	// Types of subscribed events
+	Events []string `json:"events" bson:"events" example:"url.created,url.deleted"`
+	// Time of creation
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+	// Id of owner
+	Owner primitive.ObjectID `json:"owner" bson:"owner" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+} // @name Webhook
+
+type WebhookCreate struct {
+	// URL receiving events
+	URL string `json:"url" binding:"required,url" format:"valid URL" example:"https://example.com/hooks/tiny-url"`
+	// Secret used for signing payloads
+	Secret string `json:"secret" binding:"required,min=16" example:"0123456789abcdef"`
+	// Types of subscribed events
+	Events []string           `json:"events" binding:"required,min=1,dive,oneof=url.created url.prolonged url.deleted url.expired url.milestone url.broken" example:"url.created,url.deleted"`
+	Owner  primitive.ObjectID `swaggerignore:"true"`
+} // @name WebhookCreate
+
+type WebhookDelivery struct {
+	// Unique id
+	ID primitive.ObjectID `json:"id" bson:"_id,omitempty" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// Id of webhook
+	Webhook primitive.ObjectID `json:"webhook" bson:"webhook" format:"hexadecimal string" example:"6095872d75ff40c9238bdb29"`
+	// Type of event
+	Event string `json:"event" bson:"event" example:"url.created"`
+	// Sent body
+	Payload string `json:"payload" bson:"payload" example:"{}"`
+	// Count of sending attempts
+	Attempts int `json:"attempts" bson:"attempts" example:"1"`
+	// Status code of last attempt
+	StatusCode int `json:"statusCode,omitempty" bson:"statusCode,omitempty" example:"200"`
+	// Error of last attempt
+	Error string `json:"error,omitempty" bson:"error,omitempty" example:"connection refused"`
+	// Whether receiver accepted event
+	Delivered bool `json:"delivered" bson:"delivered" example:"true"`
+	// Whether delivery waits for next attempt
+	Pending bool `json:"pending" bson:"pending" example:"false"`
+	// Time of next attempt of pending delivery
+	NextAttemptAt time.Time `json:"nextAttemptAt" bson:"nextAttemptAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+	// Time of creation
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+	// Time of last attempt
+	AttemptedAt time.Time `json:"attemptedAt" bson:"attemptedAt" format:"yyyy-MM-ddThh:mm:ss.ZZZ" example:"2021-05-09T09:29:18.169Z"`
+} // @name WebhookDelivery
+
+// WebhookEvent is body of webhook deliveries
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
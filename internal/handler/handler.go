@@ -4,8 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mebr0/tiny-url/internal/config"
 	v1 "github.com/mebr0/tiny-url/internal/handler/v1"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/metrics"
 	"github.com/mebr0/tiny-url/internal/service"
+	"github.com/mebr0/tiny-url/internal/tracing"
 	"github.com/mebr0/tiny-url/pkg/auth"
+	"github.com/mebr0/tiny-url/pkg/bot"
+	"github.com/mebr0/tiny-url/pkg/geo"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"github.com/swaggo/gin-swagger/swaggerFiles"
 )
@@ -13,22 +18,36 @@ import (
 type Handler struct {
 	services     *service.Services
 	tokenManager auth.TokenManager
+	geoResolver  geo.Resolver
+	botDetector  bot.Detector
 }
 
-func NewHandler(services *service.Services, tokenManager auth.TokenManager) *Handler {
+func NewHandler(services *service.Services, tokenManager auth.TokenManager, geoResolver geo.Resolver,
+	botDetector bot.Detector) *Handler {
 	return &Handler{
 		services:     services,
 		tokenManager: tokenManager,
+		geoResolver:  geoResolver,
+		botDetector:  botDetector,
 	}
 }
 
 func (h *Handler) Init(cfg *config.Config) *gin.Engine {
 	// Init gin handler
-	router := gin.Default()
+	router := gin.New()
+
+	// Trace, log and count requests, panics are recovered last so others see failed request
+	router.Use(tracing.Middleware()...)
+	router.Use(logging.Middleware(cfg.Log.Redact))
+	router.Use(metrics.Middleware())
+	router.Use(logging.Recovery())
 
 	// Init swagger routes
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Init probes of orchestrator
+	h.initProbeRoutes(router)
+
 	// Init router
 	h.initAPI(router)
 
@@ -36,7 +55,7 @@ func (h *Handler) Init(cfg *config.Config) *gin.Engine {
 }
 
 func (h *Handler) initAPI(router *gin.Engine) {
-	handlerV1 := v1.NewHandler(h.services, h.tokenManager)
+	handlerV1 := v1.NewHandler(h.services, h.tokenManager, h.geoResolver, h.botDetector)
 
 	api := router.Group("/api")
 	{
@@ -0,0 +1,29 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// Probes of orchestrator are served without authentication outside of API
+func (h *Handler) initProbeRoutes(router *gin.Engine) {
+	router.GET("/healthz", h.healthz)
+	router.GET("/readyz", h.readyz)
+}
+
+// healthz responds while process is alive
+func (h *Handler) healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "alive"})
+}
+
+// readyz responds with statuses of dependencies, instance is not ready if any of them is down
+func (h *Handler) readyz(c *gin.Context) {
+	readiness := h.services.Readiness.Check(c.Request.Context())
+
+	if !readiness.Ready() {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/cache"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_healthz(t *testing.T) {
+	handler := &Handler{services: &service.Services{}}
+
+	r := gin.New()
+	handler.initProbeRoutes(r)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/healthz", nil)
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, `{"status":"alive"}`, w.Body.String())
+}
+
+func TestHandler_readyz(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockReadiness)
+
+	ready := domain.Readiness{
+		Status: domain.ReadinessReady,
+		Dependencies: map[string]domain.DependencyStatus{
+			"mongo": {Status: domain.DependencyUp},
+			"redis": {Status: domain.DependencyUp},
+		},
+	}
+	notReady := domain.Readiness{
+		Status: domain.ReadinessNotReady,
+		Dependencies: map[string]domain.DependencyStatus{
+			"mongo": {Status: domain.DependencyUp},
+			"redis": {Status: domain.DependencyDown},
+		},
+	}
+	stopping := domain.Readiness{
+		Status:       domain.ReadinessStopping,
+		Dependencies: map[string]domain.DependencyStatus{},
+	}
+
+	setResponseBody := func(readiness domain.Readiness) string {
+		body, _ := json.Marshal(readiness)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name                 string
+		mockBehaviour        mockBehaviour
+		expectedCodeStatus   int
+		expectedResponseBody string
+	}{
+		{
+			name: "ready",
+			mockBehaviour: func(s *mockService.MockReadiness) {
+				s.EXPECT().Check(gomock.Any()).Return(ready)
+			},
+			expectedCodeStatus:   200,
+			expectedResponseBody: setResponseBody(ready),
+		},
+		{
+			name: "dependency down",
+			mockBehaviour: func(s *mockService.MockReadiness) {
+				s.EXPECT().Check(gomock.Any()).Return(notReady)
+			},
+			expectedCodeStatus:   503,
+			expectedResponseBody: setResponseBody(notReady),
+		},
+		{
+			name: "stopping",
+			mockBehaviour: func(s *mockService.MockReadiness) {
+				s.EXPECT().Check(gomock.Any()).Return(stopping)
+			},
+			expectedCodeStatus:   503,
+			expectedResponseBody: setResponseBody(stopping),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			readinessService := mockService.NewMockReadiness(c)
+			tt.mockBehaviour(readinessService)
+
+			handler := &Handler{services: &service.Services{Readiness: readinessService}}
+
+			// Init Endpoint
+			r := gin.New()
+			handler.initProbeRoutes(r)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/readyz", nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedCodeStatus, w.Code)
+			assert.Equal(t, tt.expectedResponseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_readyzHidesErrors(t *testing.T) {
+	services := service.NewServices(service.Deps{
+		Repos:  &repo.Repos{},
+		Caches: cache.NewMemoryCaches(10, time.Minute, time.Minute),
+		Dependencies: []service.Dependency{{
+			Name: "postgres",
+			Ping: func(ctx context.Context) error {
+				return errors.New("pq: password authentication failed for user \"tiny\" at db.internal:5432")
+			},
+		}},
+		ReadinessTimeout: time.Second,
+	})
+
+	handler := &Handler{services: services}
+
+	r := gin.New()
+	handler.initProbeRoutes(r)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	r.ServeHTTP(w, req)
+
+	// Probe is unauthenticated, so only status of dependency is shown
+	assert.Equal(t, 503, w.Code)
+	assert.Equal(t, `{"status":"not ready","dependencies":{"postgres":{"status":"down"}}}`, w.Body.String())
+	require.NotContains(t, w.Body.String(), "password authentication failed")
+	require.NotContains(t, w.Body.String(), "db.internal")
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Max count of audit entries returned at once
+const maxAuditLimit = 1000
+
+func (h *Handler) initAuditRoutes(api *gin.RouterGroup) {
+	audit := api.Group("/audit", h.userIdentity)
+	{
+		audit.GET("", h.listAudit)
+	}
+}
+
+// @Summary List audit entries
+// @Tags audit
+// @Description List the latest audited actions of user. Admins list actions of every user
+// @ID listAudit
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param actor query string false "Id of user performed action, only admins may set other user"
+// @Param action query string false "Type of action" Enums(user.registered, user.logged_in, url.created, url.prolonged, url.rules_updated, url.variants_updated, url.variant_promoted, url.deleted, url.imported)
+// @Param target query string false "Alias of url or id of user affected by action"
+// @Param from query string false "Lower inclusive bound of action time in RFC 3339"
+// @Param to query string false "Upper exclusive bound of action time in RFC 3339"
+// @Param limit query int false "Count of entries, 100 by default" minimum(1) maximum(1000)
+// @Success 200 {array} domain.AuditEntry "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 500 {object} problem "Server error"
+// @Router /audit [get]
+func (h *Handler) listAudit(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	filter, err := parseAuditFilter(c)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	entries, err := h.services.Audit.List(c.Request.Context(), userId, filter)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+func parseAuditFilter(c *gin.Context) (domain.AuditFilter, error) {
+	filter := domain.AuditFilter{
+		Action: c.Query("action"),
+		Target: c.Query("target"),
+	}
+
+	var err error
+
+	if actor := c.Query("actor"); actor != "" {
+		if filter.Actor, err = primitive.ObjectIDFromHex(actor); err != nil {
+			return domain.AuditFilter{}, ErrInvalidAuditActor
+		}
+	}
+
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return domain.AuditFilter{}, ErrInvalidAuditTime
+		}
+	}
+
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return domain.AuditFilter{}, ErrInvalidAuditTime
+		}
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		filter.Limit, err = strconv.ParseInt(limit, 10, 64)
+
+		if err != nil || filter.Limit < 1 || filter.Limit > maxAuditLimit {
+			return domain.AuditFilter{}, ErrInvalidAuditLimit
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_listAudit(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAudit, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+	actor := primitive.NewObjectID()
+	from := time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		query         string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:  "ok",
+			query: "?action=url.created&target=qwerty&from=2021-05-09T09:29:18Z&limit=10",
+			mockBehaviour: func(s *mockService.MockAudit, userId primitive.ObjectID) {
+				s.EXPECT().List(context.Background(), userId, domain.AuditFilter{
+					Action: domain.AuditURLCreated,
+					Target: "qwerty",
+					From:   from,
+					Limit:  10,
+				}).Return([]domain.AuditEntry{}, nil)
+			},
+			statusCode:   200,
+			responseBody: `[]`,
+		},
+		{
+			name:  "forbidden actor",
+			query: "?actor=" + actor.Hex(),
+			mockBehaviour: func(s *mockService.MockAudit, userId primitive.ObjectID) {
+				s.EXPECT().List(context.Background(), userId, domain.AuditFilter{Actor: actor}).
+					Return(nil, service.ErrAuditForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrAuditForbidden),
+		},
+		{
+			name:          "invalid actor",
+			query:         "?actor=sirius",
+			mockBehaviour: func(s *mockService.MockAudit, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidAuditActor),
+		},
+		{
+			name:          "invalid time",
+			query:         "?to=yesterday",
+			mockBehaviour: func(s *mockService.MockAudit, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidAuditTime),
+		},
+		{
+			name:          "limit exceeded",
+			query:         "?limit=1001",
+			mockBehaviour: func(s *mockService.MockAudit, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidAuditLimit),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auditService := mockService.NewMockAudit(c)
+			tt.mockBehaviour(auditService, userId)
+
+			services := &service.Services{Audit: auditService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/audit", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.listAudit)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/audit"+tt.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
@@ -3,7 +3,6 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/mebr0/tiny-url/internal/domain"
-	"github.com/mebr0/tiny-url/internal/repo"
 	"net/http"
 )
 
@@ -12,6 +11,9 @@ func (h *Handler) initAuthRoutes(api *gin.RouterGroup) {
 	{
 		users.POST("/register", h.register)
 		users.POST("/login", h.login)
+		users.POST("/verify", h.verify)
+		users.POST("/password/forgot", h.forgotPassword)
+		users.POST("/password/reset", h.resetPassword)
 	}
 }
 
@@ -23,25 +25,20 @@ func (h *Handler) initAuthRoutes(api *gin.RouterGroup) {
 // @Produce json
 // @Param input body domain.UserRegister true "Register info"
 // @Success 201 {string} null "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 422 {object} response "Invalid request body"
-// @Failure 500 {object} response "Server error"
+// @Failure 409 {object} problem "User already exists"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
 // @Router /auth/register [post]
 func (h *Handler) register(c *gin.Context) {
 	var toRegister domain.UserRegister
 
-	if err := c.BindJSON(&toRegister); err != nil {
-		newResponse(c, http.StatusUnprocessableEntity, "invalid request body")
+	if err := c.ShouldBindJSON(&toRegister); err != nil {
+		newBindErrorResponse(c, err)
 		return
 	}
 
 	if err := h.services.Register(c.Request.Context(), toRegister); err != nil {
-		if err == repo.ErrUserAlreadyExists {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
@@ -56,29 +53,108 @@ func (h *Handler) register(c *gin.Context) {
 // @Produce json
 // @Param input body domain.UserLogin true "Login credentials"
 // @Success 200 {object} domain.Tokens "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 422 {object} response "Invalid request body"
-// @Failure 500 {object} response "Server error"
+// @Failure 401 {object} problem "Invalid credentials"
+// @Failure 403 {object} problem "Email is not verified"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
 // @Router /auth/login [post]
 func (h *Handler) login(c *gin.Context) {
 	var toLogin domain.UserLogin
 
-	if err := c.BindJSON(&toLogin); err != nil {
-		newResponse(c, http.StatusUnprocessableEntity, "invalid request body")
+	if err := c.ShouldBindJSON(&toLogin); err != nil {
+		newBindErrorResponse(c, err)
 		return
 	}
 
 	token, err := h.services.Login(c.Request.Context(), toLogin)
 
 	if err != nil {
-		if err == repo.ErrUserNotFound {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
 	c.JSONP(http.StatusOK, token)
 }
+
+// @Summary Verify email
+// @Tags auth
+// @Description Confirm ownership of email with token sent after registration
+// @ID verify
+// @Accept json
+// @Produce json
+// @Param input body domain.UserVerify true "Verification token"
+// @Success 204 {null} nil "Operation finished successfully"
+// @Failure 400 {object} problem "Token is invalid, expired or already used"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /auth/verify [post]
+func (h *Handler) verify(c *gin.Context) {
+	var toVerify domain.UserVerify
+
+	if err := c.ShouldBindJSON(&toVerify); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.Verify(c.Request.Context(), toVerify); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Forgot password
+// @Tags auth
+// @Description Send password reset token to email, the same response is returned for unknown email
+// @ID forgotPassword
+// @Accept json
+// @Produce json
+// @Param input body domain.PasswordForgot true "Email of user"
+// @Success 202 {null} nil "Email is sent if user exists"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /auth/password/forgot [post]
+func (h *Handler) forgotPassword(c *gin.Context) {
+	var toForgot domain.PasswordForgot
+
+	if err := c.ShouldBindJSON(&toForgot); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.ForgotPassword(c.Request.Context(), toForgot); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.Status(http.StatusAccepted)
+}
+
+// @Summary Reset password
+// @Tags auth
+// @Description Set new password with token sent to email, email becomes verified
+// @ID resetPassword
+// @Accept json
+// @Produce json
+// @Param input body domain.PasswordReset true "Reset token and new password"
+// @Success 204 {null} nil "Operation finished successfully"
+// @Failure 400 {object} problem "Token is invalid, expired or already used"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /auth/password/reset [post]
+func (h *Handler) resetPassword(c *gin.Context) {
+	var toReset domain.PasswordReset
+
+	if err := c.ShouldBindJSON(&toReset); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	if err := h.services.ResetPassword(c.Request.Context(), toReset); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
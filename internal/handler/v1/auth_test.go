@@ -44,8 +44,12 @@ func TestHandler_register(t *testing.T) {
 			requestBody:   `{}`,
 			requestUser:   domain.UserRegister{},
 			mockBehaviour: func(s *mockService.MockAuth, user domain.UserRegister) {},
-			statusCode:    400,
-			responseBody:  `{"message":"invalid request body"}`,
+			statusCode:    422,
+			responseBody: problemBody(ErrValidation,
+				fieldError{Field: "name", Rule: "required"},
+				fieldError{Field: "email", Rule: "required"},
+				fieldError{Field: "password", Rule: "required"},
+			),
 		},
 		{
 			name:        "user already exists",
@@ -58,8 +62,8 @@ func TestHandler_register(t *testing.T) {
 			mockBehaviour: func(s *mockService.MockAuth, user domain.UserRegister) {
 				s.EXPECT().Register(context.Background(), user).Return(repo.ErrUserAlreadyExists)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"user already exists"}`,
+			statusCode:   409,
+			responseBody: problemBody(repo.ErrUserAlreadyExists),
 		},
 	}
 
@@ -80,7 +84,7 @@ func TestHandler_register(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.POST("/register", handler.register)
+			r.POST("/register", errorHandler, handler.register)
 
 			// Create Request
 			w := httptest.NewRecorder()
@@ -127,8 +131,8 @@ func TestHandler_login(t *testing.T) {
 			requestBody:   `{"email": "qweqweqwe", "password": "qweqweqwe"}`,
 			requestUser:   domain.UserLogin{},
 			mockBehaviour: func(s *mockService.MockAuth, user domain.UserLogin) {},
-			statusCode:    400,
-			responseBody:  `{"message":"invalid request body"}`,
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "email", Rule: "email"}),
 		},
 		{
 			name:        "user does not exists",
@@ -138,10 +142,23 @@ func TestHandler_login(t *testing.T) {
 				Password: "qweqweqwe",
 			},
 			mockBehaviour: func(s *mockService.MockAuth, user domain.UserLogin) {
-				s.EXPECT().Login(context.Background(), user).Return(domain.Tokens{}, repo.ErrUserNotFound)
+				s.EXPECT().Login(context.Background(), user).Return(domain.Tokens{}, service.ErrInvalidCredentials)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"user doesn't exists"}`,
+			statusCode:   401,
+			responseBody: problemBody(service.ErrInvalidCredentials),
+		},
+		{
+			name:        "user is not verified",
+			requestBody: `{"email": "qweqweqwe@gmail.com", "password": "qweqweqwe"}`,
+			requestUser: domain.UserLogin{
+				Email:    "qweqweqwe@gmail.com",
+				Password: "qweqweqwe",
+			},
+			mockBehaviour: func(s *mockService.MockAuth, user domain.UserLogin) {
+				s.EXPECT().Login(context.Background(), user).Return(domain.Tokens{}, service.ErrUserNotVerified)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrUserNotVerified),
 		},
 	}
 
@@ -162,7 +179,7 @@ func TestHandler_login(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.POST("/login", handler.login)
+			r.POST("/login", errorHandler, handler.login)
 
 			// Create Request
 			w := httptest.NewRecorder()
@@ -177,3 +194,198 @@ func TestHandler_login(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_verify(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuth)
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"token": "secret"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {
+				s.EXPECT().Verify(context.Background(), domain.UserVerify{Token: "secret"}).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: ``,
+		},
+		{
+			name:          "invalid request body",
+			requestBody:   `{}`,
+			mockBehaviour: func(s *mockService.MockAuth) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "token", Rule: "required"}),
+		},
+		{
+			name:        "invalid token",
+			requestBody: `{"token": "secret"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {
+				s.EXPECT().Verify(context.Background(), domain.UserVerify{Token: "secret"}).Return(service.ErrInvalidToken)
+			},
+			statusCode:   400,
+			responseBody: problemBody(service.ErrInvalidToken),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mockService.NewMockAuth(c)
+			tt.mockBehaviour(auth)
+
+			services := &service.Services{Auth: auth}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/verify", errorHandler, handler.verify)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/verify", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_forgotPassword(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuth)
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"email": "qweqweqwe@gmail.com"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {
+				s.EXPECT().ForgotPassword(context.Background(), domain.PasswordForgot{Email: "qweqweqwe@gmail.com"}).Return(nil)
+			},
+			statusCode:   202,
+			responseBody: ``,
+		},
+		{
+			name:          "invalid request body",
+			requestBody:   `{"email": "qweqweqwe"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "email", Rule: "email"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mockService.NewMockAuth(c)
+			tt.mockBehaviour(auth)
+
+			services := &service.Services{Auth: auth}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/password/forgot", errorHandler, handler.forgotPassword)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/password/forgot", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_resetPassword(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockAuth)
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"token": "secret", "password": "qweqweqwe"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {
+				s.EXPECT().ResetPassword(context.Background(), domain.PasswordReset{
+					Token:    "secret",
+					Password: "qweqweqwe",
+				}).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: ``,
+		},
+		{
+			name:          "short password",
+			requestBody:   `{"token": "secret", "password": "qwe"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "password", Rule: "min", Param: "8"}),
+		},
+		{
+			name:        "used token",
+			requestBody: `{"token": "secret", "password": "qweqweqwe"}`,
+			mockBehaviour: func(s *mockService.MockAuth) {
+				s.EXPECT().ResetPassword(context.Background(), gomock.Any()).Return(service.ErrInvalidToken)
+			},
+			statusCode:   400,
+			responseBody: problemBody(service.ErrInvalidToken),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			auth := mockService.NewMockAuth(c)
+			tt.mockBehaviour(auth)
+
+			services := &service.Services{Auth: auth}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/password/reset", errorHandler, handler.resetPassword)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/password/reset", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
@@ -1,7 +1,40 @@
 package v1
 
-import "errors"
+import (
+	"errors"
+	"github.com/mebr0/tiny-url/internal/domain"
+)
 
 var (
-	ErrURLExpired = errors.New("url expired")
+	ErrURLExpired           = domain.NewError(domain.KindGone, "url_expired", "url expired")
+	ErrURLPathNotForwarded  = domain.NewError(domain.KindInvalid, "url_path_not_forwarded", "url does not forward path")
+	ErrUnsafePath           = domain.NewError(domain.KindInvalid, "unsafe_path", "path cannot be joined safely")
+	ErrEmptyAlias           = domain.NewError(domain.KindInvalid, "empty_alias", "empty alias")
+	ErrInvalidID            = domain.NewError(domain.KindInvalid, "invalid_id", "invalid id")
+	ErrInvalidBody          = domain.NewError(domain.KindValidation, "invalid_body", "invalid request body")
+	ErrValidation           = domain.NewError(domain.KindValidation, "validation_failed", "request body has invalid fields")
+	ErrFiltersCombined      = domain.NewError(domain.KindInvalid, "invalid_query", "expired and health parameters cannot be combined")
+	ErrInvalidHealth        = domain.NewError(domain.KindInvalid, "invalid_query", "health parameter must be broken or healthy")
+	ErrInvalidExpired       = domain.NewError(domain.KindInvalid, "invalid_query", "expired parameter not boolean")
+	ErrEmptySearchQuery     = domain.NewError(domain.KindInvalid, "invalid_query", "empty search query")
+	ErrInvalidAuditActor    = domain.NewError(domain.KindInvalid, "invalid_query", "invalid actor")
+	ErrInvalidAuditTime     = domain.NewError(domain.KindInvalid, "invalid_query", "invalid time, expected RFC 3339")
+	ErrInvalidAuditLimit    = domain.NewError(domain.KindInvalid, "invalid_query", "invalid limit, expected from 1 to 1000")
+	ErrInvalidExportFormat  = domain.NewError(domain.KindInvalid, "invalid_query", "format parameter must be csv, json or ndjson")
+	ErrInvalidImportFormat  = domain.NewError(domain.KindInvalid, "invalid_query", "format parameter must be csv, json, ndjson or html")
+	ErrInvalidConflict      = domain.NewError(domain.KindInvalid, "invalid_query", "conflict parameter must be skip, overwrite or rename")
+	ErrInvalidDryRun        = domain.NewError(domain.KindInvalid, "invalid_query", "dryRun parameter not boolean")
+	ErrInvalidStatsTime     = domain.NewError(domain.KindInvalid, "invalid_query", "invalid time, expected RFC 3339")
+	ErrInvalidStatsRange    = domain.NewError(domain.KindInvalid, "invalid_query", "from must precede to by at most 366 days")
+	ErrInvalidTimezone      = domain.NewError(domain.KindInvalid, "invalid_query", "tz parameter must be IANA time zone")
+	ErrInvalidStatsInterval = domain.NewError(domain.KindInvalid, "invalid_query", "interval parameter must be hour, day or week")
+	ErrInvalidStatsLimit    = domain.NewError(domain.KindInvalid, "invalid_query", "invalid limit, expected from 1 to 100")
+	ErrImportTooLarge       = domain.NewError(domain.KindTooLarge, "import_too_large", "imported file exceeds 10 MB")
+	ErrEmptyAuthHeader      = domain.NewError(domain.KindUnauthorized, "invalid_authorization", "empty auth header")
+	ErrInvalidAuthHeader    = domain.NewError(domain.KindUnauthorized, "invalid_authorization", "invalid auth header")
+	ErrEmptyToken           = domain.NewError(domain.KindUnauthorized, "invalid_authorization", "token is empty")
+	ErrInvalidToken         = domain.NewError(domain.KindUnauthorized, "invalid_authorization", "invalid token")
+
+	// errNoUser means identity of user was not checked before handler
+	errNoUser = errors.New("user not found in context")
 )
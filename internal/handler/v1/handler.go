@@ -4,27 +4,37 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/mebr0/tiny-url/internal/service"
 	"github.com/mebr0/tiny-url/pkg/auth"
+	"github.com/mebr0/tiny-url/pkg/bot"
+	"github.com/mebr0/tiny-url/pkg/geo"
 )
 
 type Handler struct {
 	services     *service.Services
 	tokenManager auth.TokenManager
+	geoResolver  geo.Resolver
+	botDetector  bot.Detector
 }
 
-func NewHandler(services *service.Services, tokenManager auth.TokenManager) *Handler {
+func NewHandler(services *service.Services, tokenManager auth.TokenManager, geoResolver geo.Resolver,
+	botDetector bot.Detector) *Handler {
 	return &Handler{
 		services:     services,
 		tokenManager: tokenManager,
+		geoResolver:  geoResolver,
+		botDetector:  botDetector,
 	}
 }
 
 func (h *Handler) Init(api *gin.RouterGroup) {
-	v1 := api.Group("/v1")
+	v1 := api.Group("/v1", errorHandler, auditClient)
 	{
 		h.initUsersRoutes(v1)
 		h.initAuthRoutes(v1)
 		h.initURLsRoutes(v1)
 		h.initRedirectRoutes(v1)
+		h.initWebhooksRoutes(v1)
+		h.initAuditRoutes(v1)
+		h.initStatsRoutes(v1)
 
 		v1.GET("/ping", h.userIdentity, h.ping)
 	}
@@ -1,9 +1,11 @@
 package v1
 
 import (
-	"errors"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"strings"
 )
 
@@ -12,31 +14,73 @@ const (
 	userCtx             = "userId"
 )
 
+// userIdentity accepts token of existing user issued for current generation of its tokens, so tokens of deleted
+// users and tokens issued before password change are rejected
 func (h *Handler) userIdentity(c *gin.Context) {
-	id, err := h.parseAuthHeader(c)
+	id, generation, err := h.parseAuthHeader(c)
 
 	if err != nil {
-		newResponse(c, http.StatusUnauthorized, err.Error())
+		newErrorResponse(c, err)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(id)
+
+	if err != nil {
+		newErrorResponse(c, ErrInvalidToken)
+		return
+	}
+
+	user, err := h.services.Users.Get(c.Request.Context(), userId)
+
+	if err != nil {
+		if err == repo.ErrUserNotFound {
+			err = ErrInvalidToken
+		}
+
+		newErrorResponse(c, err)
+		return
+	}
+
+	if user.TokenGeneration != generation {
+		newErrorResponse(c, ErrInvalidToken)
+		return
 	}
 
 	c.Set(userCtx, id)
 }
 
-func (h *Handler) parseAuthHeader(c *gin.Context) (string, error) {
+func (h *Handler) parseAuthHeader(c *gin.Context) (string, int64, error) {
 	header := c.GetHeader(authorizationHeader)
 
 	if header == "" {
-		return "", errors.New("empty auth header")
+		return "", 0, ErrEmptyAuthHeader
 	}
 
 	headerParts := strings.Split(header, " ")
 	if len(headerParts) != 2 || headerParts[0] != "Bearer" {
-		return "", errors.New("invalid auth header")
+		return "", 0, ErrInvalidAuthHeader
 	}
 
 	if len(headerParts[1]) == 0 {
-		return "", errors.New("token is empty")
+		return "", 0, ErrEmptyToken
 	}
 
-	return h.tokenManager.Decode(headerParts[1])
+	id, generation, err := h.tokenManager.Decode(headerParts[1])
+
+	if err != nil {
+		return "", 0, ErrInvalidToken
+	}
+
+	return id, generation, nil
+}
+
+// auditClient passes origin of request to services recording audited actions
+func auditClient(c *gin.Context) {
+	ctx := service.WithAuditClient(c.Request.Context(), domain.AuditClient{
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	})
+
+	c.Request = c.Request.WithContext(ctx)
 }
@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/mebr0/tiny-url/pkg/auth"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_userIdentity(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockUsers, userId primitive.ObjectID)
+
+	tokenManager, _ := auth.NewJWTManager("key")
+
+	userId := primitive.NewObjectID()
+
+	// Token issued before the first revocation
+	token, _ := tokenManager.Issue(userId.Hex(), 0, time.Hour)
+
+	tests := []struct {
+		name          string
+		header        string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:   "ok",
+			header: "Bearer " + token,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId).Return(domain.User{ID: userId}, nil)
+			},
+			statusCode:   200,
+			responseBody: userId.Hex(),
+		},
+		{
+			name:   "password changed",
+			header: "Bearer " + token,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId).Return(domain.User{ID: userId, TokenGeneration: 1}, nil)
+			},
+			statusCode:   401,
+			responseBody: problemBody(ErrInvalidToken),
+		},
+		{
+			name:   "user deleted",
+			header: "Bearer " + token,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId).Return(domain.User{}, repo.ErrUserNotFound)
+			},
+			statusCode:   401,
+			responseBody: problemBody(ErrInvalidToken),
+		},
+		{
+			name:          "invalid token",
+			header:        "Bearer qwe",
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    401,
+			responseBody:  problemBody(ErrInvalidToken),
+		},
+		{
+			name:          "empty header",
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    401,
+			responseBody:  problemBody(ErrEmptyAuthHeader),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			usersService := mockService.NewMockUsers(c)
+			tt.mockBehaviour(usersService, userId)
+
+			services := &service.Services{Users: usersService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: tokenManager,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/me", errorHandler, handler.userIdentity, func(c *gin.Context) {
+				c.String(200, c.GetString(userCtx))
+			})
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/me", nil)
+
+			if tt.header != "" {
+				req.Header.Set(authorizationHeader, tt.header)
+			}
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
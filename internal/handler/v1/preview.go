@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"html/template"
+	"net/http"
+)
+
+// previewPage describes destination of URL with Open Graph tags for link previews, people misdetected as bots
+// are still redirected by refresh
+var previewPage = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta property="og:type" content="website">
+<meta property="og:url" content="{{.URL}}">
+<meta property="og:title" content="{{.Title}}">
+{{- with .Description}}
+<meta property="og:description" content="{{.}}">
+<meta name="description" content="{{.}}">
+{{- end}}
+{{- with .Image}}
+<meta property="og:image" content="{{.}}">
+<meta name="twitter:card" content="summary_large_image">
+{{- end}}
+{{- with .Favicon}}
+<link rel="icon" href="{{.}}">
+{{- end}}
+<meta http-equiv="refresh" content="0; url={{.URL}}">
+</head>
+<body>
+<a href="{{.URL}}">{{.Title}}</a>
+</body>
+</html>
+`))
+
+type preview struct {
+	URL         string
+	Title       string
+	Description string
+	Image       string
+	Favicon     string
+}
+
+// preview answers bot with Open Graph metadata of original URL instead of redirection
+func (h *Handler) preview(c *gin.Context, url domain.URL) {
+	page := preview{
+		URL:         url.Original,
+		Title:       url.Metadata.Title,
+		Description: url.Metadata.Description,
+		Image:       url.Metadata.Image,
+		Favicon:     url.Metadata.Favicon,
+	}
+
+	if page.Title == "" {
+		page.Title = url.Original
+	}
+
+	var buf bytes.Buffer
+
+	if err := previewPage.Execute(&buf, page); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/html; charset=utf-8", buf.Bytes())
+}
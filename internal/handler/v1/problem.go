@@ -0,0 +1,170 @@
+package v1
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+const (
+	problemContentType = "application/problem+json"
+	problemType        = "about:blank"
+	internalCode       = "internal_error"
+	internalDetail     = "internal server error"
+)
+
+// Statuses of responses to errors of every kind
+var kindStatuses = map[domain.ErrorKind]int{
+	domain.KindInvalid:      http.StatusBadRequest,
+	domain.KindUnauthorized: http.StatusUnauthorized,
+	domain.KindForbidden:    http.StatusForbidden,
+	domain.KindNotFound:     http.StatusNotFound,
+	domain.KindConflict:     http.StatusConflict,
+	domain.KindGone:         http.StatusGone,
+	domain.KindValidation:   http.StatusUnprocessableEntity,
+	domain.KindUpstream:     http.StatusBadGateway,
+	domain.KindTooLarge:     http.StatusRequestEntityTooLarge,
+}
+
+// Invalid fields are named as in json body
+func init() {
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+
+			if name == "-" {
+				return ""
+			}
+
+			return name
+		})
+	}
+}
+
+type problem struct {
+	// Type of problem
+	Type string `json:"type" example:"about:blank"`
+	// Summary of problem, the same for every status
+	Title string `json:"title" example:"Not Found"`
+	// HTTP status code
+	Status int `json:"status" example:"404"`
+	// Explanation of this occurrence of problem
+	Detail string `json:"detail" example:"url doesn't exists"`
+	// Stable machine-readable code of error
+	Code string `json:"code" example:"url_not_found"`
+	// Invalid fields of request body
+	Errors []fieldError `json:"errors,omitempty"`
+} // @name Problem
+
+type fieldError struct {
+	// Path of field in request body
+	Field string `json:"field" example:"rules[0].destination"`
+	// Failed rule of validation
+	Rule string `json:"rule" example:"min"`
+	// Parameter of rule
+	Param string `json:"param,omitempty" example:"8"`
+} // @name FieldError
+
+// newErrorResponse aborts request with error, response is written by error handler
+func newErrorResponse(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// newBindErrorResponse aborts request with error of binding request body
+func newBindErrorResponse(c *gin.Context, err error) {
+	_ = c.Error(err).SetType(gin.ErrorTypeBind)
+	c.Abort()
+}
+
+// errorHandler answers with RFC 7807 problem describing the last error of request.
+// Text of internal errors is logged and never shown to client
+func errorHandler(c *gin.Context) {
+	c.Next()
+
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+
+	last := c.Errors.Last()
+	p := newProblem(last)
+
+	if p.Status == http.StatusInternalServerError {
+		logging.FromContext(c.Request.Context()).WithError(last.Err).Error("Request failed")
+	}
+
+	c.Header("Content-Type", problemContentType)
+	c.JSON(p.Status, p)
+}
+
+func newProblem(ginErr *gin.Error) problem {
+	err := ginErr.Err
+
+	if ginErr.IsType(gin.ErrorTypeBind) {
+		var invalid validator.ValidationErrors
+
+		if !errors.As(err, &invalid) {
+			return problemOf(ErrInvalidBody)
+		}
+
+		p := problemOf(ErrValidation)
+		p.Errors = fieldErrors(invalid)
+
+		return p
+	}
+
+	var domainErr *domain.Error
+
+	if errors.As(err, &domainErr) {
+		if _, ok := kindStatuses[domainErr.Kind]; ok {
+			return problemOf(domainErr)
+		}
+	}
+
+	return problem{
+		Type:   problemType,
+		Title:  http.StatusText(http.StatusInternalServerError),
+		Status: http.StatusInternalServerError,
+		Detail: internalDetail,
+		Code:   internalCode,
+	}
+}
+
+func problemOf(err *domain.Error) problem {
+	status := kindStatuses[err.Kind]
+
+	return problem{
+		Type:   problemType,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Message,
+		Code:   err.Code,
+	}
+}
+
+func fieldErrors(invalid validator.ValidationErrors) []fieldError {
+	fields := make([]fieldError, 0, len(invalid))
+
+	for _, e := range invalid {
+		// Namespace starts with name of bound type
+		field := e.Namespace()
+
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+
+		fields = append(fields, fieldError{
+			Field: field,
+			Rule:  e.Tag(),
+			Param: e.Param(),
+		})
+	}
+
+	return fields
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"net/http/httptest"
+	"testing"
+)
+
+// problemBody is json of problem answered to err, fields are expected only for failed validation
+func problemBody(err *domain.Error, fields ...fieldError) string {
+	p := problemOf(err)
+	p.Errors = fields
+
+	data, _ := json.Marshal(p)
+
+	return string(data)
+}
+
+func TestErrorHandler(t *testing.T) {
+	type body struct {
+		Name  string                `json:"name" binding:"required,alpha,min=4"`
+		Rules []domain.RedirectRule `json:"rules" binding:"dive"`
+	}
+
+	tests := []struct {
+		name         string
+		handler      gin.HandlerFunc
+		requestBody  string
+		statusCode   int
+		responseBody string
+	}{
+		{
+			name: "domain error",
+			handler: func(c *gin.Context) {
+				newErrorResponse(c, ErrInvalidID)
+			},
+			statusCode:   400,
+			responseBody: `{"type":"about:blank","title":"Bad Request","status":400,"detail":"invalid id","code":"invalid_id"}`,
+		},
+		{
+			name: "internal error",
+			handler: func(c *gin.Context) {
+				newErrorResponse(c, errors.New("connection refused"))
+			},
+			statusCode:   500,
+			responseBody: `{"type":"about:blank","title":"Internal Server Error","status":500,"detail":"internal server error","code":"internal_error"}`,
+		},
+		{
+			name: "malformed body",
+			handler: func(c *gin.Context) {
+				var b body
+
+				if err := c.ShouldBindJSON(&b); err != nil {
+					newBindErrorResponse(c, err)
+				}
+			},
+			requestBody:  `{"name":`,
+			statusCode:   422,
+			responseBody: problemBody(ErrInvalidBody),
+		},
+		{
+			name: "invalid fields",
+			handler: func(c *gin.Context) {
+				var b body
+
+				if err := c.ShouldBindJSON(&b); err != nil {
+					newBindErrorResponse(c, err)
+				}
+			},
+			requestBody: `{"name":"Si","rules":[{"os":"ios"}]}`,
+			statusCode:  422,
+			responseBody: problemBody(ErrValidation,
+				fieldError{Field: "name", Rule: "min", Param: "4"},
+				fieldError{Field: "rules[0].destination", Rule: "required"},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/", errorHandler, tt.handler)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, problemContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
@@ -2,33 +2,52 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/metrics"
 	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/urlutil"
+	"github.com/mebr0/tiny-url/pkg/useragent"
+	"golang.org/x/text/language"
+	"math/rand"
+	"net"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 )
 
+// Prefix of cookie keeping sticky variant of URL
+const variantCookiePrefix = "variant_"
+
 func (h *Handler) initRedirectRoutes(api *gin.RouterGroup) {
 	users := api.Group("/to")
 	{
-		users.GET("/:alias", h.redirectWithAlias)
+		users.GET("/*path", h.redirectWithAlias)
 	}
 }
 
 // @Summary Redirect
 // @Tags urls
-// @Description Redirect with alias
+// @Description Redirect with alias. Destination is chosen by first rule matching User-Agent, Accept-Language
+// @Description and country of client, otherwise by weighted rotation of variants, otherwise original URL is used.
+// @Description Sticky variant is kept in cookie. Query parameters are forwarded to destination if it is enabled for URL.
+// @Description Bots, such as link previews and crawlers, get page with Open Graph metadata of URL and do not consume clicks
 // @ID redirectWithAlias
 // @Accept json
-// @Produce json
-// @Param alias path string true "Alias for redirection"
-// @Success 301 {string} null "Redirected successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 500 {object} response "Server error"
-// @Router /to/{alias} [get]
+// @Produce json,html
+// @Param path path string true "Alias for redirection, optionally followed by path forwarded to destination"
+// @Success 200 {string} string "Page with metadata for bots"
+// @Success 302 {string} null "Redirected successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 404 {object} problem "Not found"
+// @Failure 410 {object} problem "URL expired"
+// @Failure 500 {object} problem "Server error"
+// @Router /to/{path} [get]
 func (h *Handler) redirectWithAlias(c *gin.Context) {
-	alias := c.Param("alias")
+	alias, suffix := splitAlias(rawParam(c, "path"))
 
 	if alias == "" {
-		newResponse(c, http.StatusBadRequest, "empty alias")
+		newErrorResponse(c, ErrEmptyAlias)
 		return
 	}
 
@@ -36,18 +55,206 @@ func (h *Handler) redirectWithAlias(c *gin.Context) {
 
 	if err != nil {
 		if err == repo.ErrURLNotFound {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
+			metrics.Redirects.WithLabelValues(metrics.ResultMiss).Inc()
 		}
 
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
 	if url.Expired() {
-		newResponse(c, http.StatusBadRequest, ErrURLExpired.Error())
+		metrics.Redirects.WithLabelValues(metrics.ResultExpired).Inc()
+		newErrorResponse(c, ErrURLExpired)
+		return
+	}
+
+	if suffix != "" && !url.ForwardPath {
+		newErrorResponse(c, ErrURLPathNotForwarded)
+		return
+	}
+
+	// Link previews and crawlers get metadata of URL instead of redirection, so they do not consume clicks
+	if h.botDetector.IsBot(c.Request) {
+		h.services.URLs.Click(c.Request.Context(), url, domain.Click{Time: time.Now(), Bot: true})
+		metrics.Redirects.WithLabelValues(metrics.ResultBot).Inc()
+
+		h.preview(c, url)
+		return
+	}
+
+	destination, variant, err := h.destination(c, url, suffix)
+
+	if err != nil {
+		if err == urlutil.ErrUnsafePath {
+			err = ErrUnsafePath
+		}
+
+		newErrorResponse(c, err)
 		return
 	}
 
-	c.Redirect(http.StatusMovedPermanently, url.Original)
+	h.services.URLs.Click(c.Request.Context(), url, h.click(c, variant))
+	metrics.Redirects.WithLabelValues(metrics.ResultHit).Inc()
+
+	// Destination depends on request and url may change or expire, so redirection is never cached
+	c.Header("Cache-Control", "private, no-store")
+	c.Redirect(http.StatusFound, destination)
+}
+
+// destination resolves URL for redirection of request and name of chosen variant if any.
+// Matched rule takes precedence over variants, original URL is used if neither is present
+func (h *Handler) destination(c *gin.Context, url domain.URL, suffix string) (string, string, error) {
+	destination := url.Original
+	variantName := ""
+
+	if rule, ok := h.matchRule(c, url); ok {
+		destination = rule.Destination
+	} else if variant, ok := h.variant(c, url); ok {
+		destination = variant.Destination
+		variantName = variant.Name
+	}
+
+	if suffix != "" {
+		var err error
+
+		if destination, err = urlutil.JoinPath(destination, suffix); err != nil {
+			return "", "", err
+		}
+	}
+
+	if url.ForwardQuery {
+		destination, err := urlutil.MergeQuery(destination, c.Request.URL.Query())
+
+		return destination, variantName, err
+	}
+
+	return destination, variantName, nil
+}
+
+func (h *Handler) matchRule(c *gin.Context, url domain.URL) (domain.RedirectRule, bool) {
+	if len(url.Rules) == 0 {
+		return domain.RedirectRule{}, false
+	}
+
+	return url.MatchRule(h.client(c, url.UsesCountry()))
+}
+
+// variant picks variant of url by weight, sticky variant is kept in cookie until expiration of url
+func (h *Handler) variant(c *gin.Context, url domain.URL) (domain.Variant, bool) {
+	total := url.TotalWeight()
+
+	if total <= 0 {
+		return domain.Variant{}, false
+	}
+
+	cookie := variantCookiePrefix + url.Alias
+
+	if url.StickyVariants {
+		// Variant from cookie may be already removed, then new one is picked
+		if name, err := c.Cookie(cookie); err == nil {
+			if variant, ok := url.Variant(name); ok {
+				return variant, true
+			}
+		}
+	}
+
+	variant, ok := url.PickVariant(rand.Intn(total))
+
+	if ok && url.StickyVariants {
+		c.SetCookie(cookie, variant.Name, int(time.Until(url.ExpiredAt).Seconds()), "/", "", false, true)
+	}
+
+	return variant, ok
+}
+
+// splitAlias splits escaped path into alias and path suffix after it
+func splitAlias(path string) (string, string) {
+	if i := strings.IndexByte(path, '/'); i >= 0 {
+		return path[:i], path[i+1:]
+	}
+
+	return path, ""
+}
+
+// client describes requester of redirection, country is resolved only if required
+func (h *Handler) client(c *gin.Context, withCountry bool) domain.Client {
+	agent := useragent.Parse(c.GetHeader("User-Agent"))
+
+	client := domain.Client{
+		OS:        agent.OS,
+		Device:    agent.Device,
+		Languages: acceptLanguages(c.GetHeader("Accept-Language")),
+	}
+
+	// Unknown country matches only rules without country
+	if withCountry {
+		client.Country = h.country(c)
+	}
+
+	return client
+}
+
+// click describes redirection of request to variant for statistics
+func (h *Handler) click(c *gin.Context, variant string) domain.Click {
+	ua := c.GetHeader("User-Agent")
+	agent := useragent.Parse(ua)
+
+	return domain.Click{
+		Variant:  variant,
+		Time:     time.Now(),
+		Referrer: referrerHost(c.GetHeader("Referer")),
+		Country:  h.country(c),
+		Browser:  agent.Browser,
+		OS:       agent.OS,
+		Visitor:  c.ClientIP() + " " + ua,
+	}
+}
+
+// country resolves country of requester, empty if unknown
+func (h *Handler) country(c *gin.Context) string {
+	ip := net.ParseIP(c.ClientIP())
+
+	if ip == nil {
+		return ""
+	}
+
+	country, err := h.geoResolver.Country(c.Request.Context(), ip)
+
+	if err != nil {
+		return ""
+	}
+
+	return country
+}
+
+// referrerHost returns lowercase host of Referer header without www prefix, empty if header is absent or invalid
+func referrerHost(referer string) string {
+	u, err := url.Parse(referer)
+
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// acceptLanguages returns lowercase tags and their base languages from Accept-Language header in order of preference
+func acceptLanguages(header string) []string {
+	tags, _, err := language.ParseAcceptLanguage(header)
+
+	if err != nil {
+		return nil
+	}
+
+	languages := make([]string, 0, 2*len(tags))
+
+	for _, tag := range tags {
+		languages = append(languages, strings.ToLower(tag.String()))
+
+		if base, confidence := tag.Base(); confidence != language.No {
+			languages = append(languages, base.String())
+		}
+	}
+
+	return languages
 }
@@ -3,6 +3,7 @@ package v1
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
@@ -10,38 +11,499 @@ import (
 	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/internal/service"
 	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/mebr0/tiny-url/pkg/bot"
+	"github.com/mebr0/tiny-url/pkg/geo"
+	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
 
+// clickMatcher matches click equal to expected one at any time
+type clickMatcher struct {
+	expected domain.Click
+}
+
+func (m clickMatcher) Matches(x interface{}) bool {
+	click, ok := x.(domain.Click)
+
+	if !ok || click.Time.IsZero() {
+		return false
+	}
+
+	click.Time = time.Time{}
+
+	return click == m.expected
+}
+
+func (m clickMatcher) String() string {
+	return fmt.Sprintf("is click %+v", m.expected)
+}
+
+// User-Agent of requests without own one
+const browserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+// clickOf matches click to variant of request with User-Agent of browser
+func clickOf(variant string) clickMatcher {
+	return clickMatcher{domain.Click{
+		Variant: variant,
+		Country: "KZ",
+		Browser: "chrome",
+		OS:      "windows",
+		Visitor: "192.0.2.1 " + browserAgent,
+	}}
+}
+
 func TestHandler_redirectWithAlias(t *testing.T) {
 	type mockBehaviour func(s *mockService.MockURLs, alias string)
 
 	userId := primitive.NewObjectID()
 
+	rules := []domain.RedirectRule{
+		{OS: "ios", Destination: "https://apps.apple.com"},
+		{Language: "de", Destination: "https://google.de"},
+		{Country: "KZ", Destination: "https://google.kz"},
+	}
+
+	variants := []domain.Variant{
+		{Name: "a", Destination: "https://google.com/a", Weight: 0},
+		{Name: "b", Destination: "https://google.com/b", Weight: 1},
+	}
+
 	tests := []struct {
 		name          string
 		alias         string
+		suffix        string
+		query         string
+		headers       map[string]string
 		mockBehaviour mockBehaviour
 		statusCode    int
 		responseBody  string
+		location      string
+		cookie        string
 	}{
+		{
+			name:  "ok with referrer",
+			alias: "alias",
+			headers: map[string]string{
+				"Referer":    "https://www.Google.com/search?q=tiny",
+				"User-Agent": "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0",
+			},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickMatcher{domain.Click{
+					Referrer: "google.com",
+					Country:  "KZ",
+					Browser:  "firefox",
+					OS:       "linux",
+					Visitor:  "192.0.2.1 Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0",
+				}})
+			},
+			statusCode: 302,
+			location:   "https://google.com",
+		},
 		{
 			name:  "ok",
 			alias: "alias",
 			mockBehaviour: func(s *mockService.MockURLs, alias string) {
-				s.EXPECT().Get(context.Background(), alias).Return(domain.URL{
+				url := domain.URL{
 					Alias:     "alias",
 					Original:  "https://google.com",
 					CreatedAt: time.Now(),
 					ExpiredAt: time.Now().Add(5 * time.Minute),
 					Owner:     userId,
-				}, nil)
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
 			},
-			statusCode:   301,
+			statusCode:   302,
 			responseBody: ``,
+			location:     "https://google.com",
+		},
+		{
+			name:  "ok without query forwarding",
+			alias: "alias",
+			query: "ref=x",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com/?utm_source=mail",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://google.com/?utm_source=mail",
+		},
+		{
+			name:  "ok with query forwarding",
+			alias: "alias",
+			query: "ref=x&utm_source=spoofed",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:        "alias",
+					Original:     "https://google.com/?utm_source=mail",
+					CreatedAt:    time.Now(),
+					ExpiredAt:    time.Now().Add(5 * time.Minute),
+					Owner:        userId,
+					ForwardQuery: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://google.com/?ref=x&utm_source=mail",
+		},
+		{
+			name:    "ok with os rule",
+			alias:   "alias",
+			headers: map[string]string{"User-Agent": "Mozilla/5.0 (iPhone; CPU iPhone OS 14_4 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Rules:     rules,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickMatcher{domain.Click{
+					Country: "KZ",
+					Browser: "other",
+					OS:      "ios",
+					Visitor: "192.0.2.1 Mozilla/5.0 (iPhone; CPU iPhone OS 14_4 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148",
+				}})
+			},
+			statusCode: 302,
+			location:   "https://apps.apple.com",
+		},
+		{
+			name:    "ok with language rule",
+			alias:   "alias",
+			headers: map[string]string{"Accept-Language": "de-CH, en;q=0.8"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Rules:     rules,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://google.de",
+		},
+		{
+			name:    "ok with country rule",
+			alias:   "alias",
+			headers: nil,
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Rules:     rules,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://google.kz",
+		},
+		{
+			name:  "ok with variant",
+			alias: "alias",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Variants:  variants,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf("b"))
+			},
+			statusCode: 302,
+			location:   "https://google.com/b",
+		},
+		{
+			name:  "ok with new sticky variant",
+			alias: "alias",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:          "alias",
+					Original:       "https://google.com",
+					CreatedAt:      time.Now(),
+					ExpiredAt:      time.Now().Add(5 * time.Minute),
+					Owner:          userId,
+					Variants:       variants,
+					StickyVariants: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf("b"))
+			},
+			statusCode: 302,
+			location:   "https://google.com/b",
+			cookie:     "variant_alias=b",
+		},
+		{
+			name:    "ok with sticky variant from cookie",
+			alias:   "alias",
+			headers: map[string]string{"Cookie": "variant_alias=a"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:          "alias",
+					Original:       "https://google.com",
+					CreatedAt:      time.Now(),
+					ExpiredAt:      time.Now().Add(5 * time.Minute),
+					Owner:          userId,
+					Variants:       variants,
+					StickyVariants: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf("a"))
+			},
+			statusCode: 302,
+			location:   "https://google.com/a",
+		},
+		{
+			name:    "ok with rule over variant",
+			alias:   "alias",
+			headers: map[string]string{"Accept-Language": "de"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Rules:     rules,
+					Variants:  variants,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://google.de",
+		},
+		{
+			name:   "ok with path forwarding",
+			alias:  "alias",
+			suffix: "/getting-started/install",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com/v1",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://docs.example.com/v1/getting-started/install",
+		},
+		{
+			name:   "ok with path forwarding keeping query",
+			alias:  "alias",
+			suffix: "/guide",
+			query:  "ref=x",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com?lang=en",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://docs.example.com/guide?lang=en",
+		},
+		{
+			name:   "ok with trailing slash",
+			alias:  "alias",
+			suffix: "/",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: false,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickOf(""))
+			},
+			statusCode: 302,
+			location:   "https://docs.example.com",
+		},
+		{
+			name:   "path not forwarded",
+			alias:  "alias",
+			suffix: "/getting-started",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: false,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+			},
+			statusCode:   400,
+			responseBody: problemBody(ErrURLPathNotForwarded),
+		},
+		{
+			name:   "path with parent segment",
+			alias:  "alias",
+			suffix: "/../admin",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com/v1",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+			},
+			statusCode:   400,
+			responseBody: problemBody(ErrUnsafePath),
+		},
+		{
+			name:   "path with encoded slash",
+			alias:  "alias",
+			suffix: "/..%2F..%2Fadmin",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com/v1",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+			},
+			statusCode:   400,
+			responseBody: problemBody(ErrUnsafePath),
+		},
+		{
+			name:   "path with double slash",
+			alias:  "alias",
+			suffix: "//evil.com",
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:       "alias",
+					Original:    "https://docs.example.com",
+					CreatedAt:   time.Now(),
+					ExpiredAt:   time.Now().Add(5 * time.Minute),
+					Owner:       userId,
+					ForwardPath: true,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+			},
+			statusCode:   400,
+			responseBody: problemBody(ErrUnsafePath),
+		},
+		{
+			name:    "link preview",
+			alias:   "alias",
+			headers: map[string]string{"User-Agent": "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				url := domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com/?q=a&b",
+					CreatedAt: time.Now(),
+					ExpiredAt: time.Now().Add(5 * time.Minute),
+					Owner:     userId,
+					Metadata:  domain.URLMetadata{Title: "Google <Search>", Image: "https://google.com/logo.png"},
+					Variants:  variants,
+				}
+
+				s.EXPECT().Get(context.Background(), alias).Return(url, nil)
+				s.EXPECT().Click(context.Background(), url, clickMatcher{domain.Click{Bot: true}})
+			},
+			statusCode: 200,
+			responseBody: `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Google &lt;Search&gt;</title>
+<meta property="og:type" content="website">
+<meta property="og:url" content="https://google.com/?q=a&amp;b">
+<meta property="og:title" content="Google &lt;Search&gt;">
+<meta property="og:image" content="https://google.com/logo.png">
+<meta name="twitter:card" content="summary_large_image">
+<meta http-equiv="refresh" content="0; url=https://google.com/?q=a&amp;b">
+</head>
+<body>
+<a href="https://google.com/?q=a&amp;b">Google &lt;Search&gt;</a>
+</body>
+</html>
+`,
+		},
+		{
+			name:    "crawler of expired url",
+			alias:   "alias",
+			headers: map[string]string{"User-Agent": "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"},
+			mockBehaviour: func(s *mockService.MockURLs, alias string) {
+				s.EXPECT().Get(context.Background(), alias).Return(domain.URL{
+					Alias:     "alias",
+					Original:  "https://google.com",
+					ExpiredAt: time.Now().Add(-5 * time.Minute),
+				}, nil)
+			},
+			statusCode:   410,
+			responseBody: problemBody(ErrURLExpired),
 		},
 		{
 			name:  "url expired",
@@ -55,8 +517,8 @@ func TestHandler_redirectWithAlias(t *testing.T) {
 					Owner:     userId,
 				}, nil)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url expired"}`,
+			statusCode:   410,
+			responseBody: problemBody(ErrURLExpired),
 		},
 		{
 			name:  "url does not exists",
@@ -64,8 +526,8 @@ func TestHandler_redirectWithAlias(t *testing.T) {
 			mockBehaviour: func(s *mockService.MockURLs, alias string) {
 				s.EXPECT().Get(context.Background(), alias).Return(domain.URL{}, repo.ErrURLNotFound)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url doesn't exists"}`,
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
 		},
 	}
 
@@ -78,19 +540,31 @@ func TestHandler_redirectWithAlias(t *testing.T) {
 			urlsService := mockService.NewMockURLs(c)
 			tt.mockBehaviour(urlsService, tt.alias)
 
+			// Requests of httptest are sent from 192.0.2.1
+			geoResolver, err := geo.ReadCIDRResolver(strings.NewReader("192.0.2.0/24,KZ"))
+			require.NoError(t, err)
+
 			services := &service.Services{URLs: urlsService}
 			handler := &Handler{
 				services:     services,
 				tokenManager: nil,
+				geoResolver:  geoResolver,
+				botDetector:  bot.NewSignatureDetector(bot.DefaultSignatures),
 			}
 
 			// Init Endpoint
 			r := gin.New()
-			r.GET("/to/:alias", handler.redirectWithAlias)
+			r.GET("/to/*path", errorHandler, handler.redirectWithAlias)
 
 			// Create Request
 			w := httptest.NewRecorder()
-			req := httptest.NewRequest("GET", "/to/alias", bytes.NewBufferString(""))
+			req := httptest.NewRequest("GET", "/to/alias"+tt.suffix+"?"+tt.query, bytes.NewBufferString(""))
+
+			req.Header.Set("User-Agent", browserAgent)
+
+			for key, value := range tt.headers {
+				req.Header.Set(key, value)
+			}
 
 			// Make Request
 			r.ServeHTTP(w, req)
@@ -101,6 +575,18 @@ func TestHandler_redirectWithAlias(t *testing.T) {
 			if tt.responseBody != "" {
 				assert.Equal(t, tt.responseBody, w.Body.String())
 			}
+
+			if tt.location != "" {
+				assert.Equal(t, tt.location, w.Header().Get("Location"))
+			}
+
+			if tt.statusCode == 302 {
+				assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+			}
+
+			if tt.cookie != "" {
+				assert.Equal(t, true, strings.HasPrefix(w.Header().Get("Set-Cookie"), tt.cookie+";"))
+			}
 		})
 	}
 }
@@ -0,0 +1,160 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Range of statistics by default and at most
+const (
+	defaultStatsRange = 7 * 24 * time.Hour
+	maxStatsRange     = 366 * 24 * time.Hour
+)
+
+// Count of top values of every breakdown by default and at most
+const (
+	defaultStatsLimit = 10
+	maxStatsLimit     = 100
+)
+
+func (h *Handler) initStatsRoutes(api *gin.RouterGroup) {
+	stats := api.Group("/stats", h.userIdentity)
+	{
+		stats.GET("", h.getStats)
+		stats.GET("/:alias", h.getURLStats)
+	}
+}
+
+// @Summary Get statistics of URLs
+// @Tags stats
+// @Description Get clicks by time, top referrers, countries, browsers and operating systems and unique visitors
+// @Description across all URLs of user
+// @ID getStats
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param from query string false "Start of range in RFC 3339, 7 days before to by default"
+// @Param to query string false "End of range in RFC 3339, now by default"
+// @Param tz query string false "IANA time zone of series, UTC by default"
+// @Param interval query string false "Length of series bucket, day by default" Enums(hour, day, week)
+// @Param limit query int false "Count of top values of every breakdown, 10 by default" minimum(1) maximum(100)
+// @Success 200 {object} domain.Stats "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 500 {object} problem "Server error"
+// @Router /stats [get]
+func (h *Handler) getStats(c *gin.Context) {
+	h.stats(c, "")
+}
+
+// @Summary Get statistics of URL
+// @Tags stats
+// @Description Get clicks by time, top referrers, countries, browsers and operating systems and unique visitors of URL
+// @ID getURLStats
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Param from query string false "Start of range in RFC 3339, 7 days before to by default"
+// @Param to query string false "End of range in RFC 3339, now by default"
+// @Param tz query string false "IANA time zone of series, UTC by default"
+// @Param interval query string false "Length of series bucket, day by default" Enums(hour, day, week)
+// @Param limit query int false "Count of top values of every breakdown, 10 by default" minimum(1) maximum(100)
+// @Success 200 {object} domain.Stats "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "URL not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /stats/{alias} [get]
+func (h *Handler) getURLStats(c *gin.Context) {
+	h.stats(c, c.Param("alias"))
+}
+
+func (h *Handler) stats(c *gin.Context, alias string) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	query, err := parseStatsQuery(c)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	query.Alias = alias
+
+	stats, err := h.services.Stats.Get(c.Request.Context(), userId, query)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+func parseStatsQuery(c *gin.Context) (domain.StatsQuery, error) {
+	query := domain.StatsQuery{
+		To:       time.Now(),
+		Location: time.UTC,
+		Interval: c.DefaultQuery("interval", domain.StatsDay),
+		Limit:    defaultStatsLimit,
+	}
+
+	var err error
+
+	if to := c.Query("to"); to != "" {
+		if query.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return domain.StatsQuery{}, ErrInvalidStatsTime
+		}
+	}
+
+	query.From = query.To.Add(-defaultStatsRange)
+
+	if from := c.Query("from"); from != "" {
+		if query.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return domain.StatsQuery{}, ErrInvalidStatsTime
+		}
+	}
+
+	if !query.From.Before(query.To) || query.To.Sub(query.From) > maxStatsRange {
+		return domain.StatsQuery{}, ErrInvalidStatsRange
+	}
+
+	// Local time zone of server means nothing to client
+	if tz := c.Query("tz"); tz != "" {
+		if query.Location, err = time.LoadLocation(tz); err != nil || tz == "Local" {
+			return domain.StatsQuery{}, ErrInvalidTimezone
+		}
+	}
+
+	if query.Interval != domain.StatsHour && query.Interval != domain.StatsDay && query.Interval != domain.StatsWeek {
+		return domain.StatsQuery{}, ErrInvalidStatsInterval
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		query.Limit, err = strconv.Atoi(limit)
+
+		if err != nil || query.Limit < 1 || query.Limit > maxStatsLimit {
+			return domain.StatsQuery{}, ErrInvalidStatsLimit
+		}
+	}
+
+	return query, nil
+}
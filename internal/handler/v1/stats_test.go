@@ -0,0 +1,172 @@
+package v1
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_getStats(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockStats, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	almaty, err := time.LoadLocation("Asia/Almaty")
+	require.NoError(t, err)
+
+	from := time.Date(2021, 5, 9, 0, 0, 0, 0, almaty)
+	to := time.Date(2021, 5, 10, 0, 0, 0, 0, almaty)
+
+	stats := domain.Stats{
+		Alias:     "qwerty",
+		From:      from,
+		To:        to,
+		Timezone:  "Asia/Almaty",
+		Interval:  domain.StatsDay,
+		Clicks:    2,
+		Visitors:  1,
+		Series:    []domain.StatsPoint{{Time: from, Clicks: 2, Visitors: 1}},
+		Referrers: []domain.StatsCount{{Value: "google.com", Clicks: 2}},
+		Countries: []domain.StatsCount{{Value: "KZ", Clicks: 2}},
+		Browsers:  []domain.StatsCount{{Value: "chrome", Clicks: 2}},
+		OS:        []domain.StatsCount{{Value: "android", Clicks: 2}},
+	}
+
+	tests := []struct {
+		name          string
+		path          string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name: "ok",
+			path: "/stats/qwerty?from=2021-05-09T00:00:00%2B06:00&to=2021-05-10T00:00:00%2B06:00&tz=Asia/Almaty&limit=5",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId, domain.StatsQuery{
+					Alias:    "qwerty",
+					From:     time.Date(2021, 5, 9, 0, 0, 0, 0, time.FixedZone("", 6*60*60)),
+					To:       time.Date(2021, 5, 10, 0, 0, 0, 0, time.FixedZone("", 6*60*60)),
+					Location: almaty,
+					Interval: domain.StatsDay,
+					Limit:    5,
+				}).Return(stats, nil)
+			},
+			statusCode: 200,
+			responseBody: `{"alias":"qwerty","from":"2021-05-09T00:00:00+06:00","to":"2021-05-10T00:00:00+06:00",` +
+				`"timezone":"Asia/Almaty","interval":"day","clicks":2,"bots":0,"visitors":1,` +
+				`"series":[{"time":"2021-05-09T00:00:00+06:00","clicks":2,"bots":0,"visitors":1}],` +
+				`"referrers":[{"value":"google.com","clicks":2}],"countries":[{"value":"KZ","clicks":2}],` +
+				`"browsers":[{"value":"chrome","clicks":2}],"os":[{"value":"android","clicks":2}]}`,
+		},
+		{
+			name: "all urls by week",
+			path: "/stats?from=2021-05-09T00:00:00Z&to=2021-05-10T00:00:00Z&interval=week",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId, domain.StatsQuery{
+					From:     time.Date(2021, 5, 9, 0, 0, 0, 0, time.UTC),
+					To:       time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC),
+					Location: time.UTC,
+					Interval: domain.StatsWeek,
+					Limit:    defaultStatsLimit,
+				}).Return(domain.Stats{}, service.ErrURLForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrURLForbidden),
+		},
+		{
+			name: "url does not exist",
+			path: "/stats/qwerty?from=2021-05-09T00:00:00Z&to=2021-05-10T00:00:00Z",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId, gomock.Any()).Return(domain.Stats{}, repo.ErrURLNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
+		},
+		{
+			name:          "invalid time",
+			path:          "/stats?from=yesterday",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidStatsTime),
+		},
+		{
+			name:          "reversed range",
+			path:          "/stats?from=2021-05-10T00:00:00Z&to=2021-05-09T00:00:00Z",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidStatsRange),
+		},
+		{
+			name:          "too long range",
+			path:          "/stats?from=2020-01-01T00:00:00Z&to=2021-05-09T00:00:00Z",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidStatsRange),
+		},
+		{
+			name:          "invalid time zone",
+			path:          "/stats?tz=Mars/Olympus",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidTimezone),
+		},
+		{
+			name:          "invalid interval",
+			path:          "/stats?interval=month",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidStatsInterval),
+		},
+		{
+			name:          "invalid limit",
+			path:          "/stats?limit=101",
+			mockBehaviour: func(s *mockService.MockStats, userId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidStatsLimit),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			statsService := mockService.NewMockStats(c)
+			tt.mockBehaviour(statsService, userId)
+
+			services := &service.Services{Stats: statsService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			setUser := func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}
+			r.GET("/stats", errorHandler, setUser, handler.getStats)
+			r.GET("/stats/:alias", errorHandler, setUser, handler.getURLStats)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", tt.path, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
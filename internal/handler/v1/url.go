@@ -3,11 +3,15 @@ package v1
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/mebr0/tiny-url/internal/domain"
-	"github.com/mebr0/tiny-url/internal/repo"
-	"github.com/mebr0/tiny-url/internal/service"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
 	"strconv"
+	"strings"
+)
+
+const (
+	healthBroken  = "broken"
+	healthHealthy = "healthy"
 )
 
 func (h *Handler) initURLsRoutes(api *gin.RouterGroup) {
@@ -15,8 +19,16 @@ func (h *Handler) initURLsRoutes(api *gin.RouterGroup) {
 	{
 		users.GET("", h.listURLs)
 		users.POST("", h.createURL)
+		users.GET("/search", h.searchURLs)
+		users.GET("/export", h.exportURLs)
+		users.POST("/import", h.importURLs)
 		users.GET("/:alias", h.getURL)
 		users.PATCH("/:alias/prolong", h.prolongURL)
+		users.POST("/:alias/metadata", h.refreshURLMetadata)
+		users.PUT("/:alias/rules", h.setURLRules)
+		users.PUT("/:alias/variants", h.setURLVariants)
+		users.GET("/:alias/variants", h.listURLVariantStats)
+		users.POST("/:alias/variants/:name/promote", h.promoteURLVariant)
 		users.DELETE("/:alias", h.deleteURL)
 	}
 }
@@ -28,31 +40,46 @@ func (h *Handler) initURLsRoutes(api *gin.RouterGroup) {
 // @Security UsersAuth
 // @Accept json
 // @Produce json
+// @Param expired query bool false "Filter by expiration"
+// @Param health query string false "Filter by health of original URL" Enums(broken, healthy)
 // @Success 200 {array} domain.URL "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 401 {object} response "Invalid authorization"
-// @Failure 500 {object} response "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 500 {object} problem "Server error"
 // @Router /urls [get]
 func (h *Handler) listURLs(c *gin.Context) {
 	userIdHex, ok := c.Get("userId")
 
 	if !ok {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	expired := c.Query("expired")
+	health := c.Query("health")
+
+	if expired != "" && health != "" {
+		newErrorResponse(c, ErrFiltersCombined)
+		return
+	}
 
 	var urls []domain.URL
 
-	if expired == "" {
+	if health != "" {
+		if health != healthBroken && health != healthHealthy {
+			newErrorResponse(c, ErrInvalidHealth)
+			return
+		}
+
+		urls, err = h.services.Health.ListByOwnerAndHealth(c.Request.Context(), userId, health == healthBroken)
+	} else if expired == "" {
 		urls, err = h.services.URLs.ListByOwner(c.Request.Context(), userId)
 	} else {
 		var exp bool
@@ -60,7 +87,7 @@ func (h *Handler) listURLs(c *gin.Context) {
 		exp, err = strconv.ParseBool(expired)
 
 		if err != nil {
-			newResponse(c, http.StatusBadRequest, "expired parameter not boolean")
+			newErrorResponse(c, ErrInvalidExpired)
 			return
 		}
 
@@ -68,13 +95,58 @@ func (h *Handler) listURLs(c *gin.Context) {
 	}
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, urls)
 }
 
+// @Summary Search URLs
+// @Tags urls
+// @Description Search URLs owned by user by alias, original URL, tags and title of page ordered by relevance
+// @ID searchURLs
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Success 200 {array} domain.URLSearchResult "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/search [get]
+func (h *Handler) searchURLs(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	query := strings.TrimSpace(c.Query("q"))
+
+	if query == "" {
+		newErrorResponse(c, ErrEmptySearchQuery)
+		return
+	}
+
+	results, err := h.services.URLs.Search(c.Request.Context(), userId, query)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
 // @Summary Create new URL
 // @Tags urls
 // @Description Create new URL for user
@@ -84,30 +156,31 @@ func (h *Handler) listURLs(c *gin.Context) {
 // @Produce json
 // @Param input body domain.URLCreate true "Data for creating URL"
 // @Success 201 {object} domain.URL "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 401 {object} response "Invalid authorization"
-// @Failure 422 {object} response "Invalid request body"
-// @Failure 500 {object} response "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 409 {object} problem "URL already exists or limit reached"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
 // @Router /urls [post]
 func (h *Handler) createURL(c *gin.Context) {
 	var toCreate domain.URLCreate
 
-	if err := c.BindJSON(&toCreate); err != nil {
-		newResponse(c, http.StatusUnprocessableEntity, "invalid request body")
+	if err := c.ShouldBindJSON(&toCreate); err != nil {
+		newBindErrorResponse(c, err)
 		return
 	}
 
 	userIdHex, ok := c.Get("userId")
 
 	if !ok {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
@@ -116,12 +189,7 @@ func (h *Handler) createURL(c *gin.Context) {
 	url, err := h.services.URLs.Create(c.Request.Context(), toCreate)
 
 	if err != nil {
-		if err == repo.ErrURLAlreadyExists || err == service.ErrURLLimit {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
@@ -136,47 +204,38 @@ func (h *Handler) createURL(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} domain.URL "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 401 {object} response "Invalid authorization"
-// @Failure 403 {object} response "Invalid access"
-// @Failure 500 {object} response "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
 // @Router /urls/{alias} [get]
 func (h *Handler) getURL(c *gin.Context) {
 	userIdHex, ok := c.Get("userId")
 
 	if !ok {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	alias := c.Param("alias")
 
 	if alias == "" {
-		newResponse(c, http.StatusBadRequest, "empty alias")
+		newErrorResponse(c, ErrEmptyAlias)
 		return
 	}
 
 	urls, err := h.services.URLs.GetByOwner(c.Request.Context(), alias, userId)
 
 	if err != nil {
-		if err == repo.ErrURLNotFound {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		if err == service.ErrURLForbidden {
-			newResponse(c, http.StatusForbidden, err.Error())
-			return
-		}
-
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
@@ -192,55 +251,303 @@ func (h *Handler) getURL(c *gin.Context) {
 // @Produce json
 // @Param input body domain.URLProlong true "Data for prolonging URL"
 // @Success 200 {object} domain.URL "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 401 {object} response "Invalid authorization"
-// @Failure 403 {object} response "Invalid access"
-// @Failure 422 {object} response "Invalid request body"
-// @Failure 500 {object} response "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
 // @Router /urls/{alias}/prolong [patch]
 func (h *Handler) prolongURL(c *gin.Context) {
 	var toProlong domain.URLProlong
 
-	if err := c.BindJSON(&toProlong); err != nil {
-		newResponse(c, http.StatusUnprocessableEntity, "invalid request body")
+	if err := c.ShouldBindJSON(&toProlong); err != nil {
+		newBindErrorResponse(c, err)
 		return
 	}
 
 	userIdHex, ok := c.Get("userId")
 
 	if !ok {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	alias := c.Param("alias")
 
 	if alias == "" {
-		newResponse(c, http.StatusBadRequest, "empty alias")
+		newErrorResponse(c, ErrEmptyAlias)
 		return
 	}
 
 	url, err := h.services.URLs.Prolong(c.Request.Context(), alias, userId, toProlong)
 
 	if err != nil {
-		if err == repo.ErrURLNotFound {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
+		newErrorResponse(c, err)
+		return
+	}
 
-		if err == service.ErrURLForbidden {
-			newResponse(c, http.StatusForbidden, err.Error())
-			return
-		}
+	c.JSON(http.StatusOK, url)
+}
+
+// @Summary Refresh URL metadata
+// @Tags urls
+// @Description Fetch title, description, favicon and image of original URL again
+// @ID refreshURLMetadata
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Success 200 {object} domain.URL "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
+// @Failure 502 {object} problem "Original URL unavailable"
+// @Router /urls/{alias}/metadata [post]
+func (h *Handler) refreshURLMetadata(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
 
-		newResponse(c, http.StatusInternalServerError, err.Error())
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	alias := c.Param("alias")
+
+	if alias == "" {
+		newErrorResponse(c, ErrEmptyAlias)
+		return
+	}
+
+	url, err := h.services.Metadata.Refresh(c.Request.Context(), alias, userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, url)
+}
+
+// @Summary Set URL redirect rules
+// @Tags urls
+// @Description Replace redirect rules of URL. Rules are checked in order and first matching rule chooses destination,
+// @Description original URL is used if none of rules match
+// @ID setURLRules
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Param input body domain.URLRules true "Redirect rules of URL"
+// @Success 200 {object} domain.URL "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/{alias}/rules [put]
+func (h *Handler) setURLRules(c *gin.Context) {
+	var toSet domain.URLRules
+
+	if err := c.ShouldBindJSON(&toSet); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	alias := c.Param("alias")
+
+	if alias == "" {
+		newErrorResponse(c, ErrEmptyAlias)
+		return
+	}
+
+	url, err := h.services.URLs.SetRules(c.Request.Context(), alias, userId, toSet.Rules)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, url)
+}
+
+// @Summary Set URL variants
+// @Tags urls
+// @Description Replace weighted destinations of split testing. Clicks of previous variants are discarded,
+// @Description empty list of variants disables split testing
+// @ID setURLVariants
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Param input body domain.URLVariants true "Variants of URL"
+// @Success 200 {object} domain.URL "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/{alias}/variants [put]
+func (h *Handler) setURLVariants(c *gin.Context) {
+	var toSet domain.URLVariants
+
+	if err := c.ShouldBindJSON(&toSet); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	alias := c.Param("alias")
+
+	if alias == "" {
+		newErrorResponse(c, ErrEmptyAlias)
+		return
+	}
+
+	url, err := h.services.URLs.SetVariants(c.Request.Context(), alias, userId, toSet)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, url)
+}
+
+// @Summary List URL variant stats
+// @Tags urls
+// @Description List clicks of variants and their share among all redirections to variants
+// @ID listURLVariantStats
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Success 200 {array} domain.VariantStats "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/{alias}/variants [get]
+func (h *Handler) listURLVariantStats(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	alias := c.Param("alias")
+
+	if alias == "" {
+		newErrorResponse(c, ErrEmptyAlias)
+		return
+	}
+
+	stats, err := h.services.URLs.ListVariantStats(c.Request.Context(), alias, userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// @Summary Promote URL variant
+// @Tags urls
+// @Description Make destination of variant original URL and remove all variants
+// @ID promoteURLVariant
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param alias path string true "Alias of URL"
+// @Param name path string true "Name of variant"
+// @Success 200 {object} domain.URL "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/{alias}/variants/{name}/promote [post]
+func (h *Handler) promoteURLVariant(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	alias := c.Param("alias")
+
+	if alias == "" {
+		newErrorResponse(c, ErrEmptyAlias)
+		return
+	}
+
+	url, err := h.services.URLs.PromoteVariant(c.Request.Context(), alias, userId, c.Param("name"))
+
+	if err != nil {
+		newErrorResponse(c, err)
 		return
 	}
 
@@ -255,45 +562,36 @@ func (h *Handler) prolongURL(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 204 {null} nil "Operation finished successfully"
-// @Failure 400 {object} response "Invalid request"
-// @Failure 401 {object} response "Invalid authorization"
-// @Failure 403 {object} response "Invalid access"
-// @Failure 500 {object} response "Server error"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
 // @Router /urls/{alias} [delete]
 func (h *Handler) deleteURL(c *gin.Context) {
 	userIdHex, ok := c.Get("userId")
 
 	if !ok {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, "user not found")
+		newErrorResponse(c, errNoUser)
 		return
 	}
 
 	alias := c.Param("alias")
 
 	if alias == "" {
-		newResponse(c, http.StatusBadRequest, "empty alias")
+		newErrorResponse(c, ErrEmptyAlias)
 		return
 	}
 
 	if err := h.services.URLs.Delete(c.Request.Context(), alias, userId); err != nil {
-		if err == repo.ErrURLNotFound {
-			newResponse(c, http.StatusBadRequest, err.Error())
-			return
-		}
-
-		if err == service.ErrURLForbidden {
-			newResponse(c, http.StatusForbidden, err.Error())
-			return
-		}
-
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
@@ -18,7 +19,7 @@ import (
 )
 
 func TestHandler_listURLs(t *testing.T) {
-	type mockBehaviour func(s *mockService.MockURLs, ownerId primitive.ObjectID)
+	type mockBehaviour func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID)
 
 	userId := primitive.NewObjectID()
 
@@ -49,7 +50,7 @@ func TestHandler_listURLs(t *testing.T) {
 		{
 			name:   "ok",
 			userId: userId,
-			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {
 				s.EXPECT().ListByOwner(context.Background(), ownerId).Return(urls, nil)
 			},
 			statusCode:   200,
@@ -59,7 +60,7 @@ func TestHandler_listURLs(t *testing.T) {
 			name:   "ok with expired=true",
 			userId: userId,
 			query:  "expired=true",
-			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {
 				s.EXPECT().ListByOwnerAndExpiration(context.Background(), ownerId, true).Return(urls, nil)
 			},
 			statusCode:   200,
@@ -69,9 +70,35 @@ func TestHandler_listURLs(t *testing.T) {
 			name:          "error with expired=qwe",
 			userId:        userId,
 			query:         "expired=qwe",
-			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidExpired),
+		},
+		{
+			name:   "ok with health=broken",
+			userId: userId,
+			query:  "health=broken",
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {
+				h.EXPECT().ListByOwnerAndHealth(context.Background(), ownerId, true).Return(urls, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(urls),
+		},
+		{
+			name:          "error with health=qwe",
+			userId:        userId,
+			query:         "health=qwe",
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidHealth),
+		},
+		{
+			name:          "error with expired and health",
+			userId:        userId,
+			query:         "expired=true&health=broken",
+			mockBehaviour: func(s *mockService.MockURLs, h *mockService.MockHealth, ownerId primitive.ObjectID) {},
 			statusCode:    400,
-			responseBody:  `{"message":"expired parameter not boolean"}`,
+			responseBody:  problemBody(ErrFiltersCombined),
 		},
 	}
 
@@ -82,9 +109,10 @@ func TestHandler_listURLs(t *testing.T) {
 			defer c.Finish()
 
 			urlsService := mockService.NewMockURLs(c)
-			tt.mockBehaviour(urlsService, tt.userId)
+			healthService := mockService.NewMockHealth(c)
+			tt.mockBehaviour(urlsService, healthService, tt.userId)
 
-			services := &service.Services{URLs: urlsService}
+			services := &service.Services{URLs: urlsService, Health: healthService}
 			handler := &Handler{
 				services:     services,
 				tokenManager: nil,
@@ -92,7 +120,7 @@ func TestHandler_listURLs(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.GET("/urls", func(c *gin.Context) {
+			r.GET("/urls", errorHandler, func(c *gin.Context) {
 				c.Set(userCtx, userId.Hex())
 			}, handler.listURLs)
 
@@ -110,6 +138,93 @@ func TestHandler_listURLs(t *testing.T) {
 	}
 }
 
+func TestHandler_searchURLs(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	results := []domain.URLSearchResult{
+		{
+			URL: domain.URL{
+				Alias:     "alias",
+				Original:  "https://docs.example.com",
+				CreatedAt: time.Now(),
+				ExpiredAt: time.Now(),
+				Owner:     userId,
+			},
+			Score: 1.5,
+			Highlights: []domain.Highlight{
+				{Field: "original", Value: "https://docs.example.com", Matches: []domain.TextRange{{Start: 8, End: 12}}},
+			},
+		},
+	}
+
+	setResponseBody := func(results []domain.URLSearchResult) string {
+		body, _ := json.Marshal(results)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:  "ok",
+			query: "q=docs",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().Search(context.Background(), ownerId, "docs").Return(results, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(results),
+		},
+		{
+			name:          "empty query",
+			query:         "q=+",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrEmptySearchQuery),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, userId)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: nil,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/urls/search", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.searchURLs)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/urls/search?"+tt.query, bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
 func TestHandler_createURL(t *testing.T) {
 	type mockBehaviour func(s *mockService.MockURLs, url domain.URLCreate)
 
@@ -150,8 +265,8 @@ func TestHandler_createURL(t *testing.T) {
 			name:          "invalid request body",
 			requestBody:   `{"duration": 60}`,
 			mockBehaviour: func(s *mockService.MockURLs, url domain.URLCreate) {},
-			statusCode:    400,
-			responseBody:  `{"message":"invalid request body"}`,
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "original", Rule: "required"}),
 		},
 		{
 			name:        "url already exists",
@@ -170,8 +285,8 @@ func TestHandler_createURL(t *testing.T) {
 
 				s.EXPECT().Create(context.Background(), toCreate).Return(domain.URL{}, repo.ErrURLAlreadyExists)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url already exists"}`,
+			statusCode:   409,
+			responseBody: problemBody(repo.ErrURLAlreadyExists),
 		},
 		{
 			name:        "url limit",
@@ -190,8 +305,8 @@ func TestHandler_createURL(t *testing.T) {
 
 				s.EXPECT().Create(context.Background(), toCreate).Return(domain.URL{}, service.ErrURLLimit)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"cannot create more urls"}`,
+			statusCode:   409,
+			responseBody: problemBody(service.ErrURLLimit),
 		},
 	}
 
@@ -209,7 +324,7 @@ func TestHandler_createURL(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.POST("/urls", func(c *gin.Context) {
+			r.POST("/urls", errorHandler, func(c *gin.Context) {
 				c.Set(userCtx, userId.Hex())
 			}, handler.createURL)
 
@@ -275,8 +390,8 @@ func TestHandler_getURL(t *testing.T) {
 			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID) {
 				s.EXPECT().GetByOwner(context.Background(), alias, ownerId).Return(domain.URL{}, repo.ErrURLNotFound)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url doesn't exists"}`,
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
 		},
 		{
 			name:   "url forbidden",
@@ -286,7 +401,7 @@ func TestHandler_getURL(t *testing.T) {
 				s.EXPECT().GetByOwner(context.Background(), alias, ownerId).Return(domain.URL{}, service.ErrURLForbidden)
 			},
 			statusCode:   403,
-			responseBody: `{"message":"url cannot be accessed"}`,
+			responseBody: problemBody(service.ErrURLForbidden),
 		},
 	}
 
@@ -307,7 +422,7 @@ func TestHandler_getURL(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.GET("/urls/:alias", func(c *gin.Context) {
+			r.GET("/urls/:alias", errorHandler, func(c *gin.Context) {
 				c.Set(userCtx, userId.Hex())
 			}, handler.getURL)
 
@@ -372,8 +487,8 @@ func TestHandler_prolongURL(t *testing.T) {
 			userId:        userId,
 			requestBody:   ``,
 			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, url domain.URLProlong) {},
-			statusCode:    400,
-			responseBody:  `{"message":"invalid request body"}`,
+			statusCode:    422,
+			responseBody:  problemBody(ErrInvalidBody),
 		},
 		{
 			name:        "responseURL not found",
@@ -384,8 +499,8 @@ func TestHandler_prolongURL(t *testing.T) {
 			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, url domain.URLProlong) {
 				s.EXPECT().Prolong(context.Background(), alias, ownerId, url).Return(domain.URL{}, repo.ErrURLNotFound)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url doesn't exists"}`,
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
 		},
 		{
 			name:        "responseURL forbidden",
@@ -397,7 +512,7 @@ func TestHandler_prolongURL(t *testing.T) {
 				s.EXPECT().Prolong(context.Background(), alias, ownerId, url).Return(domain.URL{}, service.ErrURLForbidden)
 			},
 			statusCode:   403,
-			responseBody: `{"message":"url cannot be accessed"}`,
+			responseBody: problemBody(service.ErrURLForbidden),
 		},
 	}
 
@@ -418,7 +533,7 @@ func TestHandler_prolongURL(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.PATCH("/urls/:alias/prolong", func(c *gin.Context) {
+			r.PATCH("/urls/:alias/prolong", errorHandler, func(c *gin.Context) {
 				c.Set(userCtx, userId.Hex())
 			}, handler.prolongURL)
 
@@ -436,6 +551,427 @@ func TestHandler_prolongURL(t *testing.T) {
 	}
 }
 
+func TestHandler_setURLRules(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule)
+
+	userId := primitive.NewObjectID()
+
+	rules := []domain.RedirectRule{
+		{OS: "ios", Destination: "https://apps.apple.com"},
+	}
+
+	responseURL := domain.URL{
+		Alias:     "alias",
+		Original:  "https://google.com",
+		CreatedAt: time.Now(),
+		ExpiredAt: time.Now(),
+		Owner:     userId,
+		Rules:     rules,
+	}
+
+	setResponseBody := func(urls domain.URL) string {
+		body, _ := json.Marshal(urls)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		requestRules  []domain.RedirectRule
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:         "ok",
+			requestBody:  `{"rules": [{"os": "ios", "destination": "https://apps.apple.com"}]}`,
+			requestRules: rules,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule) {
+				s.EXPECT().SetRules(context.Background(), alias, ownerId, rules).Return(responseURL, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(responseURL),
+		},
+		{
+			name:          "invalid os",
+			requestBody:   `{"rules": [{"os": "symbian", "destination": "https://apps.apple.com"}]}`,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule) {},
+			statusCode:    422,
+			responseBody: problemBody(ErrValidation,
+				fieldError{Field: "rules[0].os", Rule: "oneof", Param: "ios android windows macos linux other"}),
+		},
+		{
+			name:          "invalid destination",
+			requestBody:   `{"rules": [{"country": "KZ", "destination": "google"}]}`,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "rules[0].destination", Rule: "url"}),
+		},
+		{
+			name:         "url not found",
+			requestBody:  `{"rules": [{"os": "ios", "destination": "https://apps.apple.com"}]}`,
+			requestRules: rules,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule) {
+				s.EXPECT().SetRules(context.Background(), alias, ownerId, rules).Return(domain.URL{}, repo.ErrURLNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
+		},
+		{
+			name:         "url forbidden",
+			requestBody:  `{"rules": [{"os": "ios", "destination": "https://apps.apple.com"}]}`,
+			requestRules: rules,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, rules []domain.RedirectRule) {
+				s.EXPECT().SetRules(context.Background(), alias, ownerId, rules).Return(domain.URL{}, service.ErrURLForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrURLForbidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, "alias", userId, tt.requestRules)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: nil,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.PUT("/urls/:alias/rules", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.setURLRules)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/urls/alias/rules", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_setURLVariants(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, toSet domain.URLVariants)
+
+	userId := primitive.NewObjectID()
+
+	toSet := domain.URLVariants{
+		Variants: []domain.VariantCreate{
+			{Name: "a", Destination: "https://google.com/a", Weight: 70},
+			{Name: "b", Destination: "https://google.com/b", Weight: 30},
+		},
+		Sticky: true,
+	}
+
+	responseURL := domain.URL{
+		Alias:          "alias",
+		Original:       "https://google.com",
+		CreatedAt:      time.Now(),
+		ExpiredAt:      time.Now(),
+		Owner:          userId,
+		Variants:       domain.NewVariants(toSet.Variants),
+		StickyVariants: true,
+	}
+
+	setResponseBody := func(urls domain.URL) string {
+		body, _ := json.Marshal(urls)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		requestSet    domain.URLVariants
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"variants": [{"name": "a", "destination": "https://google.com/a", "weight": 70}, {"name": "b", "destination": "https://google.com/b", "weight": 30}], "sticky": true}`,
+			requestSet:  toSet,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, toSet domain.URLVariants) {
+				s.EXPECT().SetVariants(context.Background(), alias, ownerId, toSet).Return(responseURL, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(responseURL),
+		},
+		{
+			name:          "duplicate names",
+			requestBody:   `{"variants": [{"name": "a", "destination": "https://google.com/a", "weight": 70}, {"name": "a", "destination": "https://google.com/b", "weight": 30}]}`,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, toSet domain.URLVariants) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "variants", Rule: "unique", Param: "Name"}),
+		},
+		{
+			name:          "zero weight",
+			requestBody:   `{"variants": [{"name": "a", "destination": "https://google.com/a", "weight": 0}]}`,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, toSet domain.URLVariants) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "variants[0].weight", Rule: "required"}),
+		},
+		{
+			name:        "url forbidden",
+			requestBody: `{"variants": [{"name": "a", "destination": "https://google.com/a", "weight": 70}, {"name": "b", "destination": "https://google.com/b", "weight": 30}], "sticky": true}`,
+			requestSet:  toSet,
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, toSet domain.URLVariants) {
+				s.EXPECT().SetVariants(context.Background(), alias, ownerId, toSet).Return(domain.URL{}, service.ErrURLForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrURLForbidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, "alias", userId, tt.requestSet)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: nil,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.PUT("/urls/:alias/variants", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.setURLVariants)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PUT", "/urls/alias/variants", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_promoteURLVariant(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, name string)
+
+	userId := primitive.NewObjectID()
+
+	responseURL := domain.URL{
+		Alias:     "alias",
+		Original:  "https://google.com/b",
+		CreatedAt: time.Now(),
+		ExpiredAt: time.Now(),
+		Owner:     userId,
+	}
+
+	setResponseBody := func(urls domain.URL) string {
+		body, _ := json.Marshal(urls)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		variant       string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:    "ok",
+			variant: "b",
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, name string) {
+				s.EXPECT().PromoteVariant(context.Background(), alias, ownerId, name).Return(responseURL, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(responseURL),
+		},
+		{
+			name:    "variant not found",
+			variant: "c",
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, name string) {
+				s.EXPECT().PromoteVariant(context.Background(), alias, ownerId, name).Return(domain.URL{}, service.ErrVariantNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(service.ErrVariantNotFound),
+		},
+		{
+			name:    "url forbidden",
+			variant: "b",
+			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID, name string) {
+				s.EXPECT().PromoteVariant(context.Background(), alias, ownerId, name).Return(domain.URL{}, service.ErrURLForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrURLForbidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, "alias", userId, tt.variant)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: nil,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/urls/:alias/variants/:name/promote", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.promoteURLVariant)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/urls/alias/variants/"+tt.variant+"/promote", bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_refreshURLMetadata(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockMetadata, alias string, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	responseURL := domain.URL{
+		Alias:     "alias",
+		Original:  "https://google.com",
+		CreatedAt: time.Now(),
+		ExpiredAt: time.Now(),
+		Owner:     userId,
+		Metadata: domain.URLMetadata{
+			Title:     "Google",
+			FetchedAt: time.Now(),
+		},
+	}
+
+	setResponseBody := func(urls domain.URL) string {
+		body, _ := json.Marshal(urls)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		alias         string
+		userId        primitive.ObjectID
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:   "ok",
+			alias:  "alias",
+			userId: userId,
+			mockBehaviour: func(s *mockService.MockMetadata, alias string, ownerId primitive.ObjectID) {
+				s.EXPECT().Refresh(context.Background(), alias, ownerId).Return(responseURL, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(responseURL),
+		},
+		{
+			name:   "url not found",
+			alias:  "alias",
+			userId: userId,
+			mockBehaviour: func(s *mockService.MockMetadata, alias string, ownerId primitive.ObjectID) {
+				s.EXPECT().Refresh(context.Background(), alias, ownerId).Return(domain.URL{}, repo.ErrURLNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
+		},
+		{
+			name:   "url forbidden",
+			alias:  "alias",
+			userId: userId,
+			mockBehaviour: func(s *mockService.MockMetadata, alias string, ownerId primitive.ObjectID) {
+				s.EXPECT().Refresh(context.Background(), alias, ownerId).Return(domain.URL{}, service.ErrURLForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrURLForbidden),
+		},
+		{
+			name:   "original unavailable",
+			alias:  "alias",
+			userId: userId,
+			mockBehaviour: func(s *mockService.MockMetadata, alias string, ownerId primitive.ObjectID) {
+				s.EXPECT().Refresh(context.Background(), alias, ownerId).Return(domain.URL{},
+					fmt.Errorf("%w: timeout", service.ErrURLMetadataUnavailable))
+			},
+			statusCode:   502,
+			responseBody: problemBody(service.ErrURLMetadataUnavailable),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			metadataService := mockService.NewMockMetadata(c)
+			tt.mockBehaviour(metadataService, "alias", tt.userId)
+
+			services := &service.Services{Metadata: metadataService}
+			handler := &Handler{
+				services:     services,
+				tokenManager: nil,
+			}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/urls/:alias/metadata", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.refreshURLMetadata)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/urls/alias/metadata", bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
 func TestHandler_deleteURL(t *testing.T) {
 	type mockBehaviour func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID)
 
@@ -466,8 +1002,8 @@ func TestHandler_deleteURL(t *testing.T) {
 			mockBehaviour: func(s *mockService.MockURLs, alias string, ownerId primitive.ObjectID) {
 				s.EXPECT().Delete(context.Background(), alias, ownerId).Return(repo.ErrURLNotFound)
 			},
-			statusCode:   400,
-			responseBody: `{"message":"url doesn't exists"}`,
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrURLNotFound),
 		},
 		{
 			name:   "url forbidden",
@@ -477,7 +1013,7 @@ func TestHandler_deleteURL(t *testing.T) {
 				s.EXPECT().Delete(context.Background(), alias, ownerId).Return(service.ErrURLForbidden)
 			},
 			statusCode:   403,
-			responseBody: `{"message":"url cannot be accessed"}`,
+			responseBody: problemBody(service.ErrURLForbidden),
 		},
 	}
 
@@ -498,7 +1034,7 @@ func TestHandler_deleteURL(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.DELETE("/urls/:alias", func(c *gin.Context) {
+			r.DELETE("/urls/:alias", errorHandler, func(c *gin.Context) {
 				c.Set(userCtx, userId.Hex())
 			}, handler.deleteURL)
 
@@ -0,0 +1,183 @@
+package v1
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/transfer"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+)
+
+// Max size of imported file
+const maxImportBytes = 10 << 20
+
+// @Summary Export URLs
+// @Tags urls
+// @Description Download all URLs owned by user with their metadata, rules and variants
+// @ID exportURLs
+// @Security UsersAuth
+// @Accept json
+// @Produce json,text/csv,application/x-ndjson
+// @Param format query string false "Format of file, json by default" Enums(csv, json, ndjson)
+// @Success 200 {array} domain.URLRecord "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/export [get]
+func (h *Handler) exportURLs(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	format := c.DefaultQuery("format", domain.FormatJSON)
+
+	if format != domain.FormatCSV && format != domain.FormatJSON && format != domain.FormatNDJSON {
+		newErrorResponse(c, ErrInvalidExportFormat)
+		return
+	}
+
+	urls, err := h.services.URLs.ListByOwner(c.Request.Context(), userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	encoder, err := transfer.NewEncoder(c.Writer, format)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Content-Type", transfer.ContentType(format))
+	c.Header("Content-Disposition", `attachment; filename="urls.`+format+`"`)
+	c.Status(http.StatusOK)
+
+	for _, url := range urls {
+		if err := encoder.Encode(domain.NewURLRecord(url)); err != nil {
+			// Status is already sent, so client notices only truncated file
+			logging.FromContext(c.Request.Context()).WithError(err).Warn("Could not export urls")
+			return
+		}
+	}
+
+	if err := encoder.Close(); err != nil {
+		logging.FromContext(c.Request.Context()).WithError(err).Warn("Could not export urls")
+	}
+}
+
+// @Summary Import URLs
+// @Tags urls
+// @Description Create URLs of user from exported file or bookmarks of browser, every row is imported on its own.
+// @Description Row conflicts with URL having its alias or, if alias is empty, with URL of user having its original URL
+// @ID importURLs
+// @Security UsersAuth
+// @Accept json,text/csv,application/x-ndjson,text/html
+// @Produce json
+// @Param format query string false "Format of file, json by default, html is Netscape bookmark file" Enums(csv, json, ndjson, html)
+// @Param conflict query string false "Resolving of conflicts, skip by default" Enums(skip, overwrite, rename)
+// @Param dryRun query bool false "Check rows without changing any URL"
+// @Param input body []domain.URLRecord true "Imported file"
+// @Success 200 {object} domain.URLImportResult "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 413 {object} problem "File too large"
+// @Failure 422 {object} problem "Malformed file"
+// @Failure 500 {object} problem "Server error"
+// @Router /urls/import [post]
+func (h *Handler) importURLs(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	options, format, err := parseImportQuery(c)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(c.Request.Body, maxImportBytes+1))
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	if len(data) > maxImportBytes {
+		newErrorResponse(c, ErrImportTooLarge)
+		return
+	}
+
+	records, err := transfer.Decode(bytes.NewReader(data), format)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	result, err := h.services.URLs.Import(c.Request.Context(), userId, records, options)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func parseImportQuery(c *gin.Context) (domain.URLImportOptions, string, error) {
+	format := c.DefaultQuery("format", domain.FormatJSON)
+
+	switch format {
+	case domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON, domain.FormatBookmarks:
+	default:
+		return domain.URLImportOptions{}, "", ErrInvalidImportFormat
+	}
+
+	options := domain.URLImportOptions{
+		OnConflict: c.DefaultQuery("conflict", domain.ConflictSkip),
+	}
+
+	switch options.OnConflict {
+	case domain.ConflictSkip, domain.ConflictOverwrite, domain.ConflictRename:
+	default:
+		return domain.URLImportOptions{}, "", ErrInvalidConflict
+	}
+
+	if dryRun := c.Query("dryRun"); dryRun != "" {
+		var err error
+
+		if options.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			return domain.URLImportOptions{}, "", ErrInvalidDryRun
+		}
+	}
+
+	return options, format, nil
+}
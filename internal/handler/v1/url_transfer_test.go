@@ -0,0 +1,231 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandler_exportURLs(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	urls := []domain.URL{
+		{
+			Alias:     "qwerty",
+			Original:  "https://google.com/",
+			CreatedAt: time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC),
+			ExpiredAt: time.Date(2021, 6, 9, 9, 29, 18, 0, time.UTC),
+			Owner:     userId,
+			Tags:      []string{"search"},
+			Metadata:  domain.URLMetadata{Title: "Google"},
+			Clicks:    10,
+		},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		contentType   string
+		responseBody  string
+	}{
+		{
+			name: "json by default",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().ListByOwner(context.Background(), ownerId).Return(urls, nil)
+			},
+			statusCode:  200,
+			contentType: "application/json; charset=utf-8",
+			responseBody: `[{"alias":"qwerty","original":"https://google.com/","createdAt":"2021-05-09T09:29:18Z",` +
+				`"expiredAt":"2021-06-09T09:29:18Z","tags":["search"],"forwardQuery":false,"forwardPath":false,` +
+				`"stickyVariants":false,"title":"Google","clicks":10}]` + "\n",
+		},
+		{
+			name:  "csv",
+			query: "?format=csv",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().ListByOwner(context.Background(), ownerId).Return(urls, nil)
+			},
+			statusCode:  200,
+			contentType: "text/csv; charset=utf-8",
+			responseBody: "alias,original,createdAt,expiredAt,tags,forwardQuery,forwardPath,rules,variants," +
+				"stickyVariants,title,description,favicon,image,clicks\n" +
+				"qwerty,https://google.com/,2021-05-09T09:29:18Z,2021-06-09T09:29:18Z,search,false,false,,," +
+				"false,Google,,,,10\n",
+		},
+		{
+			name:  "empty ndjson",
+			query: "?format=ndjson",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().ListByOwner(context.Background(), ownerId).Return([]domain.URL{}, nil)
+			},
+			statusCode:   200,
+			contentType:  "application/x-ndjson",
+			responseBody: "",
+		},
+		{
+			name:          "bookmarks are not exported",
+			query:         "?format=html",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			contentType:   problemContentType,
+			responseBody:  problemBody(ErrInvalidExportFormat),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, userId)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/urls/export", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.exportURLs)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/urls/export"+tt.query, nil)
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_importURLs(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockURLs, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	result := domain.URLImportResult{
+		DryRun:  true,
+		Created: 1,
+		Rows:    []domain.URLImportRow{{Row: 1, Alias: "qwerty", Status: domain.ImportCreated}},
+	}
+
+	tests := []struct {
+		name          string
+		query         string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			query:       "?format=csv&conflict=rename&dryRun=true",
+			requestBody: "alias,original\nqwerty,https://google.com/\n",
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().Import(context.Background(), ownerId, []domain.URLRecord{
+					{Alias: "qwerty", Original: "https://google.com/"},
+				}, domain.URLImportOptions{DryRun: true, OnConflict: domain.ConflictRename}).Return(result, nil)
+			},
+			statusCode: 200,
+			responseBody: `{"dryRun":true,"created":1,"overwritten":0,"skipped":0,"failed":0,` +
+				`"rows":[{"row":1,"alias":"qwerty","status":"created"}]}`,
+		},
+		{
+			name:        "bookmarks",
+			query:       "?format=html",
+			requestBody: `<DL><p><DT><A HREF="https://google.com/">Google</A></DL><p>`,
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {
+				s.EXPECT().Import(context.Background(), ownerId, []domain.URLRecord{
+					{Original: "https://google.com/", Title: "Google"},
+				}, domain.URLImportOptions{OnConflict: domain.ConflictSkip}).Return(result, nil)
+			},
+			statusCode: 200,
+			responseBody: `{"dryRun":true,"created":1,"overwritten":0,"skipped":0,"failed":0,` +
+				`"rows":[{"row":1,"alias":"qwerty","status":"created"}]}`,
+		},
+		{
+			name:          "invalid conflict",
+			query:         "?conflict=merge",
+			requestBody:   `[]`,
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidConflict),
+		},
+		{
+			name:          "invalid dry run",
+			query:         "?dryRun=maybe",
+			requestBody:   `[]`,
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidDryRun),
+		},
+		{
+			name:          "malformed file",
+			query:         "?format=ndjson",
+			requestBody:   `{"original":`,
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    422,
+			responseBody: problemBody(domain.NewError(domain.KindValidation, "malformed_import",
+				"malformed row 1: unexpected EOF")),
+		},
+		{
+			name:          "too large file",
+			requestBody:   `[` + strings.Repeat(" ", maxImportBytes) + `]`,
+			mockBehaviour: func(s *mockService.MockURLs, ownerId primitive.ObjectID) {},
+			statusCode:    413,
+			responseBody:  problemBody(ErrImportTooLarge),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			urlsService := mockService.NewMockURLs(c)
+			tt.mockBehaviour(urlsService, userId)
+
+			services := &service.Services{URLs: urlsService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/urls/import", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.importURLs)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/urls/import"+tt.query, bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
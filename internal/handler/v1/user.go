@@ -2,6 +2,8 @@ package v1
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http"
 )
 
@@ -9,6 +11,14 @@ func (h *Handler) initUsersRoutes(api *gin.RouterGroup) {
 	users := api.Group("/users")
 	{
 		users.GET("", h.listUsers)
+
+		me := users.Group("/me", h.userIdentity)
+		{
+			me.GET("", h.getMe)
+			me.PATCH("", h.updateMe)
+			me.POST("/password", h.changePassword)
+			me.DELETE("", h.deleteMe)
+		}
 	}
 }
 
@@ -19,15 +29,189 @@ func (h *Handler) initUsersRoutes(api *gin.RouterGroup) {
 // @Accept json
 // @Produce json
 // @Success 200 {array} domain.User "Operation finished successfully"
-// @Failure 500 {object} response "Server error"
+// @Failure 500 {object} problem "Server error"
 // @Router /users [get]
 func (h *Handler) listUsers(c *gin.Context) {
 	users, err := h.services.Users.List(c.Request.Context())
 
 	if err != nil {
-		newResponse(c, http.StatusInternalServerError, err.Error())
+		newErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, users)
 }
+
+// @Summary Get me
+// @Tags users
+// @Description Get account of user
+// @ID getMe
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Success 200 {object} domain.User "Operation finished successfully"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 404 {object} problem "User not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /users/me [get]
+func (h *Handler) getMe(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	user, err := h.services.Users.Get(c.Request.Context(), userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Update me
+// @Tags users
+// @Description Change name of user at once, new email is applied after it is verified by token sent to it
+// @ID updateMe
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param input body domain.UserUpdate true "Data for updating user"
+// @Success 200 {object} domain.User "Operation finished successfully"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 409 {object} problem "Email is taken by another user"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /users/me [patch]
+func (h *Handler) updateMe(c *gin.Context) {
+	var toUpdate domain.UserUpdate
+
+	if err := c.ShouldBindJSON(&toUpdate); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	user, err := h.services.Users.Update(c.Request.Context(), userId, toUpdate)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// @Summary Change password
+// @Tags users
+// @Description Change password of user proven by current one, pending password resets are revoked
+// @ID changePassword
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param input body domain.PasswordChange true "Current and new passwords"
+// @Success 204 {null} nil "Operation finished successfully"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Current password is wrong"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /users/me/password [post]
+func (h *Handler) changePassword(c *gin.Context) {
+	var toChange domain.PasswordChange
+
+	if err := c.ShouldBindJSON(&toChange); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	if err := h.services.Users.ChangePassword(c.Request.Context(), userId, toChange); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary Delete me
+// @Tags users
+// @Description Delete account of user proven by password with webhooks and tokens. URLs are deleted
+// @Description or transferred to successor as user chooses, according to policy of server by default
+// @ID deleteMe
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param input body domain.UserDelete true "Current password and what is done with URLs"
+// @Success 204 {null} nil "Operation finished successfully"
+// @Failure 400 {object} problem "URLs cannot be transferred without successor"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Password is wrong"
+// @Failure 409 {object} problem "User receives URLs of deleted users"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /users/me [delete]
+func (h *Handler) deleteMe(c *gin.Context) {
+	var toDelete domain.UserDelete
+
+	if err := c.ShouldBindJSON(&toDelete); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	if err := h.services.Users.Delete(c.Request.Context(), userId, toDelete); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
@@ -8,10 +8,12 @@ import (
 	"github.com/go-playground/assert/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/internal/service"
 	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 )
@@ -69,7 +71,7 @@ func TestHandler_listUsers(t *testing.T) {
 
 			// Init Endpoint
 			r := gin.New()
-			r.GET("/users", handler.listUsers)
+			r.GET("/users", errorHandler, handler.listUsers)
 
 			// Create Request
 			w := httptest.NewRecorder()
@@ -84,3 +86,328 @@ func TestHandler_listUsers(t *testing.T) {
 		})
 	}
 }
+
+func TestHandler_getMe(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockUsers, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	user := domain.User{
+		ID:       userId,
+		Name:     "Azamat",
+		Email:    "qweqweqwe@gmail.com",
+		Password: "hash",
+		Verified: true,
+	}
+
+	body, _ := json.Marshal(user)
+
+	tests := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name: "ok",
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId).Return(user, nil)
+			},
+			statusCode:   200,
+			responseBody: string(body),
+		},
+		{
+			name: "user not found",
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Get(context.Background(), userId).Return(domain.User{}, repo.ErrUserNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrUserNotFound),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			usersService := mockService.NewMockUsers(c)
+			tt.mockBehaviour(usersService, userId)
+
+			services := &service.Services{Users: usersService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/users/me", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.getMe)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/users/me", bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+			assert.Equal(t, false, strings.Contains(w.Body.String(), "hash"))
+		})
+	}
+}
+
+func TestHandler_updateMe(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockUsers, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	user := domain.User{
+		ID:    userId,
+		Name:  "Sirius",
+		Email: "qweqweqwe@gmail.com",
+	}
+
+	body, _ := json.Marshal(user)
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"name": "Sirius", "email": "sirius@gmail.com"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Update(context.Background(), userId, domain.UserUpdate{
+					Name:  "Sirius",
+					Email: "sirius@gmail.com",
+				}).Return(user, nil)
+			},
+			statusCode:   200,
+			responseBody: string(body),
+		},
+		{
+			name:          "invalid email",
+			requestBody:   `{"email": "sirius"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "email", Rule: "email"}),
+		},
+		{
+			name:        "email taken",
+			requestBody: `{"email": "sirius@gmail.com"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Update(context.Background(), userId, gomock.Any()).Return(domain.User{}, repo.ErrUserAlreadyExists)
+			},
+			statusCode:   409,
+			responseBody: problemBody(repo.ErrUserAlreadyExists),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			usersService := mockService.NewMockUsers(c)
+			tt.mockBehaviour(usersService, userId)
+
+			services := &service.Services{Users: usersService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.PATCH("/users/me", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.updateMe)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("PATCH", "/users/me", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_changePassword(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockUsers, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"password": "qweqweqwe", "newPassword": "asdasdasd"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().ChangePassword(context.Background(), userId, domain.PasswordChange{
+					Password:    "qweqweqwe",
+					NewPassword: "asdasdasd",
+				}).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: ``,
+		},
+		{
+			name:          "short new password",
+			requestBody:   `{"password": "qweqweqwe", "newPassword": "asd"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "newPassword", Rule: "min", Param: "8"}),
+		},
+		{
+			name:        "wrong password",
+			requestBody: `{"password": "qweqweqwe", "newPassword": "asdasdasd"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().ChangePassword(context.Background(), userId, gomock.Any()).Return(service.ErrWrongPassword)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrWrongPassword),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			usersService := mockService.NewMockUsers(c)
+			tt.mockBehaviour(usersService, userId)
+
+			services := &service.Services{Users: usersService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/users/me/password", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.changePassword)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/users/me/password", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_deleteMe(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockUsers, userId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		requestBody   string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"password": "qweqweqwe"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), userId, domain.UserDelete{Password: "qweqweqwe"}).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: ``,
+		},
+		{
+			name:        "ok transfer",
+			requestBody: `{"password": "qweqweqwe", "urls": "transfer"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), userId,
+					domain.UserDelete{Password: "qweqweqwe", URLs: domain.UserURLsTransfer}).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: ``,
+		},
+		{
+			name:          "unknown urls policy",
+			requestBody:   `{"password": "qweqweqwe", "urls": "archive"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "urls", Rule: "oneof", Param: "delete transfer"}),
+		},
+		{
+			name:        "no successor",
+			requestBody: `{"password": "qweqweqwe", "urls": "transfer"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), userId, gomock.Any()).Return(service.ErrNoSuccessor)
+			},
+			statusCode:   400,
+			responseBody: problemBody(service.ErrNoSuccessor),
+		},
+		{
+			name:          "no password",
+			requestBody:   `{}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "password", Rule: "required"}),
+		},
+		{
+			name:        "successor deletion",
+			requestBody: `{"password": "qweqweqwe"}`,
+			mockBehaviour: func(s *mockService.MockUsers, userId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), userId, gomock.Any()).Return(service.ErrSuccessorDeletion)
+			},
+			statusCode:   409,
+			responseBody: problemBody(service.ErrSuccessorDeletion),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			usersService := mockService.NewMockUsers(c)
+			tt.mockBehaviour(usersService, userId)
+
+			services := &service.Services{Users: usersService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.DELETE("/users/me", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.deleteMe)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/users/me", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
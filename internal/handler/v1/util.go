@@ -1,12 +1,19 @@
 package v1
 
-import "github.com/gin-gonic/gin"
+import (
+	"github.com/gin-gonic/gin"
+	"strings"
+)
 
-type response struct {
-	// Success or error message
-	Message string `json:"message" example:"some text"`
-} // @name Response
+// rawParam returns escaped value of catch-all parameter without leading slash.
+// Unlike c.Param encoded slashes are kept as is
+func rawParam(c *gin.Context, name string) string {
+	prefix := strings.TrimSuffix(c.FullPath(), "*"+name)
+	escaped := c.Request.URL.EscapedPath()
 
-func newResponse(c *gin.Context, statusCode int, message string) {
-	c.AbortWithStatusJSON(statusCode, response{message})
+	if strings.HasPrefix(escaped, prefix) {
+		return escaped[len(prefix):]
+	}
+
+	return strings.TrimPrefix(c.Param(name), "/")
 }
@@ -0,0 +1,194 @@
+package v1
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+)
+
+func (h *Handler) initWebhooksRoutes(api *gin.RouterGroup) {
+	webhooks := api.Group("/webhooks", h.userIdentity)
+	{
+		webhooks.GET("", h.listWebhooks)
+		webhooks.POST("", h.createWebhook)
+		webhooks.DELETE("/:id", h.deleteWebhook)
+		webhooks.GET("/:id/deliveries", h.listWebhookDeliveries)
+	}
+}
+
+// @Summary List webhooks
+// @Tags webhooks
+// @Description List webhooks owned by user
+// @ID listWebhooks
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.Webhook "Operation finished successfully"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 500 {object} problem "Server error"
+// @Router /webhooks [get]
+func (h *Handler) listWebhooks(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	webhooks, err := h.services.Webhooks.ListByOwner(c.Request.Context(), userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+// @Summary Create webhook
+// @Tags webhooks
+// @Description Subscribe to events of URLs lifecycle. Payloads are signed with HMAC-SHA256 of
+// @Description "<X-Webhook-Timestamp>.<body>" using secret and sent in X-Webhook-Signature header
+// @ID createWebhook
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param input body domain.WebhookCreate true "Data for creating webhook"
+// @Success 201 {object} domain.Webhook "Operation finished successfully"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 422 {object} problem "Invalid request body"
+// @Failure 500 {object} problem "Server error"
+// @Router /webhooks [post]
+func (h *Handler) createWebhook(c *gin.Context) {
+	var toCreate domain.WebhookCreate
+
+	if err := c.ShouldBindJSON(&toCreate); err != nil {
+		newBindErrorResponse(c, err)
+		return
+	}
+
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	toCreate.Owner = userId
+
+	webhook, err := h.services.Webhooks.Create(c.Request.Context(), toCreate)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, webhook)
+}
+
+// @Summary Delete webhook
+// @Tags webhooks
+// @Description Delete webhook with its delivery log
+// @ID deleteWebhook
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Id of webhook"
+// @Success 204 {null} nil "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /webhooks/{id} [delete]
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+
+	if err != nil {
+		newErrorResponse(c, ErrInvalidID)
+		return
+	}
+
+	if err := h.services.Webhooks.Delete(c.Request.Context(), id, userId); err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// @Summary List webhook deliveries
+// @Tags webhooks
+// @Description List latest deliveries of webhook
+// @ID listWebhookDeliveries
+// @Security UsersAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "Id of webhook"
+// @Success 200 {array} domain.WebhookDelivery "Operation finished successfully"
+// @Failure 400 {object} problem "Invalid request"
+// @Failure 401 {object} problem "Invalid authorization"
+// @Failure 403 {object} problem "Invalid access"
+// @Failure 404 {object} problem "Not found"
+// @Failure 500 {object} problem "Server error"
+// @Router /webhooks/{id}/deliveries [get]
+func (h *Handler) listWebhookDeliveries(c *gin.Context) {
+	userIdHex, ok := c.Get("userId")
+
+	if !ok {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	userId, err := primitive.ObjectIDFromHex(userIdHex.(string))
+
+	if err != nil {
+		newErrorResponse(c, errNoUser)
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+
+	if err != nil {
+		newErrorResponse(c, ErrInvalidID)
+		return
+	}
+
+	deliveries, err := h.services.Webhooks.ListDeliveries(c.Request.Context(), id, userId)
+
+	if err != nil {
+		newErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
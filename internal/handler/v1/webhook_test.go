@@ -0,0 +1,260 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/assert/v2"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/internal/service"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler_createWebhook(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockWebhooks, webhook domain.WebhookCreate)
+
+	userId := primitive.NewObjectID()
+
+	tests := []struct {
+		name           string
+		requestBody    string
+		requestWebhook domain.WebhookCreate
+		mockBehaviour  mockBehaviour
+		statusCode     int
+		responseBody   string
+	}{
+		{
+			name:        "ok",
+			requestBody: `{"url": "https://example.com", "secret": "0123456789abcdef", "events": ["url.created"]}`,
+			requestWebhook: domain.WebhookCreate{
+				URL:    "https://example.com",
+				Secret: "0123456789abcdef",
+				Events: []string{"url.created"},
+				Owner:  userId,
+			},
+			mockBehaviour: func(s *mockService.MockWebhooks, webhook domain.WebhookCreate) {
+				s.EXPECT().Create(context.Background(), webhook).Return(domain.Webhook{}, nil)
+			},
+			statusCode: 201,
+		},
+		{
+			name:          "unknown event",
+			requestBody:   `{"url": "https://example.com", "secret": "0123456789abcdef", "events": ["url.visited"]}`,
+			mockBehaviour: func(s *mockService.MockWebhooks, webhook domain.WebhookCreate) {},
+			statusCode:    422,
+			responseBody: problemBody(ErrValidation, fieldError{Field: "events[0]", Rule: "oneof",
+				Param: "url.created url.prolonged url.deleted url.expired url.milestone url.broken"}),
+		},
+		{
+			name:          "short secret",
+			requestBody:   `{"url": "https://example.com", "secret": "secret", "events": ["url.created"]}`,
+			mockBehaviour: func(s *mockService.MockWebhooks, webhook domain.WebhookCreate) {},
+			statusCode:    422,
+			responseBody:  problemBody(ErrValidation, fieldError{Field: "secret", Rule: "min", Param: "16"}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooksService := mockService.NewMockWebhooks(c)
+			tt.mockBehaviour(webhooksService, tt.requestWebhook)
+
+			services := &service.Services{Webhooks: webhooksService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.POST("/webhooks", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.createWebhook)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/webhooks", bytes.NewBufferString(tt.requestBody))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+
+			if tt.responseBody != "" {
+				assert.Equal(t, tt.responseBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_deleteWebhook(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+	id := primitive.NewObjectID()
+
+	tests := []struct {
+		name          string
+		id            string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name: "ok",
+			id:   id.Hex(),
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), id, ownerId).Return(nil)
+			},
+			statusCode:   204,
+			responseBody: "",
+		},
+		{
+			name:          "invalid id",
+			id:            "qwe",
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {},
+			statusCode:    400,
+			responseBody:  problemBody(ErrInvalidID),
+		},
+		{
+			name: "webhook not found",
+			id:   id.Hex(),
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), id, ownerId).Return(repo.ErrWebhookNotFound)
+			},
+			statusCode:   404,
+			responseBody: problemBody(repo.ErrWebhookNotFound),
+		},
+		{
+			name: "webhook forbidden",
+			id:   id.Hex(),
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {
+				s.EXPECT().Delete(context.Background(), id, ownerId).Return(service.ErrWebhookForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrWebhookForbidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooksService := mockService.NewMockWebhooks(c)
+			tt.mockBehaviour(webhooksService, id, userId)
+
+			services := &service.Services{Webhooks: webhooksService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.DELETE("/webhooks/:id", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.deleteWebhook)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("DELETE", "/webhooks/"+tt.id, bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
+
+func TestHandler_listWebhookDeliveries(t *testing.T) {
+	type mockBehaviour func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID)
+
+	userId := primitive.NewObjectID()
+	id := primitive.NewObjectID()
+
+	deliveries := []domain.WebhookDelivery{
+		{
+			ID:          primitive.NewObjectID(),
+			Webhook:     id,
+			Event:       domain.EventURLCreated,
+			Payload:     `{}`,
+			Attempts:    1,
+			StatusCode:  200,
+			Delivered:   true,
+			CreatedAt:   time.Now(),
+			AttemptedAt: time.Now(),
+		},
+	}
+
+	setResponseBody := func(deliveries []domain.WebhookDelivery) string {
+		body, _ := json.Marshal(deliveries)
+
+		return string(body)
+	}
+
+	tests := []struct {
+		name          string
+		mockBehaviour mockBehaviour
+		statusCode    int
+		responseBody  string
+	}{
+		{
+			name: "ok",
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {
+				s.EXPECT().ListDeliveries(context.Background(), id, ownerId).Return(deliveries, nil)
+			},
+			statusCode:   200,
+			responseBody: setResponseBody(deliveries),
+		},
+		{
+			name: "webhook forbidden",
+			mockBehaviour: func(s *mockService.MockWebhooks, id primitive.ObjectID, ownerId primitive.ObjectID) {
+				s.EXPECT().ListDeliveries(context.Background(), id, ownerId).Return(nil, service.ErrWebhookForbidden)
+			},
+			statusCode:   403,
+			responseBody: problemBody(service.ErrWebhookForbidden),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Init Dependencies
+			c := gomock.NewController(t)
+			defer c.Finish()
+
+			webhooksService := mockService.NewMockWebhooks(c)
+			tt.mockBehaviour(webhooksService, id, userId)
+
+			services := &service.Services{Webhooks: webhooksService}
+			handler := &Handler{services: services}
+
+			// Init Endpoint
+			r := gin.New()
+			r.GET("/webhooks/:id/deliveries", errorHandler, func(c *gin.Context) {
+				c.Set(userCtx, userId.Hex())
+			}, handler.listWebhookDeliveries)
+
+			// Create Request
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/webhooks/"+id.Hex()+"/deliveries", bytes.NewBufferString(""))
+
+			// Make Request
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.statusCode, w.Code)
+			assert.Equal(t, tt.responseBody, w.Body.String())
+		})
+	}
+}
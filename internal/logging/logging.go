@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Fields attached to log entries of request
+const (
+	FieldRequestID = "request_id"
+	FieldTraceID   = "trace_id"
+)
+
+type requestIDKey struct{}
+
+// Configure sets level of standard logger and formats its entries as JSON with redacted fields
+func Configure(level string, redact []string) error {
+	lvl, err := log.ParseLevel(level)
+
+	if err != nil {
+		return err
+	}
+
+	log.SetLevel(lvl)
+	log.SetFormatter(newRedactingFormatter(&log.JSONFormatter{}, redact))
+
+	return nil
+}
+
+// WithRequestID stores id of request in context
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns id of request stored in context or empty string
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// FromContext returns entry of standard logger with id of request and trace from context
+func FromContext(ctx context.Context) *log.Entry {
+	entry := log.WithContext(ctx)
+
+	if id := RequestID(ctx); id != "" {
+		entry = entry.WithField(FieldRequestID, id)
+	}
+
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		entry = entry.WithField(FieldTraceID, span.TraceID().String())
+	}
+
+	return entry
+}
+
+// Detach returns context not cancelled with ctx, but keeping id of request and span of it.
+// Work outliving request is logged and traced as part of it
+func Detach(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+
+	if id := RequestID(ctx); id != "" {
+		detached = WithRequestID(detached, id)
+	}
+
+	return detached
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/mebr0/tiny-url/internal/tracing"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// captureLogs redirects standard logger to buffer for duration of test
+func captureLogs(t *testing.T, redact []string) *bytes.Buffer {
+	t.Helper()
+
+	logger := log.StandardLogger()
+	out, formatter, level := logger.Out, logger.Formatter, logger.Level
+
+	t.Cleanup(func() {
+		log.SetOutput(out)
+		log.SetFormatter(formatter)
+		log.SetLevel(level)
+	})
+
+	require.NoError(t, Configure("INFO", redact))
+
+	buf := &bytes.Buffer{}
+	log.SetOutput(buf)
+
+	return buf
+}
+
+// lastEntry decodes last line of logs
+func lastEntry(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry))
+
+	return entry
+}
+
+func TestConfigure(t *testing.T) {
+	captureLogs(t, nil)
+
+	require.NoError(t, Configure("WARN", nil))
+	require.Equal(t, log.WarnLevel, log.GetLevel())
+
+	require.Error(t, Configure("loud", nil))
+}
+
+func TestFromContext(t *testing.T) {
+	buf := captureLogs(t, nil)
+
+	provider, _ := tracing.NewInMemoryProvider()
+
+	ctx, span := provider.Tracer("test").Start(WithRequestID(context.Background(), "req-1"), "test")
+	defer span.End()
+
+	FromContext(ctx).Info("Hello")
+
+	entry := lastEntry(t, buf)
+
+	require.Equal(t, "req-1", entry[FieldRequestID])
+	require.Equal(t, span.SpanContext().TraceID().String(), entry[FieldTraceID])
+	require.Equal(t, "Hello", entry["msg"])
+}
+
+func TestDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithRequestID(context.Background(), "req-1"))
+	cancel()
+
+	detached := Detach(ctx)
+
+	require.NoError(t, detached.Err())
+	require.Equal(t, "req-1", RequestID(detached))
+}
@@ -0,0 +1,105 @@
+package logging
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"io/ioutil"
+	"net/http"
+	"runtime/debug"
+	"time"
+)
+
+// RequestIDHeader carries id of request from client and back to it
+const RequestIDHeader = "X-Request-ID"
+
+// Longer ids of clients are replaced with generated ones
+const maxRequestIDLength = 128
+
+// Middleware assigns id to request, stores it in context of request and logs finished request.
+// Values of query parameters with redacted names are hidden
+func Middleware(redact []string) gin.HandlerFunc {
+	keys := redactedKeys(redact)
+
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		id := c.GetHeader(RequestIDHeader)
+
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(attribute.String("http.request_id", id))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		entry := FromContext(c.Request.Context()).WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"path":       redactQuery(c.Request.URL, keys),
+			"route":      c.FullPath(),
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"client_ip":  c.ClientIP(),
+			"user_agent": c.Request.UserAgent(),
+			"size":       c.Writer.Size(),
+		})
+
+		if len(c.Errors) > 0 {
+			entry = entry.WithField("errors", c.Errors.String())
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			entry.Error("Request finished")
+		case status >= http.StatusBadRequest:
+			entry.Warn("Request finished")
+		default:
+			entry.Info("Request finished")
+		}
+	}
+}
+
+// Recovery responds with internal error to panicked request and logs panic with stack
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(ioutil.Discard, func(c *gin.Context, recovered interface{}) {
+		FromContext(c.Request.Context()).WithFields(log.Fields{
+			"panic": fmt.Sprint(recovered),
+			"stack": string(debug.Stack()),
+		}).Error("Request panicked")
+
+		c.AbortWithStatus(http.StatusInternalServerError)
+	})
+}
+
+// validRequestID accepts non-empty ids of printable ASCII characters
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, r := range id {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+
+	// Reading of random bytes does not fail on supported platforms
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestID  string
+		propagated bool
+	}{
+		{
+			name:       "propagated id",
+			requestID:  "0f8fad5b-d9cb-469f-a165-70867728950e",
+			propagated: true,
+		},
+		{
+			name: "generated id",
+		},
+		{
+			name:      "invalid id",
+			requestID: "bad id\n",
+		},
+		{
+			name:      "too long id",
+			requestID: strings.Repeat("a", maxRequestIDLength+1),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := captureLogs(t, []string{"token"})
+
+			var stored string
+
+			r := gin.New()
+			r.Use(Middleware([]string{"token"}))
+			r.GET("/urls/:alias", func(c *gin.Context) {
+				stored = RequestID(c.Request.Context())
+
+				c.Status(http.StatusNotFound)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/urls/docs?token=secret", nil)
+
+			if tt.requestID != "" {
+				req.Header.Set(RequestIDHeader, tt.requestID)
+			}
+
+			r.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+
+			require.NotEmpty(t, id)
+			require.Equal(t, id, stored)
+			require.Equal(t, tt.propagated, id == tt.requestID)
+
+			entry := lastEntry(t, buf)
+
+			require.Equal(t, id, entry[FieldRequestID])
+			require.Equal(t, "warning", entry["level"])
+			require.Equal(t, "/urls/:alias", entry["route"])
+			require.Equal(t, "/urls/docs?token=%5BREDACTED%5D", entry["path"])
+			require.Equal(t, float64(http.StatusNotFound), entry["status"])
+		})
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	buf := captureLogs(t, nil)
+
+	r := gin.New()
+	r.Use(Middleware(nil), Recovery())
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+
+	r.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Contains(t, buf.String(), `"panic":"boom"`)
+
+	entry := lastEntry(t, buf)
+
+	require.Equal(t, "error", entry["level"])
+	require.Equal(t, w.Header().Get(RequestIDHeader), entry[FieldRequestID])
+}
@@ -0,0 +1,20 @@
+package logging
+
+import (
+	"context"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// MongoMonitor logs failed mongo commands as part of request running them
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			FromContext(ctx).WithFields(log.Fields{
+				"database":   "mongo",
+				"operation":  e.CommandName,
+				log.ErrorKey: e.Failure,
+			}).Warn("Database operation failed")
+		},
+	}
+}
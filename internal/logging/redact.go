@@ -0,0 +1,68 @@
+package logging
+
+import (
+	log "github.com/sirupsen/logrus"
+	"net/url"
+	"strings"
+)
+
+// Replacement of redacted values
+const redacted = "[REDACTED]"
+
+// redactingFormatter hides values of fields with sensitive names, names are matched case-insensitively
+type redactingFormatter struct {
+	formatter log.Formatter
+	keys      map[string]bool
+}
+
+func newRedactingFormatter(formatter log.Formatter, keys []string) *redactingFormatter {
+	return &redactingFormatter{
+		formatter: formatter,
+		keys:      redactedKeys(keys),
+	}
+}
+
+func (f *redactingFormatter) Format(entry *log.Entry) ([]byte, error) {
+	data := make(log.Fields, len(entry.Data))
+
+	for k, v := range entry.Data {
+		if f.keys[strings.ToLower(k)] {
+			v = redacted
+		}
+
+		data[k] = v
+	}
+
+	// Entry is shared with other hooks, so redacted copy is formatted
+	clone := *entry
+	clone.Data = data
+
+	return f.formatter.Format(&clone)
+}
+
+// redactQuery hides values of query parameters with sensitive names in request URI
+func redactQuery(u *url.URL, keys map[string]bool) string {
+	if u.RawQuery == "" {
+		return u.Path
+	}
+
+	query := u.Query()
+
+	for k := range query {
+		if keys[strings.ToLower(k)] {
+			query[k] = []string{redacted}
+		}
+	}
+
+	return u.Path + "?" + query.Encode()
+}
+
+func redactedKeys(keys []string) map[string]bool {
+	set := make(map[string]bool, len(keys))
+
+	for _, k := range keys {
+		set[strings.ToLower(strings.TrimSpace(k))] = true
+	}
+
+	return set
+}
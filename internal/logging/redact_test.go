@@ -0,0 +1,58 @@
+package logging
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func TestRedactingFormatter(t *testing.T) {
+	buf := captureLogs(t, []string{"password", "accessToken"})
+
+	entry := log.WithFields(log.Fields{
+		"Password":    "qwerty",
+		"accesstoken": "jwt",
+		"email":       "user@example.com",
+	})
+	entry.Info("Login")
+
+	logged := lastEntry(t, buf)
+
+	require.Equal(t, redacted, logged["Password"])
+	require.Equal(t, redacted, logged["accesstoken"])
+	require.Equal(t, "user@example.com", logged["email"])
+
+	// Fields of original entry are kept
+	require.Equal(t, "qwerty", entry.Data["Password"])
+}
+
+func TestRedactQuery(t *testing.T) {
+	keys := redactedKeys([]string{"token"})
+
+	tests := []struct {
+		name     string
+		uri      string
+		expected string
+	}{
+		{
+			name:     "without query",
+			uri:      "/api/v1/urls",
+			expected: "/api/v1/urls",
+		},
+		{
+			name:     "redacted parameter",
+			uri:      "/api/v1/auth/reset?Token=secret&lang=en",
+			expected: "/api/v1/auth/reset?Token=%5BREDACTED%5D&lang=en",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := url.ParseRequestURI(tt.uri)
+			require.NoError(t, err)
+
+			require.Equal(t, tt.expected, redactQuery(u, keys))
+		})
+	}
+}
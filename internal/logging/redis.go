@@ -0,0 +1,41 @@
+package logging
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	log "github.com/sirupsen/logrus"
+)
+
+// RedisHook logs failed redis commands as part of request running them, missing key is not a failure
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	logFailure(ctx, cmd)
+
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return ctx, nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	for _, cmd := range cmds {
+		logFailure(ctx, cmd)
+	}
+
+	return nil
+}
+
+func logFailure(ctx context.Context, cmd redis.Cmder) {
+	if err := cmd.Err(); err != nil && err != redis.Nil {
+		FromContext(ctx).WithError(err).WithFields(log.Fields{
+			"database":  "redis",
+			"operation": cmd.Name(),
+		}).Warn("Cache operation failed")
+	}
+}
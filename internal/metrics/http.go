@@ -0,0 +1,40 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Route of requests not matched by any route, so paths of scanners do not create new series
+const unmatchedRoute = "unmatched"
+
+// Middleware counts requests and their latency by route template
+func Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		route := c.FullPath()
+
+		if route == "" {
+			route = unmatchedRoute
+		}
+
+		status := strconv.Itoa(c.Writer.Status())
+
+		HTTPRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		HTTPRequestDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// Handler serves metrics in Prometheus format on /metrics
+func Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return mux
+}
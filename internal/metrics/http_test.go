@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	r := gin.New()
+	r.Use(Middleware())
+	r.GET("/api/v1/urls/:alias", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		route  string
+		status string
+	}{
+		{
+			name:   "matched route",
+			path:   "/api/v1/urls/first",
+			route:  "/api/v1/urls/:alias",
+			status: "204",
+		},
+		{
+			name:   "unmatched route",
+			path:   "/wp-login.php",
+			route:  unmatchedRoute,
+			status: "404",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			counter := HTTPRequests.WithLabelValues(tt.route, http.MethodGet, tt.status)
+			before := testutil.ToFloat64(counter)
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+
+			r.ServeHTTP(w, req)
+
+			require.Equal(t, before+1, testutil.ToFloat64(counter))
+		})
+	}
+}
+
+func TestHandler(t *testing.T) {
+	Redirects.WithLabelValues(ResultHit).Inc()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+
+	Handler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.True(t, strings.Contains(w.Body.String(), `tiny_url_redirects_total{result="hit"}`))
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Namespace of all metrics of service
+const namespace = "tiny_url"
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "Count of HTTP requests by route, method and status.",
+	}, []string{"route", "method", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of HTTP requests by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+
+	Redirects = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Count of redirections by result: hit, bot, miss or expired.",
+	}, []string{"result"})
+
+	URLCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "url_cache_requests_total",
+		Help:      "Count of URL lookups in cache by result: hit, missing or miss.",
+	}, []string{"result"})
+
+	AliasCollisions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "alias_collisions_total",
+		Help:      "Count of retries of alias generation because of existing alias.",
+	})
+
+	URLs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "urls",
+		Help:      "Count of URLs by state: active or expired.",
+	}, []string{"state"})
+
+	DatabaseOperationDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "database_operation_duration_seconds",
+		Help:      "Latency of operations of mongo and redis by operation.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"database", "operation"})
+
+	DatabaseOperationErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "database_operation_errors_total",
+		Help:      "Count of failed operations of mongo and redis by operation.",
+	}, []string{"database", "operation"})
+)
+
+// Results of redirection and lookups in cache
+const (
+	ResultHit     = "hit"
+	ResultBot     = "bot"
+	ResultMiss    = "miss"
+	ResultMissing = "missing"
+	ResultExpired = "expired"
+)
+
+// States of URLs
+const (
+	StateActive  = "active"
+	StateExpired = "expired"
+)
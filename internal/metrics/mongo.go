@@ -0,0 +1,26 @@
+package metrics
+
+import (
+	"context"
+	"go.mongodb.org/mongo-driver/event"
+	"time"
+)
+
+const databaseMongo = "mongo"
+
+// MongoMonitor observes latency and errors of mongo commands
+func MongoMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			observeMongo(e.CommandName, e.DurationNanos)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			observeMongo(e.CommandName, e.DurationNanos)
+			DatabaseOperationErrors.WithLabelValues(databaseMongo, e.CommandName).Inc()
+		},
+	}
+}
+
+func observeMongo(command string, durationNanos int64) {
+	DatabaseOperationDuration.WithLabelValues(databaseMongo, command).Observe(time.Duration(durationNanos).Seconds())
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"github.com/go-redis/redis/v8"
+	"time"
+)
+
+const databaseRedis = "redis"
+
+// Pipelines are observed as single operation
+const pipelineOperation = "pipeline"
+
+type startKey struct{}
+
+// RedisHook observes latency and errors of redis commands
+type RedisHook struct{}
+
+func (RedisHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, startKey{}, time.Now()), nil
+}
+
+func (RedisHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error {
+	observeRedis(ctx, cmd.Name(), cmd.Err())
+
+	return nil
+}
+
+func (RedisHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
+	return context.WithValue(ctx, startKey{}, time.Now()), nil
+}
+
+func (RedisHook) AfterProcessPipeline(ctx context.Context, cmds []redis.Cmder) error {
+	var err error
+
+	for _, cmd := range cmds {
+		if cmd.Err() != nil && cmd.Err() != redis.Nil {
+			err = cmd.Err()
+		}
+	}
+
+	observeRedis(ctx, pipelineOperation, err)
+
+	return nil
+}
+
+// observeRedis records latency since start kept in context, missing key is not an error
+func observeRedis(ctx context.Context, operation string, err error) {
+	if start, ok := ctx.Value(startKey{}).(time.Time); ok {
+		DatabaseOperationDuration.WithLabelValues(databaseRedis, operation).Observe(time.Since(start).Seconds())
+	}
+
+	if err != nil && err != redis.Nil {
+		DatabaseOperationErrors.WithLabelValues(databaseRedis, operation).Inc()
+	}
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRedisHook(t *testing.T) {
+	server, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	client.AddHook(RedisHook{})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	ctx := context.Background()
+	errors := DatabaseOperationErrors.WithLabelValues(databaseRedis, "get")
+	before := testutil.ToFloat64(errors)
+
+	// Missing key is not an error
+	require.ErrorIs(t, client.Get(ctx, "missing").Err(), redis.Nil)
+	require.Equal(t, before, testutil.ToFloat64(errors))
+
+	server.SetError("server is down")
+
+	require.Error(t, client.Get(ctx, "missing").Err())
+	require.Equal(t, before+1, testutil.ToFloat64(errors))
+
+	_, err = client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Get(ctx, "missing")
+		return nil
+	})
+	require.Error(t, err)
+	require.Equal(t, float64(1), testutil.ToFloat64(DatabaseOperationErrors.WithLabelValues(databaseRedis, pipelineOperation)))
+}
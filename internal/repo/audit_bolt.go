@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+)
+
+// AuditBoltRepo keeps entries by id, every listing scans the whole bucket
+type AuditBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newAuditBoltRepo(db *bbolt.DB) *AuditBoltRepo {
+	return &AuditBoltRepo{
+		db: db,
+	}
+}
+
+func (r *AuditBoltRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	entries := make([]domain.AuditEntry, 0)
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(auditBucket).ForEach(func(k, v []byte) error {
+			var entry domain.AuditEntry
+
+			if err := bson.Unmarshal(v, &entry); err != nil {
+				return err
+			}
+
+			if filter.Match(entry) {
+				entries = append(entries, entry)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest entries go first
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].ID.Hex() > entries[j].ID.Hex()
+		}
+
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+
+	if int64(len(entries)) > filter.Limit {
+		entries = entries[:filter.Limit]
+	}
+
+	return entries, nil
+}
+
+func (r *AuditBoltRepo) Create(ctx context.Context, entry domain.AuditEntry) (primitive.ObjectID, error) {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return putValue(tx.Bucket(auditBucket), []byte(entry.ID.Hex()), entry)
+	})
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return entry.ID, nil
+}
@@ -0,0 +1,74 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepo struct {
+	db *mongo.Collection
+}
+
+func newAuditRepo(db *mongo.Database) *AuditRepo {
+	return &AuditRepo{
+		db: db.Collection(auditCollection),
+	}
+}
+
+func (r *AuditRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	entries := make([]domain.AuditEntry, 0)
+
+	query := bson.M{}
+
+	if !filter.Actor.IsZero() {
+		query["actor"] = filter.Actor
+	}
+
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+
+	if filter.Target != "" {
+		query["target"] = filter.Target
+	}
+
+	createdAt := bson.M{}
+
+	if !filter.From.IsZero() {
+		createdAt["$gte"] = filter.From
+	}
+
+	if !filter.To.IsZero() {
+		createdAt["$lt"] = filter.To
+	}
+
+	if len(createdAt) > 0 {
+		query["createdAt"] = createdAt
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(filter.Limit)
+
+	cur, err := r.db.Find(ctx, query, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &entries)
+
+	return entries, err
+}
+
+func (r *AuditRepo) Create(ctx context.Context, entry domain.AuditEntry) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, entry)
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+	"strings"
+)
+
+const auditColumns = "id, actor, action, target, ip, user_agent, created_at, changes"
+
+type AuditPostgresRepo struct {
+	db *sql.DB
+}
+
+func newAuditPostgresRepo(db *sql.DB) *AuditPostgresRepo {
+	return &AuditPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *AuditPostgresRepo) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	where := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" $"+strconv.Itoa(len(args)))
+	}
+
+	if !filter.Actor.IsZero() {
+		where("actor =", filter.Actor.Hex())
+	}
+
+	if filter.Action != "" {
+		where("action =", filter.Action)
+	}
+
+	if filter.Target != "" {
+		where("target =", filter.Target)
+	}
+
+	if !filter.From.IsZero() {
+		where("created_at >=", filter.From)
+	}
+
+	if !filter.To.IsZero() {
+		where("created_at <", filter.To)
+	}
+
+	query := "SELECT " + auditColumns + " FROM " + auditTable
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query += " ORDER BY created_at DESC, id DESC LIMIT $" + strconv.Itoa(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := make([]domain.AuditEntry, 0)
+
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *AuditPostgresRepo) Create(ctx context.Context, entry domain.AuditEntry) (primitive.ObjectID, error) {
+	if entry.ID.IsZero() {
+		entry.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+auditTable+" ("+auditColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		entry.ID.Hex(), entry.Actor.Hex(), entry.Action, entry.Target, entry.IP, entry.UserAgent, entry.CreatedAt,
+		jsonValue{entry.Changes})
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return entry.ID, nil
+}
+
+func scanAuditEntry(row scanner) (domain.AuditEntry, error) {
+	var entry domain.AuditEntry
+	var id, actor string
+
+	if err := row.Scan(&id, &actor, &entry.Action, &entry.Target, &entry.IP, &entry.UserAgent, &entry.CreatedAt,
+		jsonValue{&entry.Changes}); err != nil {
+		return domain.AuditEntry{}, err
+	}
+
+	var err error
+
+	if entry.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.AuditEntry{}, err
+	}
+
+	entry.Actor, err = primitive.ObjectIDFromHex(actor)
+
+	return entry, err
+}
@@ -0,0 +1,39 @@
+package repo
+
+import (
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	usersBucket             = []byte("users")
+	userEmailsBucket        = []byte("userEmails")
+	urlsBucket              = []byte("urls")
+	webhooksBucket          = []byte("webhooks")
+	webhookDeliveriesBucket = []byte("webhookDeliveries")
+	auditBucket             = []byte("audit")
+	statsBucket             = []byte("stats")
+	tokensBucket            = []byte("tokens")
+)
+
+// Values are kept encoded in bson, so documents have the same fields as in mongo
+
+func getValue(b *bbolt.Bucket, key []byte, v interface{}) (bool, error) {
+	data := b.Get(key)
+
+	if data == nil {
+		return false, nil
+	}
+
+	return true, bson.Unmarshal(data, v)
+}
+
+func putValue(b *bbolt.Bucket, key []byte, v interface{}) error {
+	data, err := bson.Marshal(v)
+
+	if err != nil {
+		return err
+	}
+
+	return b.Put(key, data)
+}
@@ -1,10 +1,12 @@
 package repo
 
-import "errors"
+import "github.com/mebr0/tiny-url/internal/domain"
 
 var (
-	ErrUserNotFound      = errors.New("user doesn't exists")
-	ErrUserAlreadyExists = errors.New("user already exists")
-	ErrURLNotFound       = errors.New("url doesn't exists")
-	ErrURLAlreadyExists  = errors.New("url already exists")
+	ErrUserNotFound      = domain.NewError(domain.KindNotFound, "user_not_found", "user doesn't exists")
+	ErrUserAlreadyExists = domain.NewError(domain.KindConflict, "user_already_exists", "user already exists")
+	ErrURLNotFound       = domain.NewError(domain.KindNotFound, "url_not_found", "url doesn't exists")
+	ErrURLAlreadyExists  = domain.NewError(domain.KindConflict, "url_already_exists", "url already exists")
+	ErrWebhookNotFound   = domain.NewError(domain.KindNotFound, "webhook_not_found", "webhook doesn't exists")
+	ErrTokenNotFound     = domain.NewError(domain.KindNotFound, "token_not_found", "token doesn't exists")
 )
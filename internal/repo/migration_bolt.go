@@ -0,0 +1,86 @@
+package repo
+
+import (
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/database/boltdb"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// boltMigrations of embedded database, new migrations are only appended with next version
+var boltMigrations = []boltdb.Migration{
+	{
+		Version:     1,
+		Description: "buckets",
+		Up: func(tx *bbolt.Tx) error {
+			for _, name := range [][]byte{usersBucket, userEmailsBucket, urlsBucket, webhooksBucket, webhookDeliveriesBucket} {
+				if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+	{
+		Version:     2,
+		Description: "audit log",
+		Up: func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(auditBucket)
+
+			return err
+		},
+	},
+	{
+		Version:     3,
+		Description: "hourly rollups of clicks",
+		Up: func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucketIfNotExists(statsBucket)
+
+			return err
+		},
+	},
+	{
+		Version:     4,
+		Description: "tokens of users, users registered before verification are trusted",
+		Up: func(tx *bbolt.Tx) error {
+			if _, err := tx.CreateBucketIfNotExists(tokensBucket); err != nil {
+				return err
+			}
+
+			users := tx.Bucket(usersBucket)
+
+			var updated []domain.User
+
+			err := users.ForEach(func(k, v []byte) error {
+				var user domain.User
+
+				if err := bson.Unmarshal(v, &user); err != nil {
+					return err
+				}
+
+				user.Verified = true
+				updated = append(updated, user)
+
+				return nil
+			})
+
+			if err != nil {
+				return err
+			}
+
+			for _, user := range updated {
+				if err := putValue(users, []byte(user.ID.Hex()), user); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	},
+}
+
+// MigrateBolt creates buckets of embedded database, returns applied versions
+func MigrateBolt(db *bbolt.DB) ([]int, error) {
+	return boltdb.Migrate(db, boltMigrations)
+}
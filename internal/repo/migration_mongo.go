@@ -0,0 +1,162 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/pkg/database/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+// mongoMigrations of database schema, new migrations are only appended with next version
+var mongoMigrations = []mongodb.Migration{
+	{
+		Version:     1,
+		Description: "unique emails of users",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(usersCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "email", Value: 1}},
+				Options: options.Index().SetUnique(true),
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     2,
+		Description: "lookup of urls by owner",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(urlsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "original", Value: 1}}},
+				{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "expiredAt", Value: 1}}},
+				{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "health.broken", Value: 1}}},
+				{Keys: bson.D{{Key: "expiredAt", Value: 1}, {Key: "expirationNotified", Value: 1}}},
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     3,
+		Description: "text search of urls",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			// Aliases and tags are the most relevant
+			_, err := db.Collection(urlsCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{
+					{Key: "_id", Value: "text"},
+					{Key: "original", Value: "text"},
+					{Key: "tags", Value: "text"},
+					{Key: "metadata.title", Value: "text"},
+				},
+				Options: options.Index().SetName("search").SetWeights(bson.M{
+					"_id":            10,
+					"tags":           5,
+					"metadata.title": 3,
+					"original":       1,
+				}),
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     4,
+		Description: "lookup of webhooks and deliveries",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(webhooksCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "owner", Value: 1}, {Key: "events", Value: 1}},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Collection(webhookDeliveriesCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys: bson.D{{Key: "webhook", Value: 1}, {Key: "createdAt", Value: -1}},
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     5,
+		Description: "skip expiration events of urls expired before webhooks",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(urlsCollection).UpdateMany(ctx, bson.M{
+				"expiredAt":          bson.M{"$lt": time.Now()},
+				"expirationNotified": bson.M{"$exists": false},
+			}, bson.M{"$set": bson.M{"expirationNotified": true}})
+
+			return err
+		},
+	},
+	{
+		Version:     6,
+		Description: "lookup of audit entries",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(auditCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "actor", Value: 1}, {Key: "createdAt", Value: -1}}},
+				{Keys: bson.D{{Key: "target", Value: 1}, {Key: "createdAt", Value: -1}}},
+				{Keys: bson.D{{Key: "createdAt", Value: -1}}},
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     7,
+		Description: "hourly rollups of clicks",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(statsCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{
+					Keys:    bson.D{{Key: "alias", Value: 1}, {Key: "hour", Value: 1}},
+					Options: options.Index().SetUnique(true),
+				},
+				{Keys: bson.D{{Key: "owner", Value: 1}, {Key: "hour", Value: 1}}},
+			})
+
+			return err
+		},
+	},
+	{
+		Version:     8,
+		Description: "tokens of users, users registered before verification are trusted",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(tokensCollection).Indexes().CreateMany(ctx, []mongo.IndexModel{
+				{Keys: bson.D{{Key: "user", Value: 1}, {Key: "purpose", Value: 1}}},
+				{
+					Keys:    bson.D{{Key: "expiredAt", Value: 1}},
+					Options: options.Index().SetExpireAfterSeconds(0),
+				},
+			})
+
+			if err != nil {
+				return err
+			}
+
+			_, err = db.Collection(usersCollection).UpdateMany(ctx, bson.M{
+				"verified": bson.M{"$exists": false},
+			}, bson.M{"$set": bson.M{"verified": true}})
+
+			return err
+		},
+	},
+	{
+		Version:     9,
+		Description: "pending deliveries of webhooks",
+		Up: func(ctx context.Context, db *mongo.Database) error {
+			_, err := db.Collection(webhookDeliveriesCollection).Indexes().CreateOne(ctx, mongo.IndexModel{
+				Keys:    bson.D{{Key: "pending", Value: 1}, {Key: "nextAttemptAt", Value: 1}},
+				Options: options.Index().SetPartialFilterExpression(bson.M{"pending": true}),
+			})
+
+			return err
+		},
+	},
+}
+
+// MigrateMongo creates indexes and updates data of database to the latest version, returns applied versions
+func MigrateMongo(ctx context.Context, db *mongo.Database) ([]int, error) {
+	return mongodb.Migrate(ctx, db, mongoMigrations)
+}
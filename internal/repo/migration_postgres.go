@@ -0,0 +1,155 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mebr0/tiny-url/pkg/database/postgres"
+)
+
+// postgresMigrations of database schema, new migrations are only appended with next version.
+// Ids of users, webhooks and deliveries are kept as hexadecimal object ids for compatibility with mongo
+var postgresMigrations = []postgres.Migration{
+	{
+		Version:     1,
+		Description: "users",
+		Up: `CREATE TABLE ` + usersTable + ` (
+			id            CHAR(24) PRIMARY KEY,
+			name          TEXT NOT NULL,
+			email         TEXT NOT NULL UNIQUE,
+			password      TEXT NOT NULL,
+			registered_at TIMESTAMPTZ NOT NULL,
+			last_login    TIMESTAMPTZ NOT NULL
+		)`,
+	},
+	{
+		Version:     2,
+		Description: "urls",
+		Up: `CREATE TABLE ` + urlsTable + ` (
+			alias               TEXT PRIMARY KEY,
+			original            TEXT NOT NULL,
+			created_at          TIMESTAMPTZ NOT NULL,
+			expired_at          TIMESTAMPTZ NOT NULL,
+			owner               CHAR(24) NOT NULL,
+			tags                TEXT[],
+			metadata            JSONB NOT NULL DEFAULT '{}',
+			health              JSONB NOT NULL DEFAULT '{}',
+			clicks              BIGINT NOT NULL DEFAULT 0,
+			expiration_notified BOOLEAN NOT NULL DEFAULT FALSE,
+			forward_query       BOOLEAN NOT NULL DEFAULT FALSE,
+			forward_path        BOOLEAN NOT NULL DEFAULT FALSE,
+			rules               JSONB,
+			variants            JSONB,
+			sticky_variants     BOOLEAN NOT NULL DEFAULT FALSE
+		);
+		CREATE INDEX urls_owner_original_idx ON ` + urlsTable + ` (owner, original);
+		CREATE INDEX urls_owner_expired_at_idx ON ` + urlsTable + ` (owner, expired_at);
+		CREATE INDEX urls_expired_at_idx ON ` + urlsTable + ` (expired_at) WHERE NOT expiration_notified`,
+	},
+	{
+		Version:     3,
+		Description: "webhooks and deliveries",
+		Up: `CREATE TABLE ` + webhooksTable + ` (
+			id         CHAR(24) PRIMARY KEY,
+			url        TEXT NOT NULL,
+			secret     TEXT NOT NULL,
+			events     TEXT[] NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			owner      CHAR(24) NOT NULL
+		);
+		CREATE INDEX webhooks_owner_idx ON ` + webhooksTable + ` (owner);
+		CREATE TABLE ` + webhookDeliveriesTable + ` (
+			id           CHAR(24) PRIMARY KEY,
+			webhook      CHAR(24) NOT NULL REFERENCES ` + webhooksTable + ` (id) ON DELETE CASCADE,
+			event        TEXT NOT NULL,
+			payload      TEXT NOT NULL,
+			attempts     INTEGER NOT NULL,
+			status_code  INTEGER NOT NULL,
+			error        TEXT NOT NULL,
+			delivered    BOOLEAN NOT NULL,
+			created_at   TIMESTAMPTZ NOT NULL,
+			attempted_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX webhook_deliveries_webhook_created_at_idx ON ` + webhookDeliveriesTable + ` (webhook, created_at DESC)`,
+	},
+	{
+		Version:     4,
+		Description: "versions of urls",
+		Up:          `ALTER TABLE ` + urlsTable + ` ADD COLUMN version BIGINT NOT NULL DEFAULT 0`,
+	},
+	{
+		Version:     5,
+		Description: "audit log",
+		Up: `CREATE TABLE ` + auditTable + ` (
+			id         CHAR(24) PRIMARY KEY,
+			actor      CHAR(24) NOT NULL,
+			action     TEXT NOT NULL,
+			target     TEXT NOT NULL,
+			ip         TEXT NOT NULL,
+			user_agent TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			changes    JSONB NOT NULL DEFAULT '[]'
+		);
+		CREATE INDEX audit_actor_created_at_idx ON ` + auditTable + ` (actor, created_at DESC);
+		CREATE INDEX audit_target_created_at_idx ON ` + auditTable + ` (target, created_at DESC);
+		CREATE INDEX audit_created_at_idx ON ` + auditTable + ` (created_at DESC)`,
+	},
+	{
+		Version:     6,
+		Description: "hourly rollups of clicks",
+		Up: `CREATE TABLE ` + statsTable + ` (
+			alias     TEXT NOT NULL,
+			hour      TIMESTAMPTZ NOT NULL,
+			owner     CHAR(24) NOT NULL,
+			clicks    BIGINT NOT NULL DEFAULT 0,
+			referrers JSONB NOT NULL DEFAULT '{}',
+			countries JSONB NOT NULL DEFAULT '{}',
+			browsers  JSONB NOT NULL DEFAULT '{}',
+			os        JSONB NOT NULL DEFAULT '{}',
+			visitors  JSONB NOT NULL DEFAULT '{}',
+			PRIMARY KEY (alias, hour)
+		);
+		CREATE INDEX stats_owner_hour_idx ON ` + statsTable + ` (owner, hour)`,
+	},
+	{
+		Version:     7,
+		Description: "requests of bots in rollups of clicks",
+		Up:          `ALTER TABLE ` + statsTable + ` ADD COLUMN bots BIGINT NOT NULL DEFAULT 0`,
+	},
+	{
+		Version:     8,
+		Description: "tokens of users, users registered before verification are trusted",
+		Up: `ALTER TABLE ` + usersTable + ` ADD COLUMN verified BOOLEAN NOT NULL DEFAULT FALSE;
+		UPDATE ` + usersTable + ` SET verified = TRUE;
+		CREATE TABLE ` + tokensTable + ` (
+			hash       TEXT PRIMARY KEY,
+			user_id    CHAR(24) NOT NULL REFERENCES ` + usersTable + ` (id) ON DELETE CASCADE,
+			purpose    TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			expired_at TIMESTAMPTZ NOT NULL
+		);
+		CREATE INDEX tokens_user_id_purpose_idx ON ` + tokensTable + ` (user_id, purpose)`,
+	},
+	{
+		Version:     9,
+		Description: "emails of tokens for changing email of users",
+		Up:          `ALTER TABLE ` + tokensTable + ` ADD COLUMN email TEXT NOT NULL DEFAULT ''`,
+	},
+	{
+		Version:     10,
+		Description: "pending deliveries of webhooks",
+		Up: `ALTER TABLE ` + webhookDeliveriesTable + ` ADD COLUMN pending BOOLEAN NOT NULL DEFAULT FALSE;
+		ALTER TABLE ` + webhookDeliveriesTable + ` ADD COLUMN next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT 'epoch';
+		CREATE INDEX webhook_deliveries_pending_next_attempt_at_idx ON ` + webhookDeliveriesTable + ` (next_attempt_at)
+			WHERE pending`,
+	},
+	{
+		Version:     11,
+		Description: "generations of access tokens of users",
+		Up:          `ALTER TABLE ` + usersTable + ` ADD COLUMN token_generation BIGINT NOT NULL DEFAULT 0`,
+	},
+}
+
+// MigratePostgres creates tables and updates data of database to the latest version, returns applied versions
+func MigratePostgres(ctx context.Context, db *sql.DB) ([]int, error) {
+	return postgres.Migrate(ctx, db, postgresMigrations)
+}
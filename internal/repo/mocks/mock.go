@@ -52,6 +52,35 @@ func (mr *MockUsersMockRecorder) Create(ctx, user interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUsers)(nil).Create), ctx, user)
 }
 
+// Delete mocks base method.
+func (m *MockUsers) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUsersMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUsers)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockUsers) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUsersMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUsers)(nil).Get), ctx, id)
+}
+
 // GetByCredentials mocks base method.
 func (m *MockUsers) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +96,21 @@ func (mr *MockUsersMockRecorder) GetByCredentials(ctx, email, password interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByCredentials", reflect.TypeOf((*MockUsers)(nil).GetByCredentials), ctx, email, password)
 }
 
+// GetByEmail mocks base method.
+func (m *MockUsers) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByEmail", ctx, email)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetByEmail indicates an expected call of GetByEmail.
+func (mr *MockUsersMockRecorder) GetByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByEmail", reflect.TypeOf((*MockUsers)(nil).GetByEmail), ctx, email)
+}
+
 // List mocks base method.
 func (m *MockUsers) List(ctx context.Context) ([]domain.User, error) {
 	m.ctrl.T.Helper()
@@ -82,6 +126,48 @@ func (mr *MockUsersMockRecorder) List(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUsers)(nil).List), ctx)
 }
 
+// RevokeTokens mocks base method.
+func (m *MockUsers) RevokeTokens(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeTokens", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeTokens indicates an expected call of RevokeTokens.
+func (mr *MockUsersMockRecorder) RevokeTokens(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeTokens", reflect.TypeOf((*MockUsers)(nil).RevokeTokens), ctx, id)
+}
+
+// SetVerified mocks base method.
+func (m *MockUsers) SetVerified(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVerified", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVerified indicates an expected call of SetVerified.
+func (mr *MockUsersMockRecorder) SetVerified(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVerified", reflect.TypeOf((*MockUsers)(nil).SetVerified), ctx, id)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockUsers) UpdateEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, id, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUsersMockRecorder) UpdateEmail(ctx, id, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUsers)(nil).UpdateEmail), ctx, id, email)
+}
+
 // UpdateLastLogin mocks base method.
 func (m *MockUsers) UpdateLastLogin(ctx context.Context, id primitive.ObjectID, lastLogin time.Time) error {
 	m.ctrl.T.Helper()
@@ -96,6 +182,34 @@ func (mr *MockUsersMockRecorder) UpdateLastLogin(ctx, id, lastLogin interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateLastLogin", reflect.TypeOf((*MockUsers)(nil).UpdateLastLogin), ctx, id, lastLogin)
 }
 
+// UpdateName mocks base method.
+func (m *MockUsers) UpdateName(ctx context.Context, id primitive.ObjectID, name string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateName", ctx, id, name)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateName indicates an expected call of UpdateName.
+func (mr *MockUsersMockRecorder) UpdateName(ctx, id, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateName", reflect.TypeOf((*MockUsers)(nil).UpdateName), ctx, id, name)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockUsers) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, id, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockUsersMockRecorder) UpdatePassword(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUsers)(nil).UpdatePassword), ctx, id, password)
+}
+
 // MockURLs is a mock of URLs interface.
 type MockURLs struct {
 	ctrl     *gomock.Controller
@@ -119,6 +233,35 @@ func (m *MockURLs) EXPECT() *MockURLsMockRecorder {
 	return m.recorder
 }
 
+// CollapseVariants mocks base method.
+func (m *MockURLs) CollapseVariants(ctx context.Context, alias, original string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CollapseVariants", ctx, alias, original)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CollapseVariants indicates an expected call of CollapseVariants.
+func (mr *MockURLsMockRecorder) CollapseVariants(ctx, alias, original interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CollapseVariants", reflect.TypeOf((*MockURLs)(nil).CollapseVariants), ctx, alias, original)
+}
+
+// CountByExpiration mocks base method.
+func (m *MockURLs) CountByExpiration(ctx context.Context, expired bool) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByExpiration", ctx, expired)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByExpiration indicates an expected call of CountByExpiration.
+func (mr *MockURLsMockRecorder) CountByExpiration(ctx, expired interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByExpiration", reflect.TypeOf((*MockURLs)(nil).CountByExpiration), ctx, expired)
+}
+
 // Create mocks base method.
 func (m *MockURLs) Create(ctx context.Context, url domain.URL) (string, error) {
 	m.ctrl.T.Helper()
@@ -178,6 +321,36 @@ func (mr *MockURLsMockRecorder) GetByOriginalAndOwner(ctx, original, owner inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOriginalAndOwner", reflect.TypeOf((*MockURLs)(nil).GetByOriginalAndOwner), ctx, original, owner)
 }
 
+// IncrementClicks mocks base method.
+func (m *MockURLs) IncrementClicks(ctx context.Context, alias, variant string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementClicks", ctx, alias, variant)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IncrementClicks indicates an expected call of IncrementClicks.
+func (mr *MockURLsMockRecorder) IncrementClicks(ctx, alias, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementClicks", reflect.TypeOf((*MockURLs)(nil).IncrementClicks), ctx, alias, variant)
+}
+
+// ListActive mocks base method.
+func (m *MockURLs) ListActive(ctx context.Context) ([]domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActive", ctx)
+	ret0, _ := ret[0].([]domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActive indicates an expected call of ListActive.
+func (mr *MockURLsMockRecorder) ListActive(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActive", reflect.TypeOf((*MockURLs)(nil).ListActive), ctx)
+}
+
 // ListByOwner mocks base method.
 func (m *MockURLs) ListByOwner(ctx context.Context, userId primitive.ObjectID) ([]domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -208,6 +381,36 @@ func (mr *MockURLsMockRecorder) ListByOwnerAndExpiration(ctx, userId, expired in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwnerAndExpiration", reflect.TypeOf((*MockURLs)(nil).ListByOwnerAndExpiration), ctx, userId, expired)
 }
 
+// ListByOwnerAndHealth mocks base method.
+func (m *MockURLs) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwnerAndHealth", ctx, userId, broken)
+	ret0, _ := ret[0].([]domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwnerAndHealth indicates an expected call of ListByOwnerAndHealth.
+func (mr *MockURLsMockRecorder) ListByOwnerAndHealth(ctx, userId, broken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwnerAndHealth", reflect.TypeOf((*MockURLs)(nil).ListByOwnerAndHealth), ctx, userId, broken)
+}
+
+// ListNewlyExpired mocks base method.
+func (m *MockURLs) ListNewlyExpired(ctx context.Context) ([]domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListNewlyExpired", ctx)
+	ret0, _ := ret[0].([]domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListNewlyExpired indicates an expected call of ListNewlyExpired.
+func (mr *MockURLsMockRecorder) ListNewlyExpired(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListNewlyExpired", reflect.TypeOf((*MockURLs)(nil).ListNewlyExpired), ctx)
+}
+
 // Prolong mocks base method.
 func (m *MockURLs) Prolong(ctx context.Context, alias string, toProlong domain.URLProlong) error {
 	m.ctrl.T.Helper()
@@ -221,3 +424,471 @@ func (mr *MockURLsMockRecorder) Prolong(ctx, alias, toProlong interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prolong", reflect.TypeOf((*MockURLs)(nil).Prolong), ctx, alias, toProlong)
 }
+
+// Replace mocks base method.
+func (m *MockURLs) Replace(ctx context.Context, url domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Replace", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Replace indicates an expected call of Replace.
+func (mr *MockURLsMockRecorder) Replace(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Replace", reflect.TypeOf((*MockURLs)(nil).Replace), ctx, url)
+}
+
+// Search mocks base method.
+func (m *MockURLs) Search(ctx context.Context, userId primitive.ObjectID, query string, limit int64) ([]domain.URLSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, userId, query, limit)
+	ret0, _ := ret[0].([]domain.URLSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockURLsMockRecorder) Search(ctx, userId, query, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockURLs)(nil).Search), ctx, userId, query, limit)
+}
+
+// SetExpirationNotified mocks base method.
+func (m *MockURLs) SetExpirationNotified(ctx context.Context, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetExpirationNotified", ctx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetExpirationNotified indicates an expected call of SetExpirationNotified.
+func (mr *MockURLsMockRecorder) SetExpirationNotified(ctx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetExpirationNotified", reflect.TypeOf((*MockURLs)(nil).SetExpirationNotified), ctx, alias)
+}
+
+// UpdateHealth mocks base method.
+func (m *MockURLs) UpdateHealth(ctx context.Context, alias string, health domain.URLHealth) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHealth", ctx, alias, health)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateHealth indicates an expected call of UpdateHealth.
+func (mr *MockURLsMockRecorder) UpdateHealth(ctx, alias, health interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHealth", reflect.TypeOf((*MockURLs)(nil).UpdateHealth), ctx, alias, health)
+}
+
+// UpdateMetadata mocks base method.
+func (m *MockURLs) UpdateMetadata(ctx context.Context, alias string, metadata domain.URLMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMetadata", ctx, alias, metadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMetadata indicates an expected call of UpdateMetadata.
+func (mr *MockURLsMockRecorder) UpdateMetadata(ctx, alias, metadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMetadata", reflect.TypeOf((*MockURLs)(nil).UpdateMetadata), ctx, alias, metadata)
+}
+
+// UpdateOwner mocks base method.
+func (m *MockURLs) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOwner", ctx, alias, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOwner indicates an expected call of UpdateOwner.
+func (mr *MockURLsMockRecorder) UpdateOwner(ctx, alias, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOwner", reflect.TypeOf((*MockURLs)(nil).UpdateOwner), ctx, alias, owner)
+}
+
+// UpdateRules mocks base method.
+func (m *MockURLs) UpdateRules(ctx context.Context, alias string, rules []domain.RedirectRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRules", ctx, alias, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateRules indicates an expected call of UpdateRules.
+func (mr *MockURLsMockRecorder) UpdateRules(ctx, alias, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRules", reflect.TypeOf((*MockURLs)(nil).UpdateRules), ctx, alias, rules)
+}
+
+// UpdateVariants mocks base method.
+func (m *MockURLs) UpdateVariants(ctx context.Context, alias string, variants []domain.Variant, sticky bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, alias, variants, sticky)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockURLsMockRecorder) UpdateVariants(ctx, alias, variants, sticky interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockURLs)(nil).UpdateVariants), ctx, alias, variants, sticky)
+}
+
+// MockWebhooks is a mock of Webhooks interface.
+type MockWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksMockRecorder
+}
+
+// MockWebhooksMockRecorder is the mock recorder for MockWebhooks.
+type MockWebhooksMockRecorder struct {
+	mock *MockWebhooks
+}
+
+// NewMockWebhooks creates a new mock instance.
+func NewMockWebhooks(ctrl *gomock.Controller) *MockWebhooks {
+	mock := &MockWebhooks{ctrl: ctrl}
+	mock.recorder = &MockWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooks) EXPECT() *MockWebhooksMockRecorder {
+	return m.recorder
+}
+
+// ClaimDeliveries mocks base method.
+func (m *MockWebhooks) ClaimDeliveries(ctx context.Context, now, lease time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDeliveries", ctx, now, lease, limit)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDeliveries indicates an expected call of ClaimDeliveries.
+func (mr *MockWebhooksMockRecorder) ClaimDeliveries(ctx, now, lease, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDeliveries", reflect.TypeOf((*MockWebhooks)(nil).ClaimDeliveries), ctx, now, lease, limit)
+}
+
+// Create mocks base method.
+func (m *MockWebhooks) Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, webhook)
+	ret0, _ := ret[0].(primitive.ObjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhooksMockRecorder) Create(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhooks)(nil).Create), ctx, webhook)
+}
+
+// CreateDelivery mocks base method.
+func (m *MockWebhooks) CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(primitive.ObjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateDelivery indicates an expected call of CreateDelivery.
+func (mr *MockWebhooksMockRecorder) CreateDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDelivery", reflect.TypeOf((*MockWebhooks)(nil).CreateDelivery), ctx, delivery)
+}
+
+// Delete mocks base method.
+func (m *MockWebhooks) Delete(ctx context.Context, id primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhooksMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhooks)(nil).Delete), ctx, id)
+}
+
+// Get mocks base method.
+func (m *MockWebhooks) Get(ctx context.Context, id primitive.ObjectID) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockWebhooksMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockWebhooks)(nil).Get), ctx, id)
+}
+
+// ListByOwner mocks base method.
+func (m *MockWebhooks) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwner", ctx, owner)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwner indicates an expected call of ListByOwner.
+func (mr *MockWebhooksMockRecorder) ListByOwner(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwner", reflect.TypeOf((*MockWebhooks)(nil).ListByOwner), ctx, owner)
+}
+
+// ListByOwnerAndEvent mocks base method.
+func (m *MockWebhooks) ListByOwnerAndEvent(ctx context.Context, owner primitive.ObjectID, event string) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwnerAndEvent", ctx, owner, event)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwnerAndEvent indicates an expected call of ListByOwnerAndEvent.
+func (mr *MockWebhooksMockRecorder) ListByOwnerAndEvent(ctx, owner, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwnerAndEvent", reflect.TypeOf((*MockWebhooks)(nil).ListByOwnerAndEvent), ctx, owner, event)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhooks) ListDeliveries(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, webhookId, limit)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhooksMockRecorder) ListDeliveries(ctx, webhookId, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhooks)(nil).ListDeliveries), ctx, webhookId, limit)
+}
+
+// UpdateDelivery mocks base method.
+func (m *MockWebhooks) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDelivery", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDelivery indicates an expected call of UpdateDelivery.
+func (mr *MockWebhooksMockRecorder) UpdateDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDelivery", reflect.TypeOf((*MockWebhooks)(nil).UpdateDelivery), ctx, delivery)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockAudit) Create(ctx context.Context, entry domain.AuditEntry) (primitive.ObjectID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, entry)
+	ret0, _ := ret[0].(primitive.ObjectID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAuditMockRecorder) Create(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAudit)(nil).Create), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockAudit) List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAudit)(nil).List), ctx, filter)
+}
+
+// MockStats is a mock of Stats interface.
+type MockStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsMockRecorder
+}
+
+// MockStatsMockRecorder is the mock recorder for MockStats.
+type MockStatsMockRecorder struct {
+	mock *MockStats
+}
+
+// NewMockStats creates a new mock instance.
+func NewMockStats(ctrl *gomock.Controller) *MockStats {
+	mock := &MockStats{ctrl: ctrl}
+	mock.recorder = &MockStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStats) EXPECT() *MockStatsMockRecorder {
+	return m.recorder
+}
+
+// AddClick mocks base method.
+func (m *MockStats) AddClick(ctx context.Context, hit domain.StatsHit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddClick", ctx, hit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddClick indicates an expected call of AddClick.
+func (mr *MockStatsMockRecorder) AddClick(ctx, hit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddClick", reflect.TypeOf((*MockStats)(nil).AddClick), ctx, hit)
+}
+
+// DeleteByAlias mocks base method.
+func (m *MockStats) DeleteByAlias(ctx context.Context, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByAlias", ctx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByAlias indicates an expected call of DeleteByAlias.
+func (mr *MockStatsMockRecorder) DeleteByAlias(ctx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByAlias", reflect.TypeOf((*MockStats)(nil).DeleteByAlias), ctx, alias)
+}
+
+// List mocks base method.
+func (m *MockStats) List(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.StatsRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockStatsMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockStats)(nil).List), ctx, filter)
+}
+
+// UpdateOwner mocks base method.
+func (m *MockStats) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOwner", ctx, alias, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOwner indicates an expected call of UpdateOwner.
+func (mr *MockStatsMockRecorder) UpdateOwner(ctx, alias, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOwner", reflect.TypeOf((*MockStats)(nil).UpdateOwner), ctx, alias, owner)
+}
+
+// MockTokens is a mock of Tokens interface.
+type MockTokens struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokensMockRecorder
+}
+
+// MockTokensMockRecorder is the mock recorder for MockTokens.
+type MockTokensMockRecorder struct {
+	mock *MockTokens
+}
+
+// NewMockTokens creates a new mock instance.
+func NewMockTokens(ctrl *gomock.Controller) *MockTokens {
+	mock := &MockTokens{ctrl: ctrl}
+	mock.recorder = &MockTokensMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokens) EXPECT() *MockTokensMockRecorder {
+	return m.recorder
+}
+
+// Consume mocks base method.
+func (m *MockTokens) Consume(ctx context.Context, hash, purpose string) (domain.UserToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Consume", ctx, hash, purpose)
+	ret0, _ := ret[0].(domain.UserToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Consume indicates an expected call of Consume.
+func (mr *MockTokensMockRecorder) Consume(ctx, hash, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Consume", reflect.TypeOf((*MockTokens)(nil).Consume), ctx, hash, purpose)
+}
+
+// Create mocks base method.
+func (m *MockTokens) Create(ctx context.Context, token domain.UserToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockTokensMockRecorder) Create(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockTokens)(nil).Create), ctx, token)
+}
+
+// DeleteByUser mocks base method.
+func (m *MockTokens) DeleteByUser(ctx context.Context, user primitive.ObjectID, purpose string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByUser", ctx, user, purpose)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByUser indicates an expected call of DeleteByUser.
+func (mr *MockTokensMockRecorder) DeleteByUser(ctx, user, purpose interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByUser", reflect.TypeOf((*MockTokens)(nil).DeleteByUser), ctx, user, purpose)
+}
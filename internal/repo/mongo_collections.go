@@ -1,6 +1,11 @@
 package repo
 
 const (
-	usersCollection = "users"
-	urlsCollection  = "urls"
+	usersCollection             = "users"
+	urlsCollection              = "urls"
+	webhooksCollection          = "webhooks"
+	webhookDeliveriesCollection = "webhookDeliveries"
+	auditCollection             = "audit"
+	statsCollection             = "stats"
+	tokensCollection            = "tokens"
 )
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+)
+
+// scanner is implemented by sql.Row and sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// jsonValue stores value as json column and scans it back, null column leaves value untouched
+type jsonValue struct {
+	v interface{}
+}
+
+func (j jsonValue) Value() (driver.Value, error) {
+	data, err := json.Marshal(j.v)
+
+	// Text is passed to avoid encoding of bytes as bytea
+	return string(data), err
+}
+
+func (j jsonValue) Scan(src interface{}) error {
+	switch data := src.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(data, j.v)
+	case string:
+		return json.Unmarshal([]byte(data), j.v)
+	default:
+		return errors.New("unsupported type of json column")
+	}
+}
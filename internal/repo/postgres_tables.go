@@ -0,0 +1,11 @@
+package repo
+
+const (
+	usersTable             = "users"
+	urlsTable              = "urls"
+	webhooksTable          = "webhooks"
+	webhookDeliveriesTable = "webhook_deliveries"
+	auditTable             = "audit"
+	statsTable             = "stats"
+	tokensTable            = "tokens"
+)
@@ -2,7 +2,9 @@ package repo
 
 import (
 	"context"
+	"database/sql"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"time"
@@ -13,28 +15,118 @@ import (
 type Users interface {
 	List(ctx context.Context) ([]domain.User, error)
 	Create(ctx context.Context, user domain.User) (primitive.ObjectID, error)
+	Get(ctx context.Context, id primitive.ObjectID) (domain.User, error)
 	GetByCredentials(ctx context.Context, email, password string) (domain.User, error)
+	GetByEmail(ctx context.Context, email string) (domain.User, error)
 	UpdateLastLogin(ctx context.Context, id primitive.ObjectID, lastLogin time.Time) error
+	UpdateName(ctx context.Context, id primitive.ObjectID, name string) error
+	UpdateEmail(ctx context.Context, id primitive.ObjectID, email string) error
+	// UpdatePassword revokes access tokens of user as well
+	UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error
+	// RevokeTokens moves user to next generation of access tokens, so issued ones are no longer accepted
+	RevokeTokens(ctx context.Context, id primitive.ObjectID) error
+	SetVerified(ctx context.Context, id primitive.ObjectID) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
 }
 
 type URLs interface {
 	ListByOwner(ctx context.Context, userId primitive.ObjectID) ([]domain.URL, error)
 	ListByOwnerAndExpiration(ctx context.Context, userId primitive.ObjectID, expired bool) ([]domain.URL, error)
+	ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error)
+	ListActive(ctx context.Context) ([]domain.URL, error)
+	ListNewlyExpired(ctx context.Context) ([]domain.URL, error)
+	CountByExpiration(ctx context.Context, expired bool) (int64, error)
+	Search(ctx context.Context, userId primitive.ObjectID, query string, limit int64) ([]domain.URLSearchResult, error)
 	Create(ctx context.Context, url domain.URL) (string, error)
 	Get(ctx context.Context, alias string) (domain.URL, error)
 	GetByOriginalAndOwner(ctx context.Context, original string, owner primitive.ObjectID) (domain.URL, error)
+	// Replace overwrites all fields of url with the same alias except owner, version is incremented
+	Replace(ctx context.Context, url domain.URL) error
 	Prolong(ctx context.Context, alias string, toProlong domain.URLProlong) error
+	UpdateMetadata(ctx context.Context, alias string, metadata domain.URLMetadata) error
+	UpdateHealth(ctx context.Context, alias string, health domain.URLHealth) error
+	UpdateRules(ctx context.Context, alias string, rules []domain.RedirectRule) error
+	UpdateVariants(ctx context.Context, alias string, variants []domain.Variant, sticky bool) error
+	UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error
+	CollapseVariants(ctx context.Context, alias string, original string) error
+	IncrementClicks(ctx context.Context, alias string, variant string) (int64, error)
+	SetExpirationNotified(ctx context.Context, alias string) error
 	Delete(ctx context.Context, alias string) error
 }
 
+type Webhooks interface {
+	ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error)
+	ListByOwnerAndEvent(ctx context.Context, owner primitive.ObjectID, event string) ([]domain.Webhook, error)
+	Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error)
+	Get(ctx context.Context, id primitive.ObjectID) (domain.Webhook, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	ListDeliveries(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error)
+	CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	// ClaimDeliveries returns pending deliveries due at now and postpones their next attempt to lease,
+	// so they are not claimed again until lease is over
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Time, limit int64) ([]domain.WebhookDelivery, error)
+}
+
+// Audit is append-only, entries are never changed or deleted
+type Audit interface {
+	List(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	Create(ctx context.Context, entry domain.AuditEntry) (primitive.ObjectID, error)
+}
+
+// Stats keeps clicks of every alias pre-aggregated by hour, so reading stays fast at any count of clicks
+type Stats interface {
+	AddClick(ctx context.Context, hit domain.StatsHit) error
+	List(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsRollup, error)
+	UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error
+	DeleteByAlias(ctx context.Context, alias string) error
+}
+
+// Tokens keeps hashes of single-use tokens of users, consumed token is deleted
+type Tokens interface {
+	Create(ctx context.Context, token domain.UserToken) error
+	Consume(ctx context.Context, hash string, purpose string) (domain.UserToken, error)
+	DeleteByUser(ctx context.Context, user primitive.ObjectID, purpose string) error
+}
+
 type Repos struct {
-	Users Users
-	URLs  URLs
+	Users    Users
+	URLs     URLs
+	Webhooks Webhooks
+	Audit    Audit
+	Stats    Stats
+	Tokens   Tokens
+}
+
+func NewMongoRepos(db *mongo.Database) *Repos {
+	return &Repos{
+		Users:    newUsersRepo(db),
+		URLs:     newURLsRepo(db),
+		Webhooks: newWebhooksRepo(db),
+		Audit:    newAuditRepo(db),
+		Stats:    newStatsRepo(db),
+		Tokens:   newTokensRepo(db),
+	}
+}
+
+func NewPostgresRepos(db *sql.DB) *Repos {
+	return &Repos{
+		Users:    newUsersPostgresRepo(db),
+		URLs:     newURLsPostgresRepo(db),
+		Webhooks: newWebhooksPostgresRepo(db),
+		Audit:    newAuditPostgresRepo(db),
+		Stats:    newStatsPostgresRepo(db),
+		Tokens:   newTokensPostgresRepo(db),
+	}
 }
 
-func NewRepos(db *mongo.Database) *Repos {
+func NewBoltRepos(db *bbolt.DB) *Repos {
 	return &Repos{
-		Users: newUsersRepo(db),
-		URLs:  newURLsRepo(db),
+		Users:    newUsersBoltRepo(db),
+		URLs:     newURLsBoltRepo(db),
+		Webhooks: newWebhooksBoltRepo(db),
+		Audit:    newAuditBoltRepo(db),
+		Stats:    newStatsBoltRepo(db),
+		Tokens:   newTokensBoltRepo(db),
 	}
 }
@@ -0,0 +1,788 @@
+package repo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/database/boltdb"
+	"github.com/mebr0/tiny-url/pkg/database/mongodb"
+	"github.com/mebr0/tiny-url/pkg/database/postgres"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"os"
+	"testing"
+	"time"
+)
+
+// Conformance of every storage backend is checked with the same suite.
+// Backends are skipped unless address of test database is set in environment,
+// except embedded one which needs only temporary directory
+
+func TestBoltRepos(t *testing.T) {
+	db, err := boltdb.NewClient(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	_, err = MigrateBolt(db)
+	require.NoError(t, err)
+
+	testRepos(t, NewBoltRepos(db))
+}
+
+func TestMongoRepos(t *testing.T) {
+	uri := os.Getenv("TEST_MONGO_URI")
+
+	if testing.Short() || uri == "" {
+		t.Skip("TEST_MONGO_URI is not set")
+	}
+
+	ctx := context.Background()
+
+	client, err := mongodb.NewClient(uri, "", "")
+	require.NoError(t, err)
+
+	db := client.Database(fmt.Sprintf("tiny_url_test_%d", time.Now().UnixNano()))
+
+	t.Cleanup(func() {
+		_ = db.Drop(ctx)
+		_ = client.Disconnect(ctx)
+	})
+
+	_, err = MigrateMongo(ctx, db)
+	require.NoError(t, err)
+
+	testRepos(t, NewMongoRepos(db))
+}
+
+func TestPostgresRepos(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+
+	if testing.Short() || dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+
+	ctx := context.Background()
+
+	db, err := postgres.NewClient(dsn)
+	require.NoError(t, err)
+
+	// Single connection keeps search path of separate schema for tables of test
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("tiny_url_test_%d", time.Now().UnixNano())
+
+	_, err = db.ExecContext(ctx, "CREATE SCHEMA "+schema+"; SET search_path TO "+schema)
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_, _ = db.ExecContext(ctx, "DROP SCHEMA "+schema+" CASCADE")
+		_ = db.Close()
+	})
+
+	_, err = MigratePostgres(ctx, db)
+	require.NoError(t, err)
+
+	testRepos(t, NewPostgresRepos(db))
+}
+
+func testRepos(t *testing.T, repos *Repos) {
+	t.Run("users", func(t *testing.T) {
+		testUsers(t, repos.Users)
+	})
+	t.Run("urls", func(t *testing.T) {
+		testURLs(t, repos.URLs)
+	})
+	t.Run("webhooks", func(t *testing.T) {
+		testWebhooks(t, repos.Webhooks)
+	})
+	t.Run("audit", func(t *testing.T) {
+		testAudit(t, repos.Audit)
+	})
+	t.Run("stats", func(t *testing.T) {
+		testStats(t, repos.Stats)
+	})
+	t.Run("tokens", func(t *testing.T) {
+		testTokens(t, repos.Tokens, repos.Users)
+	})
+}
+
+func testUsers(t *testing.T, repo Users) {
+	ctx := context.Background()
+	now := time.Now()
+
+	user := domain.User{
+		Name:         "Sirius",
+		Email:        "sirius@gmail.com",
+		Password:     "hash",
+		RegisteredAt: now,
+		LastLogin:    now,
+	}
+
+	id, err := repo.Create(ctx, user)
+	require.NoError(t, err)
+	require.False(t, id.IsZero())
+
+	_, err = repo.Create(ctx, user)
+	require.ErrorIs(t, err, ErrUserAlreadyExists)
+
+	found, err := repo.GetByCredentials(ctx, user.Email, user.Password)
+	require.NoError(t, err)
+
+	user.ID = id
+	require.Equal(t, normalizeUser(user), normalizeUser(found))
+
+	found, err = repo.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, normalizeUser(user), normalizeUser(found))
+
+	_, err = repo.Get(ctx, primitive.NewObjectID())
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	_, err = repo.GetByCredentials(ctx, user.Email, "wrong")
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	found, err = repo.GetByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	require.Equal(t, normalizeUser(user), normalizeUser(found))
+
+	_, err = repo.GetByEmail(ctx, "regulus@gmail.com")
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	lastLogin := now.Add(time.Hour)
+	require.NoError(t, repo.UpdateLastLogin(ctx, id, lastLogin))
+	require.NoError(t, repo.UpdatePassword(ctx, id, "new hash"))
+	require.NoError(t, repo.SetVerified(ctx, id))
+
+	users, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.WithinDuration(t, lastLogin, users[0].LastLogin, time.Millisecond)
+	require.Equal(t, "new hash", users[0].Password)
+	require.True(t, users[0].Verified)
+	// Changed password revokes access tokens
+	require.Equal(t, int64(1), users[0].TokenGeneration)
+
+	require.NoError(t, repo.RevokeTokens(ctx, id))
+
+	found, err = repo.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), found.TokenGeneration)
+
+	otherId, err := repo.Create(ctx, domain.User{Name: "Orion", Email: "orion@gmail.com", RegisteredAt: now, LastLogin: now})
+	require.NoError(t, err)
+
+	require.ErrorIs(t, repo.UpdateEmail(ctx, id, "orion@gmail.com"), ErrUserAlreadyExists)
+	require.NoError(t, repo.UpdateEmail(ctx, id, "padfoot@gmail.com"))
+	require.NoError(t, repo.UpdateName(ctx, id, "Padfoot"))
+
+	found, err = repo.GetByEmail(ctx, "padfoot@gmail.com")
+	require.NoError(t, err)
+	require.Equal(t, id, found.ID)
+	require.Equal(t, "Padfoot", found.Name)
+
+	_, err = repo.GetByEmail(ctx, user.Email)
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	require.NoError(t, repo.Delete(ctx, otherId))
+
+	_, err = repo.Get(ctx, otherId)
+	require.ErrorIs(t, err, ErrUserNotFound)
+
+	// Email of deleted user is free
+	_, err = repo.GetByEmail(ctx, "orion@gmail.com")
+	require.ErrorIs(t, err, ErrUserNotFound)
+	require.NoError(t, repo.UpdateEmail(ctx, id, "orion@gmail.com"))
+}
+
+func testURLs(t *testing.T, repo URLs) {
+	ctx := context.Background()
+	now := time.Now()
+	owner := primitive.NewObjectID()
+
+	active := domain.URL{
+		Alias:     "docs",
+		Original:  "https://docs.example.com/start",
+		CreatedAt: now,
+		ExpiredAt: now.Add(time.Hour),
+		Owner:     owner,
+		Tags:      []string{"guide"},
+		Variants: []domain.Variant{
+			{Name: "a", Destination: "https://docs.example.com/a", Weight: 1},
+			{Name: "b", Destination: "https://docs.example.com/b", Weight: 1},
+		},
+	}
+
+	expired := domain.URL{
+		Alias:     "blog",
+		Original:  "https://blog.example.com",
+		CreatedAt: now,
+		ExpiredAt: now.Add(-time.Hour),
+		Owner:     owner,
+	}
+
+	for _, url := range []domain.URL{active, expired} {
+		alias, err := repo.Create(ctx, url)
+		require.NoError(t, err)
+		require.Equal(t, url.Alias, alias)
+	}
+
+	_, err := repo.Create(ctx, active)
+	require.ErrorIs(t, err, ErrURLAlreadyExists)
+
+	t.Run("get", func(t *testing.T) {
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, normalizeURL(active), normalizeURL(found))
+
+		found, err = repo.GetByOriginalAndOwner(ctx, expired.Original, owner)
+		require.NoError(t, err)
+		require.Equal(t, expired.Alias, found.Alias)
+
+		_, err = repo.Get(ctx, "missing")
+		require.ErrorIs(t, err, ErrURLNotFound)
+
+		_, err = repo.GetByOriginalAndOwner(ctx, expired.Original, primitive.NewObjectID())
+		require.ErrorIs(t, err, ErrURLNotFound)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		urls, err := repo.ListByOwner(ctx, owner)
+		require.NoError(t, err)
+		require.ElementsMatch(t, []string{active.Alias, expired.Alias}, aliases(urls))
+
+		urls, err = repo.ListByOwnerAndExpiration(ctx, owner, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{expired.Alias}, aliases(urls))
+
+		urls, err = repo.ListByOwnerAndExpiration(ctx, owner, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{active.Alias}, aliases(urls))
+
+		urls, err = repo.ListActive(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{active.Alias}, aliases(urls))
+
+		count, err := repo.CountByExpiration(ctx, false)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		count, err = repo.CountByExpiration(ctx, true)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+
+		urls, err = repo.ListByOwner(ctx, primitive.NewObjectID())
+		require.NoError(t, err)
+		require.Empty(t, urls)
+	})
+
+	t.Run("health", func(t *testing.T) {
+		require.NoError(t, repo.UpdateHealth(ctx, expired.Alias, domain.URLHealth{StatusCode: 500, Broken: true, CheckedAt: now}))
+
+		urls, err := repo.ListByOwnerAndHealth(ctx, owner, true)
+		require.NoError(t, err)
+		require.Equal(t, []string{expired.Alias}, aliases(urls))
+
+		// Never checked url is healthy
+		urls, err = repo.ListByOwnerAndHealth(ctx, owner, false)
+		require.NoError(t, err)
+		require.Equal(t, []string{active.Alias}, aliases(urls))
+	})
+
+	t.Run("metadata and rules", func(t *testing.T) {
+		metadata := domain.URLMetadata{Title: "Getting started", FetchedAt: now}
+		rules := []domain.RedirectRule{{OS: "ios", Destination: "https://apps.apple.com"}}
+
+		require.NoError(t, repo.UpdateMetadata(ctx, active.Alias, metadata))
+		require.NoError(t, repo.UpdateRules(ctx, active.Alias, rules))
+
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, metadata.Title, found.Metadata.Title)
+		require.WithinDuration(t, metadata.FetchedAt, found.Metadata.FetchedAt, time.Millisecond)
+		require.Equal(t, rules, found.Rules)
+		require.Equal(t, active.Version+2, found.Version)
+	})
+
+	t.Run("search", func(t *testing.T) {
+		results, err := repo.Search(ctx, owner, "started", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		require.Equal(t, active.Alias, results[0].URL.Alias)
+		require.Greater(t, results[0].Score, 0.0)
+
+		// Any of terms is enough for match
+		results, err = repo.Search(ctx, owner, "guide blog", 10)
+		require.NoError(t, err)
+		require.Len(t, results, 2)
+
+		results, err = repo.Search(ctx, primitive.NewObjectID(), "guide", 10)
+		require.NoError(t, err)
+		require.Empty(t, results)
+	})
+
+	t.Run("clicks", func(t *testing.T) {
+		clicks, err := repo.IncrementClicks(ctx, active.Alias, "b")
+		require.NoError(t, err)
+		require.Equal(t, int64(1), clicks)
+
+		clicks, err = repo.IncrementClicks(ctx, active.Alias, "removed")
+		require.NoError(t, err)
+		require.Equal(t, int64(2), clicks)
+
+		clicks, err = repo.IncrementClicks(ctx, active.Alias, "")
+		require.NoError(t, err)
+		require.Equal(t, int64(3), clicks)
+
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, int64(0), found.Variants[0].Clicks)
+		require.Equal(t, int64(1), found.Variants[1].Clicks)
+
+		// Counting clicks is not a change of url
+		require.Equal(t, active.Version+2, found.Version)
+
+		_, err = repo.IncrementClicks(ctx, "missing", "")
+		require.ErrorIs(t, err, ErrURLNotFound)
+	})
+
+	t.Run("variants", func(t *testing.T) {
+		variants := []domain.Variant{{Name: "c", Destination: "https://docs.example.com/c", Weight: 3}}
+
+		require.NoError(t, repo.UpdateVariants(ctx, active.Alias, variants, true))
+
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, variants, found.Variants)
+		require.True(t, found.StickyVariants)
+
+		require.NoError(t, repo.CollapseVariants(ctx, active.Alias, "https://docs.example.com/c"))
+
+		found, err = repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, "https://docs.example.com/c", found.Original)
+		require.Empty(t, found.Variants)
+		require.False(t, found.StickyVariants)
+
+		// Click on variant of url cached before collapsing is counted in total
+		clicks, err := repo.IncrementClicks(ctx, active.Alias, "c")
+		require.NoError(t, err)
+		require.Equal(t, found.Clicks+1, clicks)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		before, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+
+		replacement := domain.URL{
+			Alias:     active.Alias,
+			Original:  "https://docs.example.com/imported",
+			CreatedAt: now.Add(-time.Hour),
+			ExpiredAt: now.Add(2 * time.Hour),
+			Owner:     primitive.NewObjectID(),
+			Metadata:  domain.URLMetadata{Title: "Imported"},
+			Clicks:    7,
+		}
+
+		require.NoError(t, repo.Replace(ctx, replacement))
+
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, replacement.Original, found.Original)
+		require.WithinDuration(t, replacement.ExpiredAt, found.ExpiredAt, time.Millisecond)
+		require.Equal(t, "Imported", found.Metadata.Title)
+		require.Equal(t, int64(7), found.Clicks)
+		require.Empty(t, found.Tags)
+		require.Empty(t, found.Rules)
+
+		// Owner is never changed by replacement
+		require.Equal(t, owner, found.Owner)
+		require.Equal(t, before.Version+1, found.Version)
+	})
+
+	t.Run("owner", func(t *testing.T) {
+		before, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+
+		successor := primitive.NewObjectID()
+		require.NoError(t, repo.UpdateOwner(ctx, active.Alias, successor))
+
+		found, err := repo.Get(ctx, active.Alias)
+		require.NoError(t, err)
+		require.Equal(t, successor, found.Owner)
+		require.Equal(t, before.Version+1, found.Version)
+
+		urls, err := repo.ListByOwner(ctx, successor)
+		require.NoError(t, err)
+		require.Equal(t, []string{active.Alias}, aliases(urls))
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		urls, err := repo.ListNewlyExpired(ctx)
+		require.NoError(t, err)
+		require.Equal(t, []string{expired.Alias}, aliases(urls))
+
+		require.NoError(t, repo.SetExpirationNotified(ctx, expired.Alias))
+
+		urls, err = repo.ListNewlyExpired(ctx)
+		require.NoError(t, err)
+		require.Empty(t, urls)
+
+		require.NoError(t, repo.Prolong(ctx, expired.Alias, domain.URLProlong{Duration: 60}))
+
+		found, err := repo.Get(ctx, expired.Alias)
+		require.NoError(t, err)
+		require.False(t, found.Expired())
+		require.False(t, found.ExpirationNotified)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, expired.Alias))
+
+		_, err := repo.Get(ctx, expired.Alias)
+		require.ErrorIs(t, err, ErrURLNotFound)
+	})
+}
+
+func testWebhooks(t *testing.T, repo Webhooks) {
+	ctx := context.Background()
+	now := time.Now()
+	owner := primitive.NewObjectID()
+
+	webhook := domain.Webhook{
+		URL:       "https://example.com/hooks",
+		Secret:    "0123456789abcdef",
+		Events:    []string{domain.EventURLCreated, domain.EventURLDeleted},
+		CreatedAt: now,
+		Owner:     owner,
+	}
+
+	id, err := repo.Create(ctx, webhook)
+	require.NoError(t, err)
+
+	webhook.ID = id
+
+	found, err := repo.Get(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, normalizeWebhook(webhook), normalizeWebhook(found))
+
+	_, err = repo.Get(ctx, primitive.NewObjectID())
+	require.ErrorIs(t, err, ErrWebhookNotFound)
+
+	webhooks, err := repo.ListByOwner(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+
+	webhooks, err = repo.ListByOwnerAndEvent(ctx, owner, domain.EventURLDeleted)
+	require.NoError(t, err)
+	require.Len(t, webhooks, 1)
+
+	webhooks, err = repo.ListByOwnerAndEvent(ctx, owner, domain.EventURLExpired)
+	require.NoError(t, err)
+	require.Empty(t, webhooks)
+
+	for i := 0; i < 3; i++ {
+		_, err := repo.CreateDelivery(ctx, domain.WebhookDelivery{
+			Webhook:   id,
+			Event:     domain.EventURLCreated,
+			Payload:   fmt.Sprintf(`{"n":%d}`, i),
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+		})
+		require.NoError(t, err)
+	}
+
+	// The latest deliveries go first
+	deliveries, err := repo.ListDeliveries(ctx, id, 2)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, `{"n":2}`, deliveries[0].Payload)
+	require.Equal(t, `{"n":1}`, deliveries[1].Payload)
+
+	delivery := deliveries[0]
+	delivery.Attempts = 1
+	delivery.StatusCode = 200
+	delivery.Delivered = true
+	delivery.AttemptedAt = now
+
+	require.NoError(t, repo.UpdateDelivery(ctx, delivery))
+
+	deliveries, err = repo.ListDeliveries(ctx, id, 1)
+	require.NoError(t, err)
+	require.Equal(t, normalizeDelivery(delivery), normalizeDelivery(deliveries[0]))
+
+	// Only pending deliveries due at time of claim are claimed, until their lease is over
+	for i, next := range []time.Time{now.Add(-time.Second), now.Add(time.Hour)} {
+		_, err := repo.CreateDelivery(ctx, domain.WebhookDelivery{
+			Webhook:       id,
+			Event:         domain.EventURLDeleted,
+			Payload:       fmt.Sprintf(`{"pending":%d}`, i),
+			CreatedAt:     now,
+			Pending:       true,
+			NextAttemptAt: next,
+		})
+		require.NoError(t, err)
+	}
+
+	claimed, err := repo.ClaimDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	require.Equal(t, `{"pending":0}`, claimed[0].Payload)
+
+	claimed, err = repo.ClaimDeliveries(ctx, now, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	claimed, err = repo.ClaimDeliveries(ctx, now.Add(2*time.Minute), now.Add(3*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	delivery = claimed[0]
+	delivery.Attempts = 1
+	delivery.Delivered = true
+	delivery.Pending = false
+
+	require.NoError(t, repo.UpdateDelivery(ctx, delivery))
+
+	claimed, err = repo.ClaimDeliveries(ctx, now.Add(4*time.Minute), now.Add(5*time.Minute), 10)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+
+	require.NoError(t, repo.Delete(ctx, id))
+
+	deliveries, err = repo.ListDeliveries(ctx, id, 10)
+	require.NoError(t, err)
+	require.Empty(t, deliveries)
+}
+
+func testAudit(t *testing.T, repo Audit) {
+	ctx := context.Background()
+	// Bounds of filter are compared with stored time, so it is kept at precision of every backend
+	now := time.Now().Truncate(time.Millisecond)
+	actor := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+
+	entry := domain.AuditEntry{
+		Actor:     actor,
+		Action:    domain.AuditURLProlonged,
+		Target:    "qwerty",
+		IP:        "127.0.0.1",
+		UserAgent: "Mozilla/5.0",
+		CreatedAt: now,
+		Changes: []domain.AuditChange{
+			{Field: "expiredAt", Before: json.RawMessage(`"2021-05-09T09:29:18Z"`), After: json.RawMessage(`"2021-06-09T09:29:18Z"`)},
+			{Field: "tags", After: json.RawMessage(`["docs"]`)},
+		},
+	}
+
+	id, err := repo.Create(ctx, entry)
+	require.NoError(t, err)
+	require.False(t, id.IsZero())
+
+	entry.ID = id
+
+	for i, action := range []string{domain.AuditURLCreated, domain.AuditURLDeleted} {
+		_, err := repo.Create(ctx, domain.AuditEntry{
+			Actor:     other,
+			Action:    action,
+			Target:    "asdfgh",
+			CreatedAt: now.Add(time.Duration(i+1) * time.Second),
+			Changes:   []domain.AuditChange{},
+		})
+		require.NoError(t, err)
+	}
+
+	entries, err := repo.List(ctx, domain.AuditFilter{Actor: actor, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, normalizeAuditEntry(entry), normalizeAuditEntry(entries[0]))
+
+	// The latest entries go first
+	entries, err = repo.List(ctx, domain.AuditFilter{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, domain.AuditURLDeleted, entries[0].Action)
+	require.Equal(t, domain.AuditURLCreated, entries[1].Action)
+
+	entries, err = repo.List(ctx, domain.AuditFilter{Action: domain.AuditURLCreated, Target: "asdfgh", Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	entries, err = repo.List(ctx, domain.AuditFilter{From: now.Add(time.Second), To: now.Add(2 * time.Second), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, domain.AuditURLCreated, entries[0].Action)
+}
+
+func testStats(t *testing.T, repo Stats) {
+	ctx := context.Background()
+	hour := time.Date(2021, 5, 9, 9, 0, 0, 0, time.UTC)
+	owner := primitive.NewObjectID()
+
+	hits := []domain.StatsHit{
+		{Alias: "qwerty", Owner: owner, Hour: hour, Referrer: "google.com", Country: "KZ", Browser: "chrome",
+			OS: "android", VisitorRegister: 7, VisitorRank: 2},
+		{Alias: "qwerty", Owner: owner, Hour: hour, Referrer: "google.com", Country: "US", Browser: "chrome",
+			OS: "windows", VisitorRegister: 7, VisitorRank: 5},
+		{Alias: "qwerty", Owner: owner, Hour: hour.Add(time.Hour), Referrer: domain.ReferrerDirect,
+			Country: domain.CountryUnknown, Browser: "firefox", OS: "linux", VisitorRegister: 1, VisitorRank: 1},
+		{Alias: "asdfgh", Owner: owner, Hour: hour, Referrer: "$ref.example.com", Country: "KZ", Browser: "safari",
+			OS: "ios", VisitorRegister: 3, VisitorRank: 4},
+		{Alias: "zxcvbn", Owner: primitive.NewObjectID(), Hour: hour, Referrer: domain.ReferrerDirect,
+			Country: "KZ", Browser: "chrome", OS: "windows", VisitorRegister: 3, VisitorRank: 4},
+		// Requests of bots are only counted
+		{Alias: "qwerty", Owner: owner, Hour: hour, Bot: true},
+	}
+
+	for _, hit := range hits {
+		require.NoError(t, repo.AddClick(ctx, hit))
+	}
+
+	rollups, err := repo.List(ctx, domain.StatsFilter{Owner: owner, Alias: "qwerty", From: hour, To: hour.Add(time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+
+	rollups[0].Hour = rollups[0].Hour.UTC()
+	require.Equal(t, domain.StatsRollup{
+		Alias:     "qwerty",
+		Owner:     owner,
+		Hour:      hour,
+		Clicks:    2,
+		Bots:      1,
+		Referrers: map[string]int64{"google.com": 2},
+		Countries: map[string]int64{"KZ": 1, "US": 1},
+		Browsers:  map[string]int64{"chrome": 2},
+		OS:        map[string]int64{"android": 1, "windows": 1},
+		Visitors:  map[string]uint8{"7": 5},
+	}, rollups[0])
+
+	// Rollups of every url of owner go by hour
+	rollups, err = repo.List(ctx, domain.StatsFilter{Owner: owner, From: hour, To: hour.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, rollups, 3)
+	require.Equal(t, "asdfgh", rollups[0].Alias)
+	require.Equal(t, map[string]int64{"$ref.example.com": 1}, rollups[0].Referrers)
+	require.Equal(t, "qwerty", rollups[1].Alias)
+	require.Equal(t, "qwerty", rollups[2].Alias)
+	require.True(t, hour.Add(time.Hour).Equal(rollups[2].Hour))
+
+	require.NoError(t, repo.DeleteByAlias(ctx, "qwerty"))
+
+	rollups, err = repo.List(ctx, domain.StatsFilter{Owner: owner, From: hour, To: hour.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+	require.Equal(t, "asdfgh", rollups[0].Alias)
+
+	successor := primitive.NewObjectID()
+	require.NoError(t, repo.UpdateOwner(ctx, "asdfgh", successor))
+
+	rollups, err = repo.List(ctx, domain.StatsFilter{Owner: owner, From: hour, To: hour.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Empty(t, rollups)
+
+	rollups, err = repo.List(ctx, domain.StatsFilter{Owner: successor, Alias: "asdfgh", From: hour, To: hour.Add(2 * time.Hour)})
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+	require.Equal(t, int64(1), rollups[0].Clicks)
+}
+
+func aliases(urls []domain.URL) []string {
+	result := make([]string, 0, len(urls))
+
+	for _, url := range urls {
+		result = append(result, url.Alias)
+	}
+
+	return result
+}
+
+// normalizeTime drops precision and location not kept by every backend
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Millisecond)
+}
+
+func normalizeUser(user domain.User) domain.User {
+	user.RegisteredAt = normalizeTime(user.RegisteredAt)
+	user.LastLogin = normalizeTime(user.LastLogin)
+
+	return user
+}
+
+func normalizeURL(url domain.URL) domain.URL {
+	url.CreatedAt = normalizeTime(url.CreatedAt)
+	url.ExpiredAt = normalizeTime(url.ExpiredAt)
+	url.Metadata.FetchedAt = normalizeTime(url.Metadata.FetchedAt)
+	url.Health.CheckedAt = normalizeTime(url.Health.CheckedAt)
+
+	return url
+}
+
+func normalizeWebhook(webhook domain.Webhook) domain.Webhook {
+	webhook.CreatedAt = normalizeTime(webhook.CreatedAt)
+
+	return webhook
+}
+
+func normalizeAuditEntry(entry domain.AuditEntry) domain.AuditEntry {
+	entry.CreatedAt = normalizeTime(entry.CreatedAt)
+
+	return entry
+}
+
+func normalizeDelivery(delivery domain.WebhookDelivery) domain.WebhookDelivery {
+	delivery.CreatedAt = normalizeTime(delivery.CreatedAt)
+	delivery.AttemptedAt = normalizeTime(delivery.AttemptedAt)
+	delivery.NextAttemptAt = normalizeTime(delivery.NextAttemptAt)
+
+	return delivery
+}
+
+func testTokens(t *testing.T, repo Tokens, users Users) {
+	ctx := context.Background()
+	now := time.Now()
+
+	userId, err := users.Create(ctx, domain.User{Name: "Regulus", Email: "regulus@gmail.com", RegisteredAt: now, LastLogin: now})
+	require.NoError(t, err)
+
+	token := domain.UserToken{
+		Hash:      "hash1",
+		User:      userId,
+		Purpose:   domain.TokenPasswordReset,
+		Email:     "regulus@gmail.com",
+		CreatedAt: now,
+		ExpiredAt: now.Add(time.Hour),
+	}
+
+	require.NoError(t, repo.Create(ctx, token))
+	require.NoError(t, repo.Create(ctx, domain.UserToken{
+		Hash: "hash2", User: userId, Purpose: domain.TokenPasswordReset, CreatedAt: now, ExpiredAt: now.Add(time.Hour),
+	}))
+	require.NoError(t, repo.Create(ctx, domain.UserToken{
+		Hash: "hash3", User: userId, Purpose: domain.TokenVerification, CreatedAt: now, ExpiredAt: now.Add(time.Hour),
+	}))
+
+	// Token of other purpose is not consumed
+	_, err = repo.Consume(ctx, "hash1", domain.TokenVerification)
+	require.ErrorIs(t, err, ErrTokenNotFound)
+
+	found, err := repo.Consume(ctx, "hash1", domain.TokenPasswordReset)
+	require.NoError(t, err)
+	require.Equal(t, token.User, found.User)
+	require.Equal(t, token.Purpose, found.Purpose)
+	require.Equal(t, token.Email, found.Email)
+	require.WithinDuration(t, token.ExpiredAt, found.ExpiredAt, time.Millisecond)
+
+	// Tokens are single-use
+	_, err = repo.Consume(ctx, "hash1", domain.TokenPasswordReset)
+	require.ErrorIs(t, err, ErrTokenNotFound)
+
+	require.NoError(t, repo.DeleteByUser(ctx, userId, domain.TokenPasswordReset))
+
+	_, err = repo.Consume(ctx, "hash2", domain.TokenPasswordReset)
+	require.ErrorIs(t, err, ErrTokenNotFound)
+
+	_, err = repo.Consume(ctx, "hash3", domain.TokenVerification)
+	require.NoError(t, err)
+}
@@ -0,0 +1,166 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StatsBoltRepo keeps rollups by alias and hour, so rollups of one alias are read by range of keys
+type StatsBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newStatsBoltRepo(db *bbolt.DB) *StatsBoltRepo {
+	return &StatsBoltRepo{
+		db: db,
+	}
+}
+
+func (r *StatsBoltRepo) AddClick(ctx context.Context, hit domain.StatsHit) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(statsBucket)
+		key := statsKey(hit.Alias, hit.Hour)
+
+		rollup := domain.StatsRollup{
+			Alias:     hit.Alias,
+			Owner:     hit.Owner,
+			Hour:      hit.Hour,
+			Referrers: map[string]int64{},
+			Countries: map[string]int64{},
+			Browsers:  map[string]int64{},
+			OS:        map[string]int64{},
+			Visitors:  map[string]uint8{},
+		}
+
+		if _, err := getValue(b, key, &rollup); err != nil {
+			return err
+		}
+
+		if hit.Bot {
+			rollup.Bots++
+
+			return putValue(b, key, rollup)
+		}
+
+		rollup.Clicks++
+		rollup.Referrers[hit.Referrer]++
+		rollup.Countries[hit.Country]++
+		rollup.Browsers[hit.Browser]++
+		rollup.OS[hit.OS]++
+
+		register := strconv.Itoa(hit.VisitorRegister)
+
+		if hit.VisitorRank > rollup.Visitors[register] {
+			rollup.Visitors[register] = hit.VisitorRank
+		}
+
+		return putValue(b, key, rollup)
+	})
+}
+
+func (r *StatsBoltRepo) List(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsRollup, error) {
+	rollups := make([]domain.StatsRollup, 0)
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(statsBucket).Cursor()
+
+		var prefix []byte
+
+		if filter.Alias != "" {
+			prefix = []byte(filter.Alias + "\x00")
+		}
+
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var rollup domain.StatsRollup
+
+			if err := bson.Unmarshal(v, &rollup); err != nil {
+				return err
+			}
+
+			if rollup.Owner == filter.Owner && !rollup.Hour.Before(filter.From) && rollup.Hour.Before(filter.To) {
+				rollups = append(rollups, rollup)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(rollups, func(i, j int) bool {
+		if rollups[i].Hour.Equal(rollups[j].Hour) {
+			return rollups[i].Alias < rollups[j].Alias
+		}
+
+		return rollups[i].Hour.Before(rollups[j].Hour)
+	})
+
+	return rollups, nil
+}
+
+func (r *StatsBoltRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		stats := tx.Bucket(statsBucket)
+		prefix := []byte(alias + "\x00")
+
+		var rollups []domain.StatsRollup
+
+		c := stats.Cursor()
+
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			var rollup domain.StatsRollup
+
+			if err := bson.Unmarshal(v, &rollup); err != nil {
+				return err
+			}
+
+			rollups = append(rollups, rollup)
+		}
+
+		// Bucket must not be changed during iteration
+		for _, rollup := range rollups {
+			rollup.Owner = owner
+
+			if err := putValue(stats, statsKey(alias, rollup.Hour), rollup); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *StatsBoltRepo) DeleteByAlias(ctx context.Context, alias string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		c := tx.Bucket(statsBucket).Cursor()
+		prefix := []byte(alias + "\x00")
+
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Seek(prefix) {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// statsKey orders rollups of alias by hour
+func statsKey(alias string, hour time.Time) []byte {
+	key := make([]byte, len(alias)+9)
+
+	copy(key, alias)
+	binary.BigEndian.PutUint64(key[len(alias)+1:], uint64(hour.Unix()))
+
+	return key
+}
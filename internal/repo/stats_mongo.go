@@ -0,0 +1,115 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"strconv"
+	"strings"
+)
+
+// Field names can not contain dots or start with dollar, so such characters of breakdown values are replaced with
+// their full width forms
+var (
+	statsKeyEscaper   = strings.NewReplacer(".", "．", "$", "＄")
+	statsKeyUnescaper = strings.NewReplacer("．", ".", "＄", "$")
+)
+
+type StatsRepo struct {
+	db *mongo.Collection
+}
+
+func newStatsRepo(db *mongo.Database) *StatsRepo {
+	return &StatsRepo{
+		db: db.Collection(statsCollection),
+	}
+}
+
+func (r *StatsRepo) AddClick(ctx context.Context, hit domain.StatsHit) error {
+	filter := bson.M{"alias": hit.Alias, "hour": hit.Hour}
+	opts := options.Update().SetUpsert(true)
+
+	if hit.Bot {
+		_, err := r.db.UpdateOne(ctx, filter, bson.M{
+			"$setOnInsert": bson.M{"owner": hit.Owner},
+			"$inc":         bson.M{"bots": 1},
+		}, opts)
+
+		return err
+	}
+
+	update := bson.M{
+		"$setOnInsert": bson.M{"owner": hit.Owner},
+		"$inc": bson.M{
+			"clicks": 1,
+			"referrers." + statsKeyEscaper.Replace(hit.Referrer): 1,
+			"countries." + statsKeyEscaper.Replace(hit.Country):  1,
+			"browsers." + statsKeyEscaper.Replace(hit.Browser):   1,
+			"os." + statsKeyEscaper.Replace(hit.OS):              1,
+		},
+		"$max": bson.M{"visitors." + strconv.Itoa(hit.VisitorRegister): hit.VisitorRank},
+	}
+
+	_, err := r.db.UpdateOne(ctx, filter, update, opts)
+
+	return err
+}
+
+func (r *StatsRepo) List(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsRollup, error) {
+	rollups := make([]domain.StatsRollup, 0)
+
+	query := bson.M{
+		"owner": filter.Owner,
+		"hour":  bson.M{"$gte": filter.From, "$lt": filter.To},
+	}
+
+	if filter.Alias != "" {
+		query["alias"] = filter.Alias
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "hour", Value: 1}, {Key: "alias", Value: 1}})
+
+	cur, err := r.db.Find(ctx, query, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = cur.All(ctx, &rollups); err != nil {
+		return nil, err
+	}
+
+	for i := range rollups {
+		rollups[i].Referrers = unescapeStatsKeys(rollups[i].Referrers)
+		rollups[i].Countries = unescapeStatsKeys(rollups[i].Countries)
+		rollups[i].Browsers = unescapeStatsKeys(rollups[i].Browsers)
+		rollups[i].OS = unescapeStatsKeys(rollups[i].OS)
+	}
+
+	return rollups, nil
+}
+
+func (r *StatsRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	_, err := r.db.UpdateMany(ctx, bson.M{"alias": alias}, bson.M{"$set": bson.M{"owner": owner}})
+
+	return err
+}
+
+func (r *StatsRepo) DeleteByAlias(ctx context.Context, alias string) error {
+	_, err := r.db.DeleteMany(ctx, bson.M{"alias": alias})
+
+	return err
+}
+
+func unescapeStatsKeys(counts map[string]int64) map[string]int64 {
+	unescaped := make(map[string]int64, len(counts))
+
+	for key, count := range counts {
+		unescaped[statsKeyUnescaper.Replace(key)] += count
+	}
+
+	return unescaped
+}
@@ -0,0 +1,112 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strconv"
+)
+
+const statsColumns = "alias, owner, hour, clicks, referrers, countries, browsers, os, visitors, bots"
+
+type StatsPostgresRepo struct {
+	db *sql.DB
+}
+
+func newStatsPostgresRepo(db *sql.DB) *StatsPostgresRepo {
+	return &StatsPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *StatsPostgresRepo) AddClick(ctx context.Context, hit domain.StatsHit) error {
+	if hit.Bot {
+		_, err := r.db.ExecContext(ctx, `INSERT INTO `+statsTable+` (alias, owner, hour, bots) VALUES ($1, $2, $3, 1)
+			ON CONFLICT (alias, hour) DO UPDATE SET bots = `+statsTable+`.bots + 1`,
+			hit.Alias, hit.Owner.Hex(), hit.Hour)
+
+		return err
+	}
+
+	_, err := r.db.ExecContext(ctx, `INSERT INTO `+statsTable+` (`+statsColumns+`)
+		VALUES ($1, $2, $3, 1, jsonb_build_object($4::text, 1), jsonb_build_object($5::text, 1),
+			jsonb_build_object($6::text, 1), jsonb_build_object($7::text, 1), jsonb_build_object($8::text, $9::int), 0)
+		ON CONFLICT (alias, hour) DO UPDATE SET clicks = `+statsTable+`.clicks + 1,
+			referrers = jsonb_set(`+statsTable+`.referrers, ARRAY[$4::text],
+				to_jsonb(coalesce((`+statsTable+`.referrers->>$4::text)::bigint, 0) + 1)),
+			countries = jsonb_set(`+statsTable+`.countries, ARRAY[$5::text],
+				to_jsonb(coalesce((`+statsTable+`.countries->>$5::text)::bigint, 0) + 1)),
+			browsers = jsonb_set(`+statsTable+`.browsers, ARRAY[$6::text],
+				to_jsonb(coalesce((`+statsTable+`.browsers->>$6::text)::bigint, 0) + 1)),
+			os = jsonb_set(`+statsTable+`.os, ARRAY[$7::text],
+				to_jsonb(coalesce((`+statsTable+`.os->>$7::text)::bigint, 0) + 1)),
+			visitors = jsonb_set(`+statsTable+`.visitors, ARRAY[$8::text],
+				to_jsonb(greatest(coalesce((`+statsTable+`.visitors->>$8::text)::int, 0), $9::int)))`,
+		hit.Alias, hit.Owner.Hex(), hit.Hour, hit.Referrer, hit.Country, hit.Browser, hit.OS,
+		strconv.Itoa(hit.VisitorRegister), int(hit.VisitorRank))
+
+	return err
+}
+
+func (r *StatsPostgresRepo) List(ctx context.Context, filter domain.StatsFilter) ([]domain.StatsRollup, error) {
+	query := "SELECT " + statsColumns + " FROM " + statsTable + " WHERE owner = $1 AND hour >= $2 AND hour < $3"
+	args := []interface{}{filter.Owner.Hex(), filter.From, filter.To}
+
+	if filter.Alias != "" {
+		query += " AND alias = $4"
+		args = append(args, filter.Alias)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY hour, alias", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rollups := make([]domain.StatsRollup, 0)
+
+	for rows.Next() {
+		rollup, err := scanStatsRollup(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		rollups = append(rollups, rollup)
+	}
+
+	return rollups, rows.Err()
+}
+
+func (r *StatsPostgresRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+statsTable+" SET owner = $2 WHERE alias = $1", alias, owner.Hex())
+
+	return err
+}
+
+func (r *StatsPostgresRepo) DeleteByAlias(ctx context.Context, alias string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM "+statsTable+" WHERE alias = $1", alias)
+
+	return err
+}
+
+func scanStatsRollup(row scanner) (domain.StatsRollup, error) {
+	var rollup domain.StatsRollup
+	var owner string
+
+	if err := row.Scan(&rollup.Alias, &owner, &rollup.Hour, &rollup.Clicks, jsonValue{&rollup.Referrers},
+		jsonValue{&rollup.Countries}, jsonValue{&rollup.Browsers}, jsonValue{&rollup.OS},
+		jsonValue{&rollup.Visitors}, &rollup.Bots); err != nil {
+		return domain.StatsRollup{}, err
+	}
+
+	var err error
+
+	rollup.Owner, err = primitive.ObjectIDFromHex(owner)
+	rollup.Hour = rollup.Hour.UTC()
+
+	return rollup, err
+}
@@ -0,0 +1,87 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TokensBoltRepo keeps tokens by hash, tokens are few, so deletion by user scans the whole bucket
+type TokensBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newTokensBoltRepo(db *bbolt.DB) *TokensBoltRepo {
+	return &TokensBoltRepo{
+		db: db,
+	}
+}
+
+func (r *TokensBoltRepo) Create(ctx context.Context, token domain.UserToken) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return putValue(tx.Bucket(tokensBucket), []byte(token.Hash), token)
+	})
+}
+
+func (r *TokensBoltRepo) Consume(ctx context.Context, hash string, purpose string) (domain.UserToken, error) {
+	var token domain.UserToken
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		tokens := tx.Bucket(tokensBucket)
+
+		found, err := getValue(tokens, []byte(hash), &token)
+
+		if err != nil {
+			return err
+		}
+
+		if !found || token.Purpose != purpose {
+			return ErrTokenNotFound
+		}
+
+		return tokens.Delete([]byte(hash))
+	})
+
+	if err != nil {
+		return domain.UserToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *TokensBoltRepo) DeleteByUser(ctx context.Context, user primitive.ObjectID, purpose string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		tokens := tx.Bucket(tokensBucket)
+
+		var keys [][]byte
+
+		err := tokens.ForEach(func(k, v []byte) error {
+			var token domain.UserToken
+
+			if err := bson.Unmarshal(v, &token); err != nil {
+				return err
+			}
+
+			if token.User == user && token.Purpose == purpose {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+
+			return nil
+		})
+
+		if err != nil {
+			return err
+		}
+
+		// Bucket must not be changed during iteration
+		for _, k := range keys {
+			if err := tokens.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
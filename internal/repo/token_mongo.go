@@ -0,0 +1,46 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// TokensRepo keeps tokens by hash, expired tokens are removed by TTL index
+type TokensRepo struct {
+	db *mongo.Collection
+}
+
+func newTokensRepo(db *mongo.Database) *TokensRepo {
+	return &TokensRepo{
+		db: db.Collection(tokensCollection),
+	}
+}
+
+func (r *TokensRepo) Create(ctx context.Context, token domain.UserToken) error {
+	_, err := r.db.InsertOne(ctx, token)
+
+	return err
+}
+
+func (r *TokensRepo) Consume(ctx context.Context, hash string, purpose string) (domain.UserToken, error) {
+	var token domain.UserToken
+
+	if err := r.db.FindOneAndDelete(ctx, bson.M{"_id": hash, "purpose": purpose}).Decode(&token); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.UserToken{}, ErrTokenNotFound
+		}
+
+		return domain.UserToken{}, err
+	}
+
+	return token, nil
+}
+
+func (r *TokensRepo) DeleteByUser(ctx context.Context, user primitive.ObjectID, purpose string) error {
+	_, err := r.db.DeleteMany(ctx, bson.M{"user": user, "purpose": purpose})
+
+	return err
+}
@@ -0,0 +1,55 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const tokenColumns = "hash, user_id, purpose, email, created_at, expired_at"
+
+type TokensPostgresRepo struct {
+	db *sql.DB
+}
+
+func newTokensPostgresRepo(db *sql.DB) *TokensPostgresRepo {
+	return &TokensPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *TokensPostgresRepo) Create(ctx context.Context, token domain.UserToken) error {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+tokensTable+" ("+tokenColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		token.Hash, token.User.Hex(), token.Purpose, token.Email, token.CreatedAt, token.ExpiredAt)
+
+	return err
+}
+
+func (r *TokensPostgresRepo) Consume(ctx context.Context, hash string, purpose string) (domain.UserToken, error) {
+	row := r.db.QueryRowContext(ctx, "DELETE FROM "+tokensTable+" WHERE hash = $1 AND purpose = $2 RETURNING "+tokenColumns,
+		hash, purpose)
+
+	var token domain.UserToken
+	var user string
+
+	if err := row.Scan(&token.Hash, &user, &token.Purpose, &token.Email, &token.CreatedAt, &token.ExpiredAt); err != nil {
+		if err == sql.ErrNoRows {
+			return domain.UserToken{}, ErrTokenNotFound
+		}
+
+		return domain.UserToken{}, err
+	}
+
+	var err error
+
+	token.User, err = primitive.ObjectIDFromHex(user)
+
+	return token, err
+}
+
+func (r *TokensPostgresRepo) DeleteByUser(ctx context.Context, user primitive.ObjectID, purpose string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM "+tokensTable+" WHERE user_id = $1 AND purpose = $2", user.Hex(), purpose)
+
+	return err
+}
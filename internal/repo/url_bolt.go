@@ -0,0 +1,328 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/highlight"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// Weights of fields in text search, the same as in mongo text index
+const (
+	aliasSearchWeight    = 10
+	tagsSearchWeight     = 5
+	titleSearchWeight    = 3
+	originalSearchWeight = 1
+)
+
+// URLsBoltRepo keeps urls by alias. There are no secondary indexes,
+// so lists and search scan all urls, which is acceptable for embedded mode
+type URLsBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newURLsBoltRepo(db *bbolt.DB) *URLsBoltRepo {
+	return &URLsBoltRepo{
+		db: db,
+	}
+}
+
+func (r *URLsBoltRepo) ListByOwner(ctx context.Context, userId primitive.ObjectID) ([]domain.URL, error) {
+	return r.list(func(url domain.URL) bool {
+		return url.Owner == userId
+	})
+}
+
+func (r *URLsBoltRepo) ListByOwnerAndExpiration(ctx context.Context, userId primitive.ObjectID, expired bool) ([]domain.URL, error) {
+	return r.list(func(url domain.URL) bool {
+		return url.Owner == userId && url.Expired() == expired
+	})
+}
+
+func (r *URLsBoltRepo) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	return r.list(func(url domain.URL) bool {
+		return url.Owner == userId && url.Health.Broken == broken
+	})
+}
+
+func (r *URLsBoltRepo) ListActive(ctx context.Context) ([]domain.URL, error) {
+	return r.list(func(url domain.URL) bool {
+		return !url.Expired()
+	})
+}
+
+func (r *URLsBoltRepo) ListNewlyExpired(ctx context.Context) ([]domain.URL, error) {
+	return r.list(func(url domain.URL) bool {
+		return url.Expired() && !url.ExpirationNotified
+	})
+}
+
+func (r *URLsBoltRepo) CountByExpiration(ctx context.Context, expired bool) (int64, error) {
+	urls, err := r.list(func(url domain.URL) bool {
+		return url.Expired() == expired
+	})
+
+	return int64(len(urls)), err
+}
+
+func (r *URLsBoltRepo) Search(ctx context.Context, userId primitive.ObjectID, query string, limit int64) ([]domain.URLSearchResult, error) {
+	results := make([]domain.URLSearchResult, 0)
+
+	// Like in mongo, any of terms is enough for match
+	terms := highlight.Terms(query)
+
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	urls, err := r.ListByOwner(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, url := range urls {
+		score := searchScore(url, terms)
+
+		if score > 0 {
+			results = append(results, domain.URLSearchResult{URL: url, Score: score})
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	if int64(len(results)) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func (r *URLsBoltRepo) Create(ctx context.Context, url domain.URL) (string, error) {
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		urls := tx.Bucket(urlsBucket)
+
+		if urls.Get([]byte(url.Alias)) != nil {
+			return ErrURLAlreadyExists
+		}
+
+		return putValue(urls, []byte(url.Alias), url)
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return url.Alias, nil
+}
+
+func (r *URLsBoltRepo) Get(ctx context.Context, alias string) (domain.URL, error) {
+	var url domain.URL
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		found, err := getValue(tx.Bucket(urlsBucket), []byte(alias), &url)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrURLNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	return url, nil
+}
+
+func (r *URLsBoltRepo) GetByOriginalAndOwner(ctx context.Context, original string, owner primitive.ObjectID) (domain.URL, error) {
+	urls, err := r.list(func(url domain.URL) bool {
+		return url.Original == original && url.Owner == owner
+	})
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if len(urls) == 0 {
+		return domain.URL{}, ErrURLNotFound
+	}
+
+	return urls[0], nil
+}
+
+func (r *URLsBoltRepo) Replace(ctx context.Context, url domain.URL) error {
+	return r.change(url.Alias, func(stored *domain.URL) {
+		url.Owner = stored.Owner
+		url.Version = stored.Version
+		*stored = url
+	})
+}
+
+func (r *URLsBoltRepo) Prolong(ctx context.Context, alias string, toProlong domain.URLProlong) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.ExpiredAt = time.Now().Add(time.Duration(toProlong.Duration) * time.Second)
+		url.ExpirationNotified = false
+	})
+}
+
+func (r *URLsBoltRepo) UpdateMetadata(ctx context.Context, alias string, metadata domain.URLMetadata) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Metadata = metadata
+	})
+}
+
+func (r *URLsBoltRepo) UpdateHealth(ctx context.Context, alias string, health domain.URLHealth) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Health = health
+	})
+}
+
+func (r *URLsBoltRepo) UpdateRules(ctx context.Context, alias string, rules []domain.RedirectRule) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Rules = rules
+	})
+}
+
+func (r *URLsBoltRepo) UpdateVariants(ctx context.Context, alias string, variants []domain.Variant, sticky bool) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Variants = variants
+		url.StickyVariants = sticky
+	})
+}
+
+func (r *URLsBoltRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Owner = owner
+	})
+}
+
+func (r *URLsBoltRepo) CollapseVariants(ctx context.Context, alias string, original string) error {
+	return r.change(alias, func(url *domain.URL) {
+		url.Original = original
+		url.Variants = nil
+		url.StickyVariants = false
+	})
+}
+
+func (r *URLsBoltRepo) IncrementClicks(ctx context.Context, alias string, variant string) (int64, error) {
+	var clicks int64
+	found := false
+
+	err := r.update(alias, func(url *domain.URL) {
+		found = true
+		url.Clicks++
+		clicks = url.Clicks
+
+		// Variant may be already removed, then only total clicks are incremented
+		for i := range url.Variants {
+			if variant != "" && url.Variants[i].Name == variant {
+				url.Variants[i].Clicks++
+			}
+		}
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !found {
+		return 0, ErrURLNotFound
+	}
+
+	return clicks, nil
+}
+
+func (r *URLsBoltRepo) SetExpirationNotified(ctx context.Context, alias string) error {
+	return r.update(alias, func(url *domain.URL) {
+		url.ExpirationNotified = true
+	})
+}
+
+func (r *URLsBoltRepo) Delete(ctx context.Context, alias string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(urlsBucket).Delete([]byte(alias))
+	})
+}
+
+// list returns urls matching filter ordered by creation time
+func (r *URLsBoltRepo) list(filter func(url domain.URL) bool) ([]domain.URL, error) {
+	urls := make([]domain.URL, 0)
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(urlsBucket).ForEach(func(k, v []byte) error {
+			var url domain.URL
+
+			if err := bson.Unmarshal(v, &url); err != nil {
+				return err
+			}
+
+			if filter(url) {
+				urls = append(urls, url)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(urls, func(i, j int) bool {
+		return urls[i].CreatedAt.Before(urls[j].CreatedAt)
+	})
+
+	return urls, nil
+}
+
+// update changes url with alias in single transaction, missing url is skipped
+func (r *URLsBoltRepo) update(alias string, change func(url *domain.URL)) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		var url domain.URL
+
+		urls := tx.Bucket(urlsBucket)
+
+		found, err := getValue(urls, []byte(alias), &url)
+
+		if err != nil || !found {
+			return err
+		}
+
+		change(&url)
+
+		return putValue(urls, []byte(alias), url)
+	})
+}
+
+// change updates url like update and increments its version
+func (r *URLsBoltRepo) change(alias string, apply func(url *domain.URL)) error {
+	return r.update(alias, func(url *domain.URL) {
+		apply(url)
+		url.Version++
+	})
+}
+
+// searchScore sums weighted count of matches of terms in searchable fields
+func searchScore(url domain.URL, terms []string) float64 {
+	score := aliasSearchWeight*len(highlight.Find(url.Alias, terms)) +
+		titleSearchWeight*len(highlight.Find(url.Metadata.Title, terms)) +
+		originalSearchWeight*len(highlight.Find(url.Original, terms))
+
+	for _, tag := range url.Tags {
+		score += tagsSearchWeight * len(highlight.Find(tag, terms))
+	}
+
+	return float64(score)
+}
@@ -7,9 +7,13 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"time"
 )
 
+// Increment of version on every change of url, except counters and flags of background jobs
+var nextVersion = bson.M{"version": 1}
+
 type URLsRepo struct {
 	db *mongo.Collection
 }
@@ -56,6 +60,95 @@ func (r *URLsRepo) ListByOwnerAndExpiration(ctx context.Context, userId primitiv
 	return urls, err
 }
 
+func (r *URLsRepo) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	urls := make([]domain.URL, 0)
+
+	// URLs which were never checked are considered as not broken
+	query := bson.M{"owner": userId, "health.broken": bson.M{"$ne": true}}
+
+	if broken {
+		query["health.broken"] = true
+	}
+
+	cur, err := r.db.Find(ctx, query)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &urls)
+
+	return urls, err
+}
+
+func (r *URLsRepo) ListActive(ctx context.Context) ([]domain.URL, error) {
+	urls := make([]domain.URL, 0)
+
+	cur, err := r.db.Find(ctx, bson.M{"expiredAt": bson.M{"$gte": time.Now()}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &urls)
+
+	return urls, err
+}
+
+func (r *URLsRepo) ListNewlyExpired(ctx context.Context) ([]domain.URL, error) {
+	urls := make([]domain.URL, 0)
+
+	cur, err := r.db.Find(ctx, bson.M{"expiredAt": bson.M{"$lt": time.Now()}, "expirationNotified": bson.M{"$ne": true}})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &urls)
+
+	return urls, err
+}
+
+func (r *URLsRepo) CountByExpiration(ctx context.Context, expired bool) (int64, error) {
+	var op string
+
+	if expired {
+		op = "$lt"
+	} else {
+		op = "$gte"
+	}
+
+	return r.db.CountDocuments(ctx, bson.M{"expiredAt": bson.M{op: time.Now()}})
+}
+
+func (r *URLsRepo) Search(ctx context.Context, userId primitive.ObjectID, query string, limit int64) ([]domain.URLSearchResult, error) {
+	var found []struct {
+		URL   domain.URL `bson:",inline"`
+		Score float64    `bson:"score"`
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().SetProjection(bson.M{"score": score}).SetSort(bson.M{"score": score}).SetLimit(limit)
+
+	cur, err := r.db.Find(ctx, bson.M{"owner": userId, "$text": bson.M{"$search": query}}, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cur.All(ctx, &found); err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.URLSearchResult, 0, len(found))
+
+	for _, f := range found {
+		results = append(results, domain.URLSearchResult{URL: f.URL, Score: f.Score})
+	}
+
+	return results, nil
+}
+
 func (r *URLsRepo) Create(ctx context.Context, url domain.URL) (string, error) {
 	res, err := r.db.InsertOne(ctx, url)
 
@@ -98,10 +191,132 @@ func (r *URLsRepo) GetByOriginalAndOwner(ctx context.Context, original string, o
 	return url, nil
 }
 
+func (r *URLsRepo) Replace(ctx context.Context, url domain.URL) error {
+	data, err := bson.Marshal(url)
+
+	if err != nil {
+		return err
+	}
+
+	var set bson.M
+
+	if err := bson.Unmarshal(data, &set); err != nil {
+		return err
+	}
+
+	delete(set, "_id")
+	delete(set, "owner")
+	delete(set, "version")
+
+	update := bson.M{"$set": set, "$inc": nextVersion}
+
+	// Empty lists are omitted from document, so previous values are removed explicitly
+	unset := bson.M{}
+
+	for _, field := range []string{"tags", "rules", "variants"} {
+		if _, ok := set[field]; !ok {
+			unset[field] = ""
+		}
+	}
+
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	_, err = r.db.UpdateByID(ctx, url.Alias, update)
+
+	return err
+}
+
 func (r *URLsRepo) Prolong(ctx context.Context, alias string, toProlong domain.URLProlong) error {
-	updateQuery := bson.M{"expiredAt": time.Now().Add(time.Duration(toProlong.Duration) * time.Second)}
+	updateQuery := bson.M{
+		"expiredAt":          time.Now().Add(time.Duration(toProlong.Duration) * time.Second),
+		"expirationNotified": false,
+	}
+
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": updateQuery, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) UpdateMetadata(ctx context.Context, alias string, metadata domain.URLMetadata) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"metadata": metadata}, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) UpdateHealth(ctx context.Context, alias string, health domain.URLHealth) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"health": health}, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) UpdateRules(ctx context.Context, alias string, rules []domain.RedirectRule) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"rules": rules}, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) UpdateVariants(ctx context.Context, alias string, variants []domain.Variant, sticky bool) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"variants": variants, "stickyVariants": sticky}, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"owner": owner}, "$inc": nextVersion})
+
+	return err
+}
+
+func (r *URLsRepo) CollapseVariants(ctx context.Context, alias string, original string) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{
+		"$set":   bson.M{"original": original, "stickyVariants": false},
+		"$unset": bson.M{"variants": ""},
+		"$inc":   nextVersion,
+	})
+
+	return err
+}
+
+func (r *URLsRepo) IncrementClicks(ctx context.Context, alias string, variant string) (int64, error) {
+	var url domain.URL
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	// Variant may be already removed, then only total clicks are incremented. Array filter fails on url without
+	// variants, so variant is counted only if variants exist
+	if variant != "" {
+		variantOpts := options.FindOneAndUpdate().SetReturnDocument(options.After).
+			SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"v.name": variant}}})
+
+		filter := bson.M{"_id": alias, "variants": bson.M{"$type": "array"}}
+		inc := bson.M{"clicks": 1, "variants.$[v].clicks": 1}
+
+		err := r.db.FindOneAndUpdate(ctx, filter, bson.M{"$inc": inc}, variantOpts).Decode(&url)
+
+		if err == nil {
+			return url.Clicks, nil
+		}
+
+		if err != mongo.ErrNoDocuments {
+			return 0, err
+		}
+	}
+
+	if err := r.db.FindOneAndUpdate(ctx, bson.M{"_id": alias}, bson.M{"$inc": bson.M{"clicks": 1}}, opts).Decode(&url); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, ErrURLNotFound
+		}
+
+		return 0, err
+	}
+
+	return url.Clicks, nil
+}
 
-	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": updateQuery})
+func (r *URLsRepo) SetExpirationNotified(ctx context.Context, alias string) error {
+	_, err := r.db.UpdateByID(ctx, alias, bson.M{"$set": bson.M{"expirationNotified": true}})
 
 	return err
 }
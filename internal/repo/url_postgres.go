@@ -0,0 +1,277 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/database/postgres"
+	"github.com/mebr0/tiny-url/pkg/highlight"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"strings"
+	"time"
+)
+
+const urlColumns = "alias, original, created_at, expired_at, owner, tags, metadata, health, clicks, " +
+	"expiration_notified, forward_query, forward_path, rules, variants, sticky_variants, version"
+
+// Weighted document for text search, punctuation is replaced so parts of URLs are matched separately
+const urlSearchDocument = `setweight(to_tsvector('simple', regexp_replace(alias, '[^[:alnum:]]+', ' ', 'g')), 'A') ||
+	setweight(to_tsvector('simple', regexp_replace(coalesce(array_to_string(tags, ' '), ''), '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+	setweight(to_tsvector('simple', regexp_replace(coalesce(metadata->>'title', ''), '[^[:alnum:]]+', ' ', 'g')), 'C') ||
+	setweight(to_tsvector('simple', regexp_replace(original, '[^[:alnum:]]+', ' ', 'g')), 'D')`
+
+type URLsPostgresRepo struct {
+	db *sql.DB
+}
+
+func newURLsPostgresRepo(db *sql.DB) *URLsPostgresRepo {
+	return &URLsPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *URLsPostgresRepo) ListByOwner(ctx context.Context, userId primitive.ObjectID) ([]domain.URL, error) {
+	return r.list(ctx, "owner = $1", userId.Hex())
+}
+
+func (r *URLsPostgresRepo) ListByOwnerAndExpiration(ctx context.Context, userId primitive.ObjectID, expired bool) ([]domain.URL, error) {
+	if expired {
+		return r.list(ctx, "owner = $1 AND expired_at < $2", userId.Hex(), time.Now())
+	}
+
+	return r.list(ctx, "owner = $1 AND expired_at >= $2", userId.Hex(), time.Now())
+}
+
+func (r *URLsPostgresRepo) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	// URLs which were never checked are considered as not broken
+	return r.list(ctx, "owner = $1 AND coalesce((health->>'broken')::boolean, false) = $2", userId.Hex(), broken)
+}
+
+func (r *URLsPostgresRepo) ListActive(ctx context.Context) ([]domain.URL, error) {
+	return r.list(ctx, "expired_at >= $1", time.Now())
+}
+
+func (r *URLsPostgresRepo) ListNewlyExpired(ctx context.Context) ([]domain.URL, error) {
+	return r.list(ctx, "expired_at < $1 AND NOT expiration_notified", time.Now())
+}
+
+func (r *URLsPostgresRepo) CountByExpiration(ctx context.Context, expired bool) (int64, error) {
+	var count int64
+
+	query := "SELECT count(*) FROM " + urlsTable + " WHERE expired_at >= $1"
+
+	if expired {
+		query = "SELECT count(*) FROM " + urlsTable + " WHERE expired_at < $1"
+	}
+
+	err := r.db.QueryRowContext(ctx, query, time.Now()).Scan(&count)
+
+	return count, err
+}
+
+func (r *URLsPostgresRepo) Search(ctx context.Context, userId primitive.ObjectID, query string, limit int64) ([]domain.URLSearchResult, error) {
+	results := make([]domain.URLSearchResult, 0)
+
+	// Like in mongo, any of terms is enough for match
+	terms := highlight.Terms(query)
+
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT "+urlColumns+", ts_rank(document, query) AS score "+
+		"FROM (SELECT *, "+urlSearchDocument+" AS document FROM "+urlsTable+" WHERE owner = $1) AS u, "+
+		"to_tsquery('simple', $2) AS query WHERE document @@ query ORDER BY score DESC LIMIT $3",
+		userId.Hex(), strings.Join(terms, " | "), limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var result domain.URLSearchResult
+
+		result.URL, err = scanURL(rows, &result.Score)
+
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, rows.Err()
+}
+
+func (r *URLsPostgresRepo) Create(ctx context.Context, url domain.URL) (string, error) {
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+urlsTable+" ("+urlColumns+") "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)",
+		url.Alias, url.Original, url.CreatedAt, url.ExpiredAt, url.Owner.Hex(), pq.Array(url.Tags),
+		jsonValue{url.Metadata}, jsonValue{url.Health}, url.Clicks, url.ExpirationNotified, url.ForwardQuery,
+		url.ForwardPath, jsonValue{url.Rules}, jsonValue{url.Variants}, url.StickyVariants, url.Version)
+
+	if err != nil {
+		if postgres.IsDuplicate(err) {
+			return "", ErrURLAlreadyExists
+		}
+
+		return "", err
+	}
+
+	return url.Alias, nil
+}
+
+func (r *URLsPostgresRepo) Get(ctx context.Context, alias string) (domain.URL, error) {
+	return r.get(ctx, "alias = $1", alias)
+}
+
+func (r *URLsPostgresRepo) GetByOriginalAndOwner(ctx context.Context, original string, owner primitive.ObjectID) (domain.URL, error) {
+	return r.get(ctx, "original = $1 AND owner = $2", original, owner.Hex())
+}
+
+func (r *URLsPostgresRepo) Replace(ctx context.Context, url domain.URL) error {
+	return r.change(ctx, url.Alias, "original = $2, created_at = $3, expired_at = $4, tags = $5, metadata = $6, "+
+		"health = $7, clicks = $8, expiration_notified = $9, forward_query = $10, forward_path = $11, rules = $12, "+
+		"variants = $13, sticky_variants = $14",
+		url.Original, url.CreatedAt, url.ExpiredAt, pq.Array(url.Tags), jsonValue{url.Metadata}, jsonValue{url.Health},
+		url.Clicks, url.ExpirationNotified, url.ForwardQuery, url.ForwardPath, jsonValue{url.Rules},
+		jsonValue{url.Variants}, url.StickyVariants)
+}
+
+func (r *URLsPostgresRepo) Prolong(ctx context.Context, alias string, toProlong domain.URLProlong) error {
+	return r.change(ctx, alias, "expired_at = $2, expiration_notified = FALSE",
+		time.Now().Add(time.Duration(toProlong.Duration)*time.Second))
+}
+
+func (r *URLsPostgresRepo) UpdateMetadata(ctx context.Context, alias string, metadata domain.URLMetadata) error {
+	return r.change(ctx, alias, "metadata = $2", jsonValue{metadata})
+}
+
+func (r *URLsPostgresRepo) UpdateHealth(ctx context.Context, alias string, health domain.URLHealth) error {
+	return r.change(ctx, alias, "health = $2", jsonValue{health})
+}
+
+func (r *URLsPostgresRepo) UpdateRules(ctx context.Context, alias string, rules []domain.RedirectRule) error {
+	return r.change(ctx, alias, "rules = $2", jsonValue{rules})
+}
+
+func (r *URLsPostgresRepo) UpdateVariants(ctx context.Context, alias string, variants []domain.Variant, sticky bool) error {
+	return r.change(ctx, alias, "variants = $2, sticky_variants = $3", jsonValue{variants}, sticky)
+}
+
+func (r *URLsPostgresRepo) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	return r.change(ctx, alias, "owner = $2", owner.Hex())
+}
+
+func (r *URLsPostgresRepo) CollapseVariants(ctx context.Context, alias string, original string) error {
+	return r.change(ctx, alias, "original = $2, variants = NULL, sticky_variants = FALSE", original)
+}
+
+func (r *URLsPostgresRepo) IncrementClicks(ctx context.Context, alias string, variant string) (int64, error) {
+	var clicks int64
+
+	// Variant may be already removed, then only total clicks are incremented
+	err := r.db.QueryRowContext(ctx, `UPDATE `+urlsTable+` SET clicks = clicks + 1,
+		variants = CASE WHEN $2 = '' OR jsonb_typeof(variants) IS DISTINCT FROM 'array' THEN variants ELSE (
+			SELECT jsonb_agg(CASE WHEN v->>'name' = $2
+				THEN jsonb_set(v, '{clicks}', to_jsonb(coalesce((v->>'clicks')::bigint, 0) + 1))
+				ELSE v END ORDER BY i)
+			FROM jsonb_array_elements(variants) WITH ORDINALITY AS e(v, i)
+		) END
+		WHERE alias = $1 RETURNING clicks`, alias, variant).Scan(&clicks)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrURLNotFound
+		}
+
+		return 0, err
+	}
+
+	return clicks, nil
+}
+
+func (r *URLsPostgresRepo) SetExpirationNotified(ctx context.Context, alias string) error {
+	return r.update(ctx, alias, "expiration_notified = TRUE")
+}
+
+func (r *URLsPostgresRepo) Delete(ctx context.Context, alias string) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM "+urlsTable+" WHERE alias = $1", alias)
+
+	return err
+}
+
+func (r *URLsPostgresRepo) list(ctx context.Context, where string, args ...interface{}) ([]domain.URL, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+urlColumns+" FROM "+urlsTable+" WHERE "+where+" ORDER BY created_at", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	urls := make([]domain.URL, 0)
+
+	for rows.Next() {
+		url, err := scanURL(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		urls = append(urls, url)
+	}
+
+	return urls, rows.Err()
+}
+
+func (r *URLsPostgresRepo) get(ctx context.Context, where string, args ...interface{}) (domain.URL, error) {
+	url, err := scanURL(r.db.QueryRowContext(ctx, "SELECT "+urlColumns+" FROM "+urlsTable+" WHERE "+where+" LIMIT 1", args...))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.URL{}, ErrURLNotFound
+		}
+
+		return domain.URL{}, err
+	}
+
+	return url, nil
+}
+
+// update sets columns of url with alias as $1 and values as next parameters
+func (r *URLsPostgresRepo) update(ctx context.Context, alias string, set string, values ...interface{}) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+urlsTable+" SET "+set+" WHERE alias = $1", append([]interface{}{alias}, values...)...)
+
+	return err
+}
+
+// change sets columns like update and increments version of url
+func (r *URLsPostgresRepo) change(ctx context.Context, alias string, set string, values ...interface{}) error {
+	return r.update(ctx, alias, set+", version = version + 1", values...)
+}
+
+// scanURL reads url columns followed by extra columns
+func scanURL(row scanner, extra ...interface{}) (domain.URL, error) {
+	var url domain.URL
+	var owner string
+
+	dest := []interface{}{
+		&url.Alias, &url.Original, &url.CreatedAt, &url.ExpiredAt, &owner, pq.Array(&url.Tags),
+		jsonValue{&url.Metadata}, jsonValue{&url.Health}, &url.Clicks, &url.ExpirationNotified, &url.ForwardQuery,
+		&url.ForwardPath, jsonValue{&url.Rules}, jsonValue{&url.Variants}, &url.StickyVariants, &url.Version,
+	}
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return domain.URL{}, err
+	}
+
+	var err error
+
+	url.Owner, err = primitive.ObjectIDFromHex(owner)
+
+	return url, err
+}
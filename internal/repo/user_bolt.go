@@ -0,0 +1,262 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// UsersBoltRepo keeps users by id with index of emails in separate bucket
+type UsersBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newUsersBoltRepo(db *bbolt.DB) *UsersBoltRepo {
+	return &UsersBoltRepo{
+		db: db,
+	}
+}
+
+func (r *UsersBoltRepo) List(ctx context.Context) ([]domain.User, error) {
+	var users []domain.User
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(usersBucket).ForEach(func(k, v []byte) error {
+			var user domain.User
+
+			if err := bson.Unmarshal(v, &user); err != nil {
+				return err
+			}
+
+			users = append(users, user)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].RegisteredAt.Before(users[j].RegisteredAt)
+	})
+
+	return users, nil
+}
+
+func (r *UsersBoltRepo) Create(ctx context.Context, user domain.User) (primitive.ObjectID, error) {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		emails := tx.Bucket(userEmailsBucket)
+
+		if emails.Get([]byte(user.Email)) != nil {
+			return ErrUserAlreadyExists
+		}
+
+		if err := emails.Put([]byte(user.Email), []byte(user.ID.Hex())); err != nil {
+			return err
+		}
+
+		return putValue(tx.Bucket(usersBucket), []byte(user.ID.Hex()), user)
+	})
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return user.ID, nil
+}
+
+func (r *UsersBoltRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	var user domain.User
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		found, err := getValue(tx.Bucket(usersBucket), []byte(id.Hex()), &user)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrUserNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersBoltRepo) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
+	var user domain.User
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		id := tx.Bucket(userEmailsBucket).Get([]byte(email))
+
+		if id == nil {
+			return ErrUserNotFound
+		}
+
+		found, err := getValue(tx.Bucket(usersBucket), id, &user)
+
+		if err != nil {
+			return err
+		}
+
+		if !found || user.Password != password {
+			return ErrUserNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersBoltRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	var user domain.User
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		id := tx.Bucket(userEmailsBucket).Get([]byte(email))
+
+		if id == nil {
+			return ErrUserNotFound
+		}
+
+		found, err := getValue(tx.Bucket(usersBucket), id, &user)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrUserNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersBoltRepo) UpdateLastLogin(ctx context.Context, id primitive.ObjectID, lastLogin time.Time) error {
+	return r.update(id, func(user *domain.User) {
+		user.LastLogin = lastLogin
+	})
+}
+
+func (r *UsersBoltRepo) UpdateName(ctx context.Context, id primitive.ObjectID, name string) error {
+	return r.update(id, func(user *domain.User) {
+		user.Name = name
+	})
+}
+
+// UpdateEmail moves user in index of emails, email of other user is not taken
+func (r *UsersBoltRepo) UpdateEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		var user domain.User
+
+		users := tx.Bucket(usersBucket)
+		emails := tx.Bucket(userEmailsBucket)
+
+		found, err := getValue(users, []byte(id.Hex()), &user)
+
+		if err != nil || !found || user.Email == email {
+			return err
+		}
+
+		if emails.Get([]byte(email)) != nil {
+			return ErrUserAlreadyExists
+		}
+
+		if err := emails.Delete([]byte(user.Email)); err != nil {
+			return err
+		}
+
+		if err := emails.Put([]byte(email), []byte(id.Hex())); err != nil {
+			return err
+		}
+
+		user.Email = email
+
+		return putValue(users, []byte(id.Hex()), user)
+	})
+}
+
+func (r *UsersBoltRepo) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	return r.update(id, func(user *domain.User) {
+		user.Password = password
+		user.TokenGeneration++
+	})
+}
+
+func (r *UsersBoltRepo) RevokeTokens(ctx context.Context, id primitive.ObjectID) error {
+	return r.update(id, func(user *domain.User) {
+		user.TokenGeneration++
+	})
+}
+
+func (r *UsersBoltRepo) SetVerified(ctx context.Context, id primitive.ObjectID) error {
+	return r.update(id, func(user *domain.User) {
+		user.Verified = true
+	})
+}
+
+func (r *UsersBoltRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		var user domain.User
+
+		users := tx.Bucket(usersBucket)
+
+		found, err := getValue(users, []byte(id.Hex()), &user)
+
+		if err != nil || !found {
+			return err
+		}
+
+		if err := tx.Bucket(userEmailsBucket).Delete([]byte(user.Email)); err != nil {
+			return err
+		}
+
+		return users.Delete([]byte(id.Hex()))
+	})
+}
+
+// update changes user with id in single transaction, missing user is skipped
+func (r *UsersBoltRepo) update(id primitive.ObjectID, change func(user *domain.User)) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		var user domain.User
+
+		users := tx.Bucket(usersBucket)
+
+		found, err := getValue(users, []byte(id.Hex()), &user)
+
+		if err != nil || !found {
+			return err
+		}
+
+		change(&user)
+
+		return putValue(users, []byte(id.Hex()), user)
+	})
+}
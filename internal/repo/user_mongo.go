@@ -48,6 +48,20 @@ func (r *UsersRepo) Create(ctx context.Context, user domain.User) (primitive.Obj
 	return res.InsertedID.(primitive.ObjectID), nil
 }
 
+func (r *UsersRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	var user domain.User
+
+	if err := r.db.FindOne(ctx, bson.M{"_id": id}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.User{}, ErrUserNotFound
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
 func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
 	var user domain.User
 
@@ -62,6 +76,20 @@ func (r *UsersRepo) GetByCredentials(ctx context.Context, email, password string
 	return user, nil
 }
 
+func (r *UsersRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	var user domain.User
+
+	if err := r.db.FindOne(ctx, bson.M{"email": email}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.User{}, ErrUserNotFound
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
 func (r *UsersRepo) UpdateLastLogin(ctx context.Context, id primitive.ObjectID, lastLogin time.Time) error {
 	if _, err := r.db.UpdateByID(ctx, id, bson.M{"$set": bson.M{"lastLogin": lastLogin}}); err != nil {
 		return err
@@ -69,3 +97,48 @@ func (r *UsersRepo) UpdateLastLogin(ctx context.Context, id primitive.ObjectID,
 
 	return nil
 }
+
+func (r *UsersRepo) UpdateName(ctx context.Context, id primitive.ObjectID, name string) error {
+	_, err := r.db.UpdateByID(ctx, id, bson.M{"$set": bson.M{"name": name}})
+
+	return err
+}
+
+func (r *UsersRepo) UpdateEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	if _, err := r.db.UpdateByID(ctx, id, bson.M{"$set": bson.M{"email": email}}); err != nil {
+		if mongodb.IsDuplicate(err) {
+			return ErrUserAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UsersRepo) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	_, err := r.db.UpdateByID(ctx, id, bson.M{
+		"$set": bson.M{"password": password},
+		"$inc": bson.M{"tokenGeneration": 1},
+	})
+
+	return err
+}
+
+func (r *UsersRepo) RevokeTokens(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.UpdateByID(ctx, id, bson.M{"$inc": bson.M{"tokenGeneration": 1}})
+
+	return err
+}
+
+func (r *UsersRepo) SetVerified(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.UpdateByID(ctx, id, bson.M{"$set": bson.M{"verified": true}})
+
+	return err
+}
+
+func (r *UsersRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.DeleteOne(ctx, bson.M{"_id": id})
+
+	return err
+}
@@ -0,0 +1,180 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/database/postgres"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const userColumns = "id, name, email, password, registered_at, last_login, verified, token_generation"
+
+type UsersPostgresRepo struct {
+	db *sql.DB
+}
+
+func newUsersPostgresRepo(db *sql.DB) *UsersPostgresRepo {
+	return &UsersPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *UsersPostgresRepo) List(ctx context.Context) ([]domain.User, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+userColumns+" FROM "+usersTable+" ORDER BY registered_at")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var users []domain.User
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r *UsersPostgresRepo) Create(ctx context.Context, user domain.User) (primitive.ObjectID, error) {
+	if user.ID.IsZero() {
+		user.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+usersTable+" ("+userColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		user.ID.Hex(), user.Name, user.Email, user.Password, user.RegisteredAt, user.LastLogin, user.Verified,
+		user.TokenGeneration)
+
+	if err != nil {
+		if postgres.IsDuplicate(err) {
+			return [12]byte{}, ErrUserAlreadyExists
+		}
+
+		return [12]byte{}, err
+	}
+
+	return user.ID, nil
+}
+
+func (r *UsersPostgresRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM "+usersTable+" WHERE id = $1", id.Hex())
+
+	user, err := scanUser(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, ErrUserNotFound
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersPostgresRepo) GetByCredentials(ctx context.Context, email, password string) (domain.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM "+usersTable+" WHERE email = $1 AND password = $2", email, password)
+
+	user, err := scanUser(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, ErrUserNotFound
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersPostgresRepo) GetByEmail(ctx context.Context, email string) (domain.User, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+userColumns+" FROM "+usersTable+" WHERE email = $1", email)
+
+	user, err := scanUser(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.User{}, ErrUserNotFound
+		}
+
+		return domain.User{}, err
+	}
+
+	return user, nil
+}
+
+func (r *UsersPostgresRepo) UpdateLastLogin(ctx context.Context, id primitive.ObjectID, lastLogin time.Time) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET last_login = $2 WHERE id = $1", id.Hex(), lastLogin)
+
+	return err
+}
+
+func (r *UsersPostgresRepo) UpdateName(ctx context.Context, id primitive.ObjectID, name string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET name = $2 WHERE id = $1", id.Hex(), name)
+
+	return err
+}
+
+func (r *UsersPostgresRepo) UpdateEmail(ctx context.Context, id primitive.ObjectID, email string) error {
+	if _, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET email = $2 WHERE id = $1", id.Hex(), email); err != nil {
+		if postgres.IsDuplicate(err) {
+			return ErrUserAlreadyExists
+		}
+
+		return err
+	}
+
+	return nil
+}
+
+func (r *UsersPostgresRepo) UpdatePassword(ctx context.Context, id primitive.ObjectID, password string) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET password = $2, token_generation = token_generation + 1 "+
+		"WHERE id = $1", id.Hex(), password)
+
+	return err
+}
+
+func (r *UsersPostgresRepo) RevokeTokens(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET token_generation = token_generation + 1 WHERE id = $1", id.Hex())
+
+	return err
+}
+
+func (r *UsersPostgresRepo) SetVerified(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+usersTable+" SET verified = TRUE WHERE id = $1", id.Hex())
+
+	return err
+}
+
+// Delete removes user with tokens of user
+func (r *UsersPostgresRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM "+usersTable+" WHERE id = $1", id.Hex())
+
+	return err
+}
+
+func scanUser(row scanner) (domain.User, error) {
+	var user domain.User
+	var id string
+
+	if err := row.Scan(&id, &user.Name, &user.Email, &user.Password, &user.RegisteredAt, &user.LastLogin, &user.Verified,
+		&user.TokenGeneration); err != nil {
+		return domain.User{}, err
+	}
+
+	var err error
+
+	user.ID, err = primitive.ObjectIDFromHex(id)
+
+	return user, err
+}
@@ -0,0 +1,278 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"time"
+)
+
+// WebhooksBoltRepo keeps webhooks by id and their deliveries in nested bucket per webhook
+type WebhooksBoltRepo struct {
+	db *bbolt.DB
+}
+
+func newWebhooksBoltRepo(db *bbolt.DB) *WebhooksBoltRepo {
+	return &WebhooksBoltRepo{
+		db: db,
+	}
+}
+
+func (r *WebhooksBoltRepo) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.list(func(webhook domain.Webhook) bool {
+		return webhook.Owner == owner
+	})
+}
+
+func (r *WebhooksBoltRepo) ListByOwnerAndEvent(ctx context.Context, owner primitive.ObjectID, event string) ([]domain.Webhook, error) {
+	return r.list(func(webhook domain.Webhook) bool {
+		if webhook.Owner != owner {
+			return false
+		}
+
+		for _, e := range webhook.Events {
+			if e == event {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func (r *WebhooksBoltRepo) Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error) {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		return putValue(tx.Bucket(webhooksBucket), []byte(webhook.ID.Hex()), webhook)
+	})
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return webhook.ID, nil
+}
+
+func (r *WebhooksBoltRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.Webhook, error) {
+	var webhook domain.Webhook
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		found, err := getValue(tx.Bucket(webhooksBucket), []byte(id.Hex()), &webhook)
+
+		if err != nil {
+			return err
+		}
+
+		if !found {
+			return ErrWebhookNotFound
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhooksBoltRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		if err := tx.Bucket(webhooksBucket).Delete([]byte(id.Hex())); err != nil {
+			return err
+		}
+
+		err := tx.Bucket(webhookDeliveriesBucket).DeleteBucket([]byte(id.Hex()))
+
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		}
+
+		return err
+	})
+}
+
+func (r *WebhooksBoltRepo) ListDeliveries(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(webhookDeliveriesBucket).Bucket([]byte(webhookId.Hex()))
+
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var delivery domain.WebhookDelivery
+
+			if err := bson.Unmarshal(v, &delivery); err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, delivery)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The latest deliveries go first
+	sort.SliceStable(deliveries, func(i, j int) bool {
+		return deliveries[i].CreatedAt.After(deliveries[j].CreatedAt)
+	})
+
+	if int64(len(deliveries)) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhooksBoltRepo) CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error) {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		b, err := tx.Bucket(webhookDeliveriesBucket).CreateBucketIfNotExists([]byte(delivery.Webhook.Hex()))
+
+		if err != nil {
+			return err
+		}
+
+		return putValue(b, []byte(delivery.ID.Hex()), delivery)
+	})
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return delivery.ID, nil
+}
+
+func (r *WebhooksBoltRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return r.db.Update(func(tx *bbolt.Tx) error {
+		var stored domain.WebhookDelivery
+
+		b := tx.Bucket(webhookDeliveriesBucket).Bucket([]byte(delivery.Webhook.Hex()))
+
+		if b == nil {
+			return nil
+		}
+
+		found, err := getValue(b, []byte(delivery.ID.Hex()), &stored)
+
+		if err != nil || !found {
+			return err
+		}
+
+		stored.Attempts = delivery.Attempts
+		stored.StatusCode = delivery.StatusCode
+		stored.Error = delivery.Error
+		stored.Delivered = delivery.Delivered
+		stored.AttemptedAt = delivery.AttemptedAt
+		stored.Pending = delivery.Pending
+		stored.NextAttemptAt = delivery.NextAttemptAt
+
+		return putValue(b, []byte(delivery.ID.Hex()), stored)
+	})
+}
+
+// ClaimDeliveries looks through deliveries of every webhook, writes are serialized by bolt,
+// so every delivery is claimed once
+func (r *WebhooksBoltRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	err := r.db.Update(func(tx *bbolt.Tx) error {
+		var due []domain.WebhookDelivery
+
+		err := tx.Bucket(webhookDeliveriesBucket).ForEach(func(webhook, _ []byte) error {
+			return tx.Bucket(webhookDeliveriesBucket).Bucket(webhook).ForEach(func(k, v []byte) error {
+				var delivery domain.WebhookDelivery
+
+				if err := bson.Unmarshal(v, &delivery); err != nil {
+					return err
+				}
+
+				if delivery.Pending && !delivery.NextAttemptAt.After(now) {
+					due = append(due, delivery)
+				}
+
+				return nil
+			})
+		})
+
+		if err != nil {
+			return err
+		}
+
+		sort.SliceStable(due, func(i, j int) bool {
+			return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+		})
+
+		if int64(len(due)) > limit {
+			due = due[:limit]
+		}
+
+		for _, delivery := range due {
+			delivery.NextAttemptAt = lease
+
+			b := tx.Bucket(webhookDeliveriesBucket).Bucket([]byte(delivery.Webhook.Hex()))
+
+			if err := putValue(b, []byte(delivery.ID.Hex()), delivery); err != nil {
+				return err
+			}
+
+			deliveries = append(deliveries, delivery)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// list returns webhooks matching filter ordered by creation time
+func (r *WebhooksBoltRepo) list(filter func(webhook domain.Webhook) bool) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+
+	err := r.db.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(webhooksBucket).ForEach(func(k, v []byte) error {
+			var webhook domain.Webhook
+
+			if err := bson.Unmarshal(v, &webhook); err != nil {
+				return err
+			}
+
+			if filter(webhook) {
+				webhooks = append(webhooks, webhook)
+			}
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(webhooks, func(i, j int) bool {
+		return webhooks[i].CreatedAt.Before(webhooks[j].CreatedAt)
+	})
+
+	return webhooks, nil
+}
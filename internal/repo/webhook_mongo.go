@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"time"
+)
+
+type WebhooksRepo struct {
+	db         *mongo.Collection
+	deliveries *mongo.Collection
+}
+
+func newWebhooksRepo(db *mongo.Database) *WebhooksRepo {
+	return &WebhooksRepo{
+		db:         db.Collection(webhooksCollection),
+		deliveries: db.Collection(webhookDeliveriesCollection),
+	}
+}
+
+func (r *WebhooksRepo) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+
+	cur, err := r.db.Find(ctx, bson.M{"owner": owner})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &webhooks)
+
+	return webhooks, err
+}
+
+func (r *WebhooksRepo) ListByOwnerAndEvent(ctx context.Context, owner primitive.ObjectID, event string) ([]domain.Webhook, error) {
+	webhooks := make([]domain.Webhook, 0)
+
+	cur, err := r.db.Find(ctx, bson.M{"owner": owner, "events": event})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &webhooks)
+
+	return webhooks, err
+}
+
+func (r *WebhooksRepo) Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error) {
+	res, err := r.db.InsertOne(ctx, webhook)
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *WebhooksRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.Webhook, error) {
+	var webhook domain.Webhook
+
+	if err := r.db.FindOne(ctx, bson.M{"_id": id}).Decode(&webhook); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return domain.Webhook{}, ErrWebhookNotFound
+		}
+
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhooksRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	if _, err := r.db.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return err
+	}
+
+	_, err := r.deliveries.DeleteMany(ctx, bson.M{"webhook": id})
+
+	return err
+}
+
+func (r *WebhooksRepo) ListDeliveries(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	opts := options.Find().SetSort(bson.M{"createdAt": -1}).SetLimit(limit)
+
+	cur, err := r.deliveries.Find(ctx, bson.M{"webhook": webhookId}, opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = cur.All(ctx, &deliveries)
+
+	return deliveries, err
+}
+
+func (r *WebhooksRepo) CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error) {
+	res, err := r.deliveries.InsertOne(ctx, delivery)
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return res.InsertedID.(primitive.ObjectID), nil
+}
+
+func (r *WebhooksRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := r.deliveries.ReplaceOne(ctx, bson.M{"_id": delivery.ID}, delivery)
+
+	return err
+}
+
+// ClaimDeliveries postpones deliveries one by one, so every delivery is claimed by single instance
+func (r *WebhooksRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"nextAttemptAt": 1}).SetReturnDocument(options.After)
+
+	for int64(len(deliveries)) < limit {
+		var delivery domain.WebhookDelivery
+
+		err := r.deliveries.FindOneAndUpdate(ctx, bson.M{
+			"pending":       true,
+			"nextAttemptAt": bson.M{"$lte": now},
+		}, bson.M{"$set": bson.M{"nextAttemptAt": lease}}, opts).Decode(&delivery)
+
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,215 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"github.com/lib/pq"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+const (
+	webhookColumns  = "id, url, secret, events, created_at, owner"
+	deliveryColumns = "id, webhook, event, payload, attempts, status_code, error, delivered, created_at, attempted_at, " +
+		"pending, next_attempt_at"
+)
+
+type WebhooksPostgresRepo struct {
+	db *sql.DB
+}
+
+func newWebhooksPostgresRepo(db *sql.DB) *WebhooksPostgresRepo {
+	return &WebhooksPostgresRepo{
+		db: db,
+	}
+}
+
+func (r *WebhooksPostgresRepo) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	return r.list(ctx, "owner = $1", owner.Hex())
+}
+
+func (r *WebhooksPostgresRepo) ListByOwnerAndEvent(ctx context.Context, owner primitive.ObjectID, event string) ([]domain.Webhook, error) {
+	return r.list(ctx, "owner = $1 AND $2 = ANY(events)", owner.Hex(), event)
+}
+
+func (r *WebhooksPostgresRepo) Create(ctx context.Context, webhook domain.Webhook) (primitive.ObjectID, error) {
+	if webhook.ID.IsZero() {
+		webhook.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+webhooksTable+" ("+webhookColumns+") VALUES ($1, $2, $3, $4, $5, $6)",
+		webhook.ID.Hex(), webhook.URL, webhook.Secret, pq.Array(webhook.Events), webhook.CreatedAt, webhook.Owner.Hex())
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return webhook.ID, nil
+}
+
+func (r *WebhooksPostgresRepo) Get(ctx context.Context, id primitive.ObjectID) (domain.Webhook, error) {
+	row := r.db.QueryRowContext(ctx, "SELECT "+webhookColumns+" FROM "+webhooksTable+" WHERE id = $1", id.Hex())
+
+	webhook, err := scanWebhook(row)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.Webhook{}, ErrWebhookNotFound
+		}
+
+		return domain.Webhook{}, err
+	}
+
+	return webhook, nil
+}
+
+func (r *WebhooksPostgresRepo) Delete(ctx context.Context, id primitive.ObjectID) error {
+	// Deliveries are deleted by cascade
+	_, err := r.db.ExecContext(ctx, "DELETE FROM "+webhooksTable+" WHERE id = $1", id.Hex())
+
+	return err
+}
+
+func (r *WebhooksPostgresRepo) ListDeliveries(ctx context.Context, webhookId primitive.ObjectID, limit int64) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+deliveryColumns+" FROM "+webhookDeliveriesTable+
+		" WHERE webhook = $1 ORDER BY created_at DESC LIMIT $2", webhookId.Hex(), limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhooksPostgresRepo) CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error) {
+	if delivery.ID.IsZero() {
+		delivery.ID = primitive.NewObjectID()
+	}
+
+	_, err := r.db.ExecContext(ctx, "INSERT INTO "+webhookDeliveriesTable+" ("+deliveryColumns+") "+
+		"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)",
+		delivery.ID.Hex(), delivery.Webhook.Hex(), delivery.Event, delivery.Payload, delivery.Attempts,
+		delivery.StatusCode, delivery.Error, delivery.Delivered, delivery.CreatedAt, delivery.AttemptedAt,
+		delivery.Pending, delivery.NextAttemptAt)
+
+	if err != nil {
+		return [12]byte{}, err
+	}
+
+	return delivery.ID, nil
+}
+
+func (r *WebhooksPostgresRepo) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := r.db.ExecContext(ctx, "UPDATE "+webhookDeliveriesTable+" SET attempts = $2, status_code = $3, error = $4, "+
+		"delivered = $5, attempted_at = $6, pending = $7, next_attempt_at = $8 WHERE id = $1", delivery.ID.Hex(),
+		delivery.Attempts, delivery.StatusCode, delivery.Error, delivery.Delivered, delivery.AttemptedAt, delivery.Pending,
+		delivery.NextAttemptAt)
+
+	return err
+}
+
+// ClaimDeliveries skips rows locked by other instances, so every delivery is claimed by single instance
+func (r *WebhooksPostgresRepo) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Time, limit int64) ([]domain.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx, "UPDATE "+webhookDeliveriesTable+" SET next_attempt_at = $2 WHERE id IN ("+
+		"SELECT id FROM "+webhookDeliveriesTable+" WHERE pending AND next_attempt_at <= $1 "+
+		"ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED) RETURNING "+deliveryColumns, now, lease, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := make([]domain.WebhookDelivery, 0)
+
+	for rows.Next() {
+		delivery, err := scanDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r *WebhooksPostgresRepo) list(ctx context.Context, where string, args ...interface{}) ([]domain.Webhook, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT "+webhookColumns+" FROM "+webhooksTable+" WHERE "+where+" ORDER BY created_at", args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	webhooks := make([]domain.Webhook, 0)
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func scanWebhook(row scanner) (domain.Webhook, error) {
+	var webhook domain.Webhook
+	var id, owner string
+
+	if err := row.Scan(&id, &webhook.URL, &webhook.Secret, pq.Array(&webhook.Events), &webhook.CreatedAt, &owner); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	var err error
+
+	if webhook.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	webhook.Owner, err = primitive.ObjectIDFromHex(owner)
+
+	return webhook, err
+}
+
+func scanDelivery(row scanner) (domain.WebhookDelivery, error) {
+	var delivery domain.WebhookDelivery
+	var id, webhook string
+
+	if err := row.Scan(&id, &webhook, &delivery.Event, &delivery.Payload, &delivery.Attempts, &delivery.StatusCode,
+		&delivery.Error, &delivery.Delivered, &delivery.CreatedAt, &delivery.AttemptedAt, &delivery.Pending,
+		&delivery.NextAttemptAt); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	var err error
+
+	if delivery.ID, err = primitive.ObjectIDFromHex(id); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+
+	delivery.Webhook, err = primitive.ObjectIDFromHex(webhook)
+
+	return delivery, err
+}
@@ -22,6 +22,18 @@ func NewServer(cfg *config.Config, handler http.Handler) *Server {
 	}
 }
 
+// NewMetricsServer serves metrics on separate port, so they are not exposed with API
+func NewMetricsServer(cfg *config.Config, handler http.Handler) *Server {
+	return &Server{
+		httpServer: &http.Server{
+			Addr:         ":" + cfg.Metrics.Port,
+			Handler:      handler,
+			ReadTimeout:  cfg.HTTP.ReadTimeout,
+			WriteTimeout: cfg.HTTP.WriteTimeout,
+		},
+	}
+}
+
 func (s *Server) Run() error {
 	return s.httpServer.ListenAndServe()
 }
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"time"
+)
+
+// Count of audit entries returned when filter has no limit
+const auditLimit = 100
+
+// Fields changed in background or by every redirection are not audited
+var (
+	auditIgnoredUserFields = []string{"password", "lastLogin"}
+	auditIgnoredURLFields  = []string{"clicks", "health", "metadata", "version"}
+)
+
+type auditClientKey struct{}
+
+// WithAuditClient returns copy of ctx carrying origin of request, it is recorded with audited actions
+func WithAuditClient(ctx context.Context, client domain.AuditClient) context.Context {
+	return context.WithValue(ctx, auditClientKey{}, client)
+}
+
+func auditClient(ctx context.Context) domain.AuditClient {
+	client, _ := ctx.Value(auditClientKey{}).(domain.AuditClient)
+
+	return client
+}
+
+type AuditService struct {
+	repo      repo.Audit
+	usersRepo repo.Users
+	admins    map[string]bool
+}
+
+func newAuditService(repo repo.Audit, usersRepo repo.Users, admins []string) *AuditService {
+	adminEmails := make(map[string]bool, len(admins))
+
+	for _, email := range admins {
+		adminEmails[email] = true
+	}
+
+	return &AuditService{
+		repo:      repo,
+		usersRepo: usersRepo,
+		admins:    adminEmails,
+	}
+}
+
+// List returns the latest entries matching filter. Admins see entries of every user,
+// others only their own ones
+func (s *AuditService) List(ctx context.Context, userId primitive.ObjectID, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	user, err := s.usersRepo.Get(ctx, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.admins[user.Email] {
+		if !filter.Actor.IsZero() && filter.Actor != userId {
+			return nil, ErrAuditForbidden
+		}
+
+		filter.Actor = userId
+	}
+
+	if filter.Limit <= 0 {
+		filter.Limit = auditLimit
+	}
+
+	return s.repo.List(ctx, filter)
+}
+
+// Record appends action of actor to audit log with fields of target changed between before and after.
+// Action is already done, so failure is only logged
+func (s *AuditService) Record(ctx context.Context, actor primitive.ObjectID, action string, target string,
+	before interface{}, after interface{}, ignored ...string) {
+	logger := logging.FromContext(ctx).WithField("action", action).WithField("target", target)
+
+	changes, err := domain.NewAuditChanges(before, after, ignored...)
+
+	if err != nil {
+		logger.WithError(err).Warn("Could not compare changes of audited target")
+	}
+
+	client := auditClient(ctx)
+
+	_, err = s.repo.Create(ctx, domain.AuditEntry{
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		CreatedAt: time.Now(),
+		Changes:   changes,
+	})
+
+	if err != nil {
+		logger.WithError(err).Error("Could not record audit entry")
+	}
+}
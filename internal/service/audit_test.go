@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+const adminEmail = "admin@gmail.com"
+
+func mockAuditService(t *testing.T) (*AuditService, *mockRepo.MockAudit, *mockRepo.MockUsers) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	auditRepo := mockRepo.NewMockAudit(mockCtl)
+	usersRepo := mockRepo.NewMockUsers(mockCtl)
+
+	service := newAuditService(auditRepo, usersRepo, []string{adminEmail})
+
+	return service, auditRepo, usersRepo
+}
+
+func TestAuditService_ListOwn(t *testing.T) {
+	s, auditRepo, usersRepo := mockAuditService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	usersRepo.EXPECT().Get(ctx, userId).Return(domain.User{ID: userId, Email: "sirius@gmail.com"}, nil)
+	auditRepo.EXPECT().List(ctx, domain.AuditFilter{Actor: userId, Action: domain.AuditURLCreated, Limit: auditLimit}).
+		Return([]domain.AuditEntry{}, nil)
+
+	res, err := s.List(ctx, userId, domain.AuditFilter{Action: domain.AuditURLCreated})
+
+	require.NoError(t, err)
+	require.Empty(t, res)
+}
+
+func TestAuditService_ListErrAuditForbidden(t *testing.T) {
+	s, _, usersRepo := mockAuditService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	usersRepo.EXPECT().Get(ctx, userId).Return(domain.User{ID: userId, Email: "sirius@gmail.com"}, nil)
+
+	_, err := s.List(ctx, userId, domain.AuditFilter{Actor: primitive.NewObjectID()})
+
+	require.ErrorIs(t, err, ErrAuditForbidden)
+}
+
+func TestAuditService_ListAdmin(t *testing.T) {
+	s, auditRepo, usersRepo := mockAuditService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	usersRepo.EXPECT().Get(ctx, userId).Return(domain.User{ID: userId, Email: adminEmail}, nil)
+	auditRepo.EXPECT().List(ctx, domain.AuditFilter{Limit: 10}).Return([]domain.AuditEntry{{}}, nil)
+
+	res, err := s.List(ctx, userId, domain.AuditFilter{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+}
+
+func TestAuditService_Record(t *testing.T) {
+	s, auditRepo, _ := mockAuditService(t)
+
+	ctx := WithAuditClient(context.Background(), domain.AuditClient{IP: "127.0.0.1", UserAgent: "Mozilla/5.0"})
+
+	actor := primitive.NewObjectID()
+	expiredAt := time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC)
+
+	before := domain.URL{Alias: "alias", Original: "https://google.com/", ExpiredAt: expiredAt, Clicks: 1}
+	after := before
+	after.ExpiredAt = expiredAt.Add(time.Hour)
+	after.Clicks = 2
+	after.Tags = []string{"docs"}
+
+	var entry domain.AuditEntry
+
+	auditRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e domain.AuditEntry) (primitive.ObjectID, error) {
+		entry = e
+
+		return primitive.NewObjectID(), nil
+	})
+
+	s.Record(ctx, actor, domain.AuditURLProlonged, "alias", before, after, auditIgnoredURLFields...)
+
+	require.Equal(t, actor, entry.Actor)
+	require.Equal(t, domain.AuditURLProlonged, entry.Action)
+	require.Equal(t, "alias", entry.Target)
+	require.Equal(t, "127.0.0.1", entry.IP)
+	require.Equal(t, "Mozilla/5.0", entry.UserAgent)
+	require.Equal(t, []domain.AuditChange{
+		{
+			Field:  "expiredAt",
+			Before: json.RawMessage(`"2021-05-09T09:29:18Z"`),
+			After:  json.RawMessage(`"2021-05-09T10:29:18Z"`),
+		},
+		{Field: "tags", After: json.RawMessage(`["docs"]`)},
+	}, entry.Changes)
+}
+
+func TestAuditService_RecordDeleted(t *testing.T) {
+	s, auditRepo, _ := mockAuditService(t)
+
+	ctx := context.Background()
+
+	var entry domain.AuditEntry
+
+	auditRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, e domain.AuditEntry) (primitive.ObjectID, error) {
+		entry = e
+
+		return primitive.NewObjectID(), errDefault
+	})
+
+	s.Record(ctx, primitive.NewObjectID(), domain.AuditURLDeleted, "alias", domain.URL{Alias: "alias"}, nil,
+		auditIgnoredURLFields...)
+
+	// Every field of deleted url is removed
+	for _, change := range entry.Changes {
+		require.NotNil(t, change.Before)
+		require.Nil(t, change.After)
+	}
+
+	require.NotEmpty(t, entry.Changes)
+}
@@ -2,29 +2,56 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
 	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/pkg/auth"
 	"github.com/mebr0/tiny-url/pkg/hash"
-	log "github.com/sirupsen/logrus"
+	"github.com/mebr0/tiny-url/pkg/mail"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
 	"time"
 )
 
+// Timeout of sending single email in background
+const mailTimeout = 30 * time.Second
+
+// AuthService registers users and issues sessions. Ownership of email is proven by single-use tokens
+// sent to it, only hashes of tokens are stored
 type AuthService struct {
-	repo           repo.Users
-	hasher         hash.PasswordHasher
-	tokenManager   auth.TokenManager
-	accessTokenTTL time.Duration
+	repo             repo.Users
+	tokens           repo.Tokens
+	audit            Audit
+	hasher           hash.PasswordHasher
+	tokenManager     auth.TokenManager
+	mailer           mail.Sender
+	accessTokenTTL   time.Duration
+	verificationTTL  time.Duration
+	passwordResetTTL time.Duration
+	linkURL          string
+	// Emails and updates of last login done in background, finished on shutdown
+	background sync.WaitGroup
 }
 
-func newAuthService(repo repo.Users, hasher hash.PasswordHasher, tokenManager auth.TokenManager,
-	accessTokenTTL time.Duration) *AuthService {
+func newAuthService(repo repo.Users, tokens repo.Tokens, audit Audit, hasher hash.PasswordHasher,
+	tokenManager auth.TokenManager, mailer mail.Sender, accessTokenTTL, verificationTTL, passwordResetTTL time.Duration,
+	linkURL string) *AuthService {
 	return &AuthService{
-		repo:           repo,
-		hasher:         hasher,
-		tokenManager:   tokenManager,
-		accessTokenTTL: accessTokenTTL,
+		repo:             repo,
+		tokens:           tokens,
+		audit:            audit,
+		hasher:           hasher,
+		tokenManager:     tokenManager,
+		mailer:           mailer,
+		accessTokenTTL:   accessTokenTTL,
+		verificationTTL:  verificationTTL,
+		passwordResetTTL: passwordResetTTL,
+		linkURL:          linkURL,
 	}
 }
 
@@ -43,9 +70,22 @@ func (s *AuthService) Register(ctx context.Context, toRegister domain.UserRegist
 		LastLogin:    time.Now(),
 	}
 
-	_, err = s.repo.Create(ctx, user)
+	id, err := s.repo.Create(ctx, user)
+
+	if err != nil {
+		return err
+	}
+
+	user.ID = id
 
-	return err
+	s.audit.Record(ctx, id, domain.AuditUserRegistered, id.Hex(), nil, user, auditIgnoredUserFields...)
+
+	// User is registered anyway, lost email is recovered by resetting password
+	if err := s.sendToken(ctx, user, domain.TokenVerification); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("user", id.Hex()).Warn("Could not issue verification token")
+	}
+
+	return nil
 }
 
 func (s *AuthService) Login(ctx context.Context, toLogin domain.UserLogin) (domain.Tokens, error) {
@@ -58,19 +98,34 @@ func (s *AuthService) Login(ctx context.Context, toLogin domain.UserLogin) (doma
 	user, err := s.repo.GetByCredentials(ctx, toLogin.Email, passwordHash)
 
 	if err != nil {
+		// Unknown email is not distinguished from wrong password
+		if err == repo.ErrUserNotFound {
+			return domain.Tokens{}, ErrInvalidCredentials
+		}
+
 		return domain.Tokens{}, err
 	}
 
-	tokens, err := s.createSession(ctx, user.ID)
+	if !user.Verified {
+		return domain.Tokens{}, ErrUserNotVerified
+	}
+
+	tokens, err := s.createSession(ctx, user)
 
 	// Async update last login
 	if err == nil {
+		s.audit.Record(ctx, user.ID, domain.AuditUserLoggedIn, user.ID.Hex(), nil, nil)
+
+		s.background.Add(1)
+
 		go func() {
-			c, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+			defer s.background.Done()
+
+			c, cancel := context.WithTimeout(logging.Detach(ctx), time.Duration(5)*time.Second)
 			defer cancel()
 
 			if err := s.repo.UpdateLastLogin(c, user.ID, time.Now()); err != nil {
-				log.Warn("Could not updates last login by time.Now() for user " + user.ID.Hex() + " " + err.Error())
+				logging.FromContext(c).WithError(err).WithField("user", user.ID.Hex()).Warn("Could not update last login of user")
 			}
 		}()
 	}
@@ -78,11 +133,178 @@ func (s *AuthService) Login(ctx context.Context, toLogin domain.UserLogin) (doma
 	return tokens, err
 }
 
-func (s *AuthService) createSession(ctx context.Context, userId primitive.ObjectID) (domain.Tokens, error) {
+// Verify marks email of user as verified, new email is applied if token is sent to it
+func (s *AuthService) Verify(ctx context.Context, toVerify domain.UserVerify) error {
+	token, err := s.consumeToken(ctx, toVerify.Token, domain.TokenVerification)
+
+	if err != nil {
+		return err
+	}
+
+	// Tokens issued before emails were kept in them verify current email
+	if token.Email != "" {
+		if err := s.repo.UpdateEmail(ctx, token.User, token.Email); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.SetVerified(ctx, token.User); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, token.User, domain.AuditUserVerified, token.User.Hex(), nil, nil)
+
+	return nil
+}
+
+// ForgotPassword sends password reset token to email of user, unknown email is not reported to prevent
+// enumeration of users
+func (s *AuthService) ForgotPassword(ctx context.Context, toForgot domain.PasswordForgot) error {
+	user, err := s.repo.GetByEmail(ctx, toForgot.Email)
+
+	if err != nil {
+		if err == repo.ErrUserNotFound {
+			return nil
+		}
+
+		return err
+	}
+
+	// Only the latest token is valid
+	if err := s.tokens.DeleteByUser(ctx, user.ID, domain.TokenPasswordReset); err != nil {
+		return err
+	}
+
+	return s.sendToken(ctx, user, domain.TokenPasswordReset)
+}
+
+// ResetPassword sets new password of user and revokes issued access tokens, email is verified by the same token
+func (s *AuthService) ResetPassword(ctx context.Context, toReset domain.PasswordReset) error {
+	token, err := s.consumeToken(ctx, toReset.Token, domain.TokenPasswordReset)
+
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(toReset.Password)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, token.User, passwordHash); err != nil {
+		return err
+	}
+
+	if err := s.repo.SetVerified(ctx, token.User); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, token.User, domain.AuditUserPasswordReset, token.User.Hex(), nil, nil)
+
+	return nil
+}
+
+// Wait blocks until emails and updates of last login done in background are finished
+func (s *AuthService) Wait() {
+	s.background.Wait()
+}
+
+// sendToken issues token and sends link with it to email of user in background, verification token proves
+// ownership of exactly this email. Link leads to page of frontend, which posts token to API
+func (s *AuthService) sendToken(ctx context.Context, user domain.User, purpose string) error {
+	ttl, subject, page := s.verificationTTL, "Verify your email", "verify"
+
+	if purpose == domain.TokenPasswordReset {
+		ttl, subject, page = s.passwordResetTTL, "Reset your password", "reset-password"
+	}
+
+	expiredAt := time.Now().Add(ttl)
+
+	secret, err := s.issueToken(ctx, user.ID, purpose, user.Email, expiredAt)
+
+	if err != nil {
+		return err
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: subject,
+		Body: fmt.Sprintf("Hello, %s!\n\nFollow the link below before %s:\n%s/%s?token=%s\n\n"+
+			"Ignore this email if you didn't request it.\n", user.Name, expiredAt.UTC().Format("2006-01-02 15:04 MST"),
+			s.linkURL, page, secret),
+	}
+
+	s.background.Add(1)
+
+	go func() {
+		defer s.background.Done()
+
+		c, cancel := context.WithTimeout(logging.Detach(ctx), mailTimeout)
+		defer cancel()
+
+		if err := s.mailer.Send(c, msg); err != nil {
+			logging.FromContext(c).WithError(err).WithField("user", user.ID.Hex()).Warn("Could not send email to user")
+		}
+	}()
+
+	return nil
+}
+
+// issueToken stores hash of new random token and returns the token itself
+func (s *AuthService) issueToken(ctx context.Context, userId primitive.ObjectID, purpose string, email string,
+	expiredAt time.Time) (string, error) {
+	b := make([]byte, 32)
+
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	secret := base64.RawURLEncoding.EncodeToString(b)
+
+	err := s.tokens.Create(ctx, domain.UserToken{
+		Hash:      hashToken(secret),
+		User:      userId,
+		Purpose:   purpose,
+		Email:     email,
+		CreatedAt: time.Now(),
+		ExpiredAt: expiredAt,
+	})
+
+	return secret, err
+}
+
+// consumeToken deletes token, so it cannot be used again even if it is expired
+func (s *AuthService) consumeToken(ctx context.Context, secret string, purpose string) (domain.UserToken, error) {
+	token, err := s.tokens.Consume(ctx, hashToken(secret), purpose)
+
+	if err != nil {
+		if err == repo.ErrTokenNotFound {
+			return domain.UserToken{}, ErrInvalidToken
+		}
+
+		return domain.UserToken{}, err
+	}
+
+	if token.Expired() {
+		return domain.UserToken{}, ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// createSession issues access token of current generation of user
+func (s *AuthService) createSession(ctx context.Context, user domain.User) (domain.Tokens, error) {
 	var res domain.Tokens
 	var err error
 
-	res.AccessToken, err = s.tokenManager.Issue(userId.Hex(), s.accessTokenTTL)
+	res.AccessToken, err = s.tokenManager.Issue(user.ID.Hex(), user.TokenGeneration, s.accessTokenTTL)
 
 	return res, err
 }
@@ -1,64 +1,155 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/mebr0/tiny-url/internal/domain"
 	"github.com/mebr0/tiny-url/internal/repo"
 	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
 	"github.com/mebr0/tiny-url/pkg/auth"
 	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/mebr0/tiny-url/pkg/mail"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"regexp"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
 var errDefault = errors.New("error")
 
-func mockAuthService(t *testing.T) (*AuthService, *mockRepo.MockUsers) {
+func mockAuthService(t *testing.T) (*AuthService, *mockRepo.MockUsers, *mockRepo.MockTokens, *bytes.Buffer) {
 	t.Helper()
 
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 
 	usersRepo := mockRepo.NewMockUsers(mockCtl)
+	tokensRepo := mockRepo.NewMockTokens(mockCtl)
+	audit := mockService.NewMockAudit(mockCtl)
 	authManager, _ := auth.NewJWTManager("key")
 
-	service := newAuthService(usersRepo, hash.NewSHA1PasswordHasher(""), authManager, time.Duration(1)*time.Hour)
+	audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).AnyTimes()
 
-	return service, usersRepo
+	// Emails are written to buffer
+	var mails bytes.Buffer
+
+	service := newAuthService(usersRepo, tokensRepo, audit, hash.NewSHA1PasswordHasher(""), authManager,
+		mail.NewWriterSender(&mails, "noreply@tiny.url"), time.Duration(1)*time.Hour, 24*time.Hour, time.Hour,
+		"https://tiny.url")
+
+	return service, usersRepo, tokensRepo, &mails
+}
+
+// mailedToken returns token from link in sent email
+func mailedToken(t *testing.T, mails *bytes.Buffer, page string) string {
+	t.Helper()
+
+	match := regexp.MustCompile(`https://tiny\.url/` + page + `\?token=([\w-]+)`).FindStringSubmatch(mails.String())
+	require.Len(t, match, 2)
+
+	return match[1]
 }
 
 func TestAuthService_Register(t *testing.T) {
-	service, usersRepo := mockAuthService(t)
+	service, usersRepo, tokensRepo, mails := mockAuthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	var issued domain.UserToken
+
+	usersRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, user domain.User) (primitive.ObjectID, error) {
+		require.False(t, user.Verified)
+
+		return userId, nil
+	})
+	tokensRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, token domain.UserToken) error {
+		issued = token
+
+		return nil
+	})
+
+	err := service.Register(ctx, domain.UserRegister{Name: "Sirius", Email: "sirius@gmail.com"})
+	service.Wait()
+
+	require.NoError(t, err)
+	require.Equal(t, userId, issued.User)
+	require.Equal(t, domain.TokenVerification, issued.Purpose)
+	require.WithinDuration(t, time.Now().Add(24*time.Hour), issued.ExpiredAt, time.Minute)
+	require.Contains(t, mails.String(), "To: sirius@gmail.com\n")
+
+	// Only hash of sent token is stored
+	require.Equal(t, hashToken(mailedToken(t, mails, "verify")), issued.Hash)
+}
+
+func TestAuthService_RegisterErrMail(t *testing.T) {
+	service, usersRepo, tokensRepo, mails := mockAuthService(t)
 
 	ctx := context.Background()
 
 	usersRepo.EXPECT().Create(ctx, gomock.Any()).Return(primitive.NewObjectID(), nil)
+	tokensRepo.EXPECT().Create(ctx, gomock.Any()).Return(errDefault)
 
 	err := service.Register(ctx, domain.UserRegister{})
+	service.Wait()
 
 	require.NoError(t, err)
+	require.Empty(t, mails.String())
 }
 
 func TestAuthService_Login(t *testing.T) {
-	service, usersRepo := mockAuthService(t)
+	service, usersRepo, _, _ := mockAuthService(t)
 
 	ctx := context.Background()
 
-	usersRepo.EXPECT().GetByCredentials(ctx, gomock.Any(), gomock.Any()).Return(domain.User{}, nil)
-	usersRepo.EXPECT().UpdateLastLogin(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	user := domain.User{ID: primitive.NewObjectID(), Verified: true, TokenGeneration: 3}
+
+	var persisted int32
+
+	usersRepo.EXPECT().GetByCredentials(ctx, gomock.Any(), gomock.Any()).Return(user, nil)
+	usersRepo.EXPECT().UpdateLastLogin(gomock.Any(), user.ID, gomock.Any()).DoAndReturn(func(_ context.Context, _ primitive.ObjectID, _ time.Time) error {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreInt32(&persisted, 1)
+
+		return nil
+	})
 
 	res, err := service.Login(ctx, domain.UserLogin{})
+	service.Wait()
+
+	require.NoError(t, err)
+	// Last login is updated in background before shutdown
+	require.Equal(t, int32(1), atomic.LoadInt32(&persisted))
+
+	// Token is issued for current generation of tokens of user
+	subject, generation, err := service.tokenManager.Decode(res.AccessToken)
 
 	require.NoError(t, err)
-	require.IsType(t, domain.Tokens{}, res)
+	require.Equal(t, user.ID.Hex(), subject)
+	require.Equal(t, user.TokenGeneration, generation)
+}
+
+func TestAuthService_LoginErrUserNotVerified(t *testing.T) {
+	service, usersRepo, _, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	usersRepo.EXPECT().GetByCredentials(ctx, gomock.Any(), gomock.Any()).Return(domain.User{}, nil)
+
+	_, err := service.Login(ctx, domain.UserLogin{})
+
+	require.ErrorIs(t, err, ErrUserNotVerified)
 }
 
 func TestAuthService_LoginErrUserNotExists(t *testing.T) {
-	service, usersRepo := mockAuthService(t)
+	service, usersRepo, _, _ := mockAuthService(t)
 
 	ctx := context.Background()
 
@@ -66,11 +157,11 @@ func TestAuthService_LoginErrUserNotExists(t *testing.T) {
 
 	_, err := service.Login(ctx, domain.UserLogin{})
 
-	require.ErrorIs(t, err, repo.ErrUserNotFound)
+	require.ErrorIs(t, err, ErrInvalidCredentials)
 }
 
 func TestAuthService_LoginErr(t *testing.T) {
-	service, usersRepo := mockAuthService(t)
+	service, usersRepo, _, _ := mockAuthService(t)
 
 	ctx := context.Background()
 
@@ -80,3 +171,154 @@ func TestAuthService_LoginErr(t *testing.T) {
 
 	require.Error(t, err)
 }
+
+func TestAuthService_Verify(t *testing.T) {
+	service, usersRepo, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	tokensRepo.EXPECT().Consume(ctx, hashToken("token"), domain.TokenVerification).Return(domain.UserToken{
+		User:      userId,
+		Purpose:   domain.TokenVerification,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil)
+	usersRepo.EXPECT().SetVerified(ctx, userId).Return(nil)
+
+	err := service.Verify(ctx, domain.UserVerify{Token: "token"})
+
+	require.NoError(t, err)
+}
+
+func TestAuthService_VerifyNewEmail(t *testing.T) {
+	service, usersRepo, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	tokensRepo.EXPECT().Consume(ctx, hashToken("token"), domain.TokenVerification).Return(domain.UserToken{
+		User:      userId,
+		Purpose:   domain.TokenVerification,
+		Email:     "orion@gmail.com",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil)
+	usersRepo.EXPECT().UpdateEmail(ctx, userId, "orion@gmail.com").Return(nil)
+	usersRepo.EXPECT().SetVerified(ctx, userId).Return(nil)
+
+	err := service.Verify(ctx, domain.UserVerify{Token: "token"})
+
+	require.NoError(t, err)
+}
+
+func TestAuthService_VerifyErrEmailTaken(t *testing.T) {
+	service, usersRepo, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	tokensRepo.EXPECT().Consume(ctx, hashToken("token"), domain.TokenVerification).Return(domain.UserToken{
+		User:      userId,
+		Purpose:   domain.TokenVerification,
+		Email:     "orion@gmail.com",
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil)
+	usersRepo.EXPECT().UpdateEmail(ctx, userId, "orion@gmail.com").Return(repo.ErrUserAlreadyExists)
+
+	err := service.Verify(ctx, domain.UserVerify{Token: "token"})
+
+	require.ErrorIs(t, err, repo.ErrUserAlreadyExists)
+}
+
+func TestAuthService_VerifyErrInvalidToken(t *testing.T) {
+	service, _, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	tokensRepo.EXPECT().Consume(ctx, gomock.Any(), domain.TokenVerification).Return(domain.UserToken{}, repo.ErrTokenNotFound)
+
+	err := service.Verify(ctx, domain.UserVerify{Token: "token"})
+
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthService_VerifyErrExpiredToken(t *testing.T) {
+	service, _, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	tokensRepo.EXPECT().Consume(ctx, gomock.Any(), domain.TokenVerification).Return(domain.UserToken{
+		User:      primitive.NewObjectID(),
+		Purpose:   domain.TokenVerification,
+		ExpiredAt: time.Now().Add(-time.Second),
+	}, nil)
+
+	err := service.Verify(ctx, domain.UserVerify{Token: "token"})
+
+	require.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func TestAuthService_ForgotPassword(t *testing.T) {
+	service, usersRepo, tokensRepo, mails := mockAuthService(t)
+
+	ctx := context.Background()
+
+	user := domain.User{ID: primitive.NewObjectID(), Name: "Sirius", Email: "sirius@gmail.com"}
+
+	var issued domain.UserToken
+
+	usersRepo.EXPECT().GetByEmail(ctx, user.Email).Return(user, nil)
+	gomock.InOrder(
+		tokensRepo.EXPECT().DeleteByUser(ctx, user.ID, domain.TokenPasswordReset).Return(nil),
+		tokensRepo.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, token domain.UserToken) error {
+			issued = token
+
+			return nil
+		}),
+	)
+
+	err := service.ForgotPassword(ctx, domain.PasswordForgot{Email: user.Email})
+	service.Wait()
+
+	require.NoError(t, err)
+	require.Equal(t, domain.TokenPasswordReset, issued.Purpose)
+	require.WithinDuration(t, time.Now().Add(time.Hour), issued.ExpiredAt, time.Minute)
+	require.Equal(t, hashToken(mailedToken(t, mails, "reset-password")), issued.Hash)
+}
+
+func TestAuthService_ForgotPasswordUnknownEmail(t *testing.T) {
+	service, usersRepo, _, mails := mockAuthService(t)
+
+	ctx := context.Background()
+
+	usersRepo.EXPECT().GetByEmail(ctx, gomock.Any()).Return(domain.User{}, repo.ErrUserNotFound)
+
+	err := service.ForgotPassword(ctx, domain.PasswordForgot{Email: "regulus@gmail.com"})
+	service.Wait()
+
+	require.NoError(t, err)
+	require.Empty(t, mails.String())
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	service, usersRepo, tokensRepo, _ := mockAuthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+	passwordHash, _ := hash.NewSHA1PasswordHasher("").Hash("qweqweqwe")
+
+	tokensRepo.EXPECT().Consume(ctx, hashToken("token"), domain.TokenPasswordReset).Return(domain.UserToken{
+		User:      userId,
+		Purpose:   domain.TokenPasswordReset,
+		ExpiredAt: time.Now().Add(time.Hour),
+	}, nil)
+	usersRepo.EXPECT().UpdatePassword(ctx, userId, passwordHash).Return(nil)
+	usersRepo.EXPECT().SetVerified(ctx, userId).Return(nil)
+
+	err := service.ResetPassword(ctx, domain.PasswordReset{Token: "token", Password: "qweqweqwe"})
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/cache"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	log "github.com/sirupsen/logrus"
+	"sync"
+	"time"
+)
+
+const (
+	// Attempts of every write to cache
+	cacheWriteAttempts = 3
+	// Delay before the second attempt, doubled for every next one
+	cacheWriteBackoff = 100 * time.Millisecond
+	// Timeout of single attempt
+	cacheWriteTimeout = 5 * time.Second
+)
+
+// cacheWriter keeps cache of urls in line with database. Changes of urls are written to cache at once,
+// failed writes are retried in background. Background writes are tracked, so they are finished on shutdown.
+// Versions of urls keep older state from overwriting newer one, whatever order writes finish in
+type cacheWriter struct {
+	cache   cache.URLs
+	backoff time.Duration
+	wg      sync.WaitGroup
+}
+
+func newCacheWriter(cache cache.URLs, backoff time.Duration) *cacheWriter {
+	return &cacheWriter{
+		cache:   cache,
+		backoff: backoff,
+	}
+}
+
+// Set writes changed url
+func (w *cacheWriter) Set(ctx context.Context, url domain.URL) {
+	w.write(ctx, "save url", url.Alias, func(ctx context.Context) error {
+		if err := w.cache.Set(ctx, url); err != nil {
+			return err
+		}
+
+		return w.invalidate(ctx, url.Alias, url.Version)
+	})
+}
+
+// SetMissing writes deleted alias
+func (w *cacheWriter) SetMissing(ctx context.Context, alias string) {
+	w.write(ctx, "save missing alias", alias, func(ctx context.Context) error {
+		if err := w.cache.SetMissing(ctx, alias); err != nil {
+			return err
+		}
+
+		return w.invalidate(ctx, alias, -1)
+	})
+}
+
+// Delete drops alias, so created url is not hidden by missing alias
+func (w *cacheWriter) Delete(ctx context.Context, alias string) {
+	w.write(ctx, "delete alias", alias, func(ctx context.Context) error {
+		if err := w.cache.Delete(ctx, alias); err != nil {
+			return err
+		}
+
+		return w.invalidate(ctx, alias, -1)
+	})
+}
+
+// Fill saves url loaded from database in background. Url is not changed, so it is not invalidated
+func (w *cacheWriter) Fill(ctx context.Context, url domain.URL) {
+	w.background(ctx, "save url", url.Alias, 1, 0, func(ctx context.Context) error {
+		return w.cache.Set(ctx, url)
+	})
+}
+
+// FillMissing saves alias not found in database in background
+func (w *cacheWriter) FillMissing(ctx context.Context, alias string) {
+	w.background(ctx, "save missing alias", alias, 1, 0, func(ctx context.Context) error {
+		return w.cache.SetMissing(ctx, alias)
+	})
+}
+
+// invalidate announces change of alias, if cache keeps local copies of urls
+func (w *cacheWriter) invalidate(ctx context.Context, alias string, version int64) error {
+	if invalidator, ok := w.cache.(cache.Invalidator); ok {
+		return invalidator.Invalidate(ctx, alias, version)
+	}
+
+	return nil
+}
+
+// Wait blocks until background writes are finished
+func (w *cacheWriter) Wait() {
+	w.wg.Wait()
+}
+
+// write tries to write at once and retries in background on failure
+func (w *cacheWriter) write(ctx context.Context, operation string, alias string, write func(ctx context.Context) error) {
+	c, cancel := context.WithTimeout(ctx, cacheWriteTimeout)
+	defer cancel()
+
+	if err := write(c); err == nil {
+		return
+	}
+
+	w.background(ctx, operation, alias, cacheWriteAttempts-1, w.backoff, write)
+}
+
+// background makes attempts of write, waiting delay before each of them and doubling it.
+// Attempts outlive ctx, but are logged as part of its request
+func (w *cacheWriter) background(ctx context.Context, operation string, alias string, attempts int, delay time.Duration,
+	write func(ctx context.Context) error) {
+	ctx = logging.Detach(ctx)
+
+	w.wg.Add(1)
+
+	go func() {
+		defer w.wg.Done()
+
+		var err error
+
+		for i := 0; i < attempts; i++ {
+			time.Sleep(delay)
+
+			if err = w.attempt(ctx, write); err == nil {
+				return
+			}
+
+			delay *= 2
+		}
+
+		logging.FromContext(ctx).WithError(err).WithFields(log.Fields{
+			"operation": operation,
+			"alias":     alias,
+		}).Warn("Could not write to cache")
+	}()
+}
+
+func (w *cacheWriter) attempt(ctx context.Context, write func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, cacheWriteTimeout)
+	defer cancel()
+
+	return write(ctx)
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	mockCache "github.com/mebr0/tiny-url/internal/cache/mocks"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestCacheWriter_SetRetries(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsCache := mockCache.NewMockURLs(mockCtl)
+	w := newCacheWriter(urlsCache, time.Millisecond)
+
+	url := domain.URL{Alias: "alias", Version: 2}
+
+	// Failed write is retried in background until it succeeds
+	gomock.InOrder(
+		urlsCache.EXPECT().Set(gomock.Any(), url).Return(errors.New("connection refused")),
+		urlsCache.EXPECT().Set(gomock.Any(), url).Return(errors.New("connection refused")),
+		urlsCache.EXPECT().Set(gomock.Any(), url).Return(nil),
+	)
+
+	w.Set(context.Background(), url)
+	w.Wait()
+}
+
+func TestCacheWriter_SetGivesUp(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsCache := mockCache.NewMockURLs(mockCtl)
+	w := newCacheWriter(urlsCache, time.Millisecond)
+
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "alias").Return(errors.New("connection refused")).Times(cacheWriteAttempts)
+
+	w.SetMissing(context.Background(), "alias")
+	w.Wait()
+}
+
+// invalidatingCache records invalidations of cache shared with other instances
+type invalidatingCache struct {
+	*mockCache.MockURLs
+	invalidated []string
+}
+
+func (c *invalidatingCache) Invalidate(ctx context.Context, alias string, version int64) error {
+	c.invalidated = append(c.invalidated, fmt.Sprintf("%s:%d", alias, version))
+
+	return nil
+}
+
+func TestCacheWriter_Invalidate(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsCache := &invalidatingCache{MockURLs: mockCache.NewMockURLs(mockCtl)}
+	w := newCacheWriter(urlsCache, time.Millisecond)
+
+	url := domain.URL{Alias: "alias", Version: 2}
+
+	urlsCache.EXPECT().Set(gomock.Any(), url).Return(nil).Times(2)
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "missing").Return(nil).Times(2)
+	urlsCache.EXPECT().Delete(gomock.Any(), "deleted").Return(nil)
+
+	w.Set(context.Background(), url)
+	w.SetMissing(context.Background(), "missing")
+	w.Delete(context.Background(), "deleted")
+
+	// Urls loaded from database are not changed, so they are not invalidated
+	w.Fill(context.Background(), url)
+	w.FillMissing(context.Background(), "missing")
+	w.Wait()
+
+	require.Equal(t, []string{"alias:2", "missing:-1", "deleted:-1"}, urlsCache.invalidated)
+}
+
+func TestCacheWriter_Fill(t *testing.T) {
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsCache := mockCache.NewMockURLs(mockCtl)
+	w := newCacheWriter(urlsCache, time.Millisecond)
+
+	// Filling cache is not retried, the next miss fills it again
+	urlsCache.EXPECT().Set(gomock.Any(), domain.URL{Alias: "alias"}).Return(errors.New("connection refused"))
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "missing").Return(nil)
+
+	w.Fill(context.Background(), domain.URL{Alias: "alias"})
+	w.FillMissing(context.Background(), "missing")
+	w.Wait()
+}
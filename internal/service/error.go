@@ -1,9 +1,24 @@
 package service
 
-import "errors"
+import (
+	"errors"
+	"github.com/mebr0/tiny-url/internal/domain"
+)
 
 var (
 	ErrNoPossibleAliasEncoding = errors.New("cannot encode url to alias")
-	ErrURLLimit                = errors.New("cannot create more urls")
-	ErrURLForbidden            = errors.New("url cannot be accessed")
+	ErrURLLimit                = domain.NewError(domain.KindConflict, "url_limit_reached", "cannot create more urls")
+	ErrURLForbidden            = domain.NewError(domain.KindForbidden, "url_forbidden", "url cannot be accessed")
+	ErrURLMetadataUnavailable  = domain.NewError(domain.KindUpstream, "url_metadata_unavailable", "url metadata cannot be fetched")
+	ErrVariantNotFound         = domain.NewError(domain.KindNotFound, "variant_not_found", "variant doesn't exists")
+	ErrWebhookForbidden        = domain.NewError(domain.KindForbidden, "webhook_forbidden", "webhook cannot be accessed")
+	ErrAuditForbidden          = domain.NewError(domain.KindForbidden, "audit_forbidden", "audit of other users cannot be accessed")
+	ErrInvalidCredentials      = domain.NewError(domain.KindUnauthorized, "invalid_credentials", "invalid email or password")
+	ErrUserNotVerified         = domain.NewError(domain.KindForbidden, "user_not_verified", "email of user is not verified")
+	ErrInvalidToken            = domain.NewError(domain.KindInvalid, "invalid_token", "token is invalid, expired or already used")
+	ErrWrongPassword           = domain.NewError(domain.KindForbidden, "wrong_password", "password is wrong")
+	ErrSuccessorDeletion       = domain.NewError(domain.KindConflict, "successor_deletion", "user receiving urls of deleted users cannot be deleted")
+	ErrNoSuccessor             = domain.NewError(domain.KindInvalid, "no_successor", "urls cannot be transferred without successor")
+	ErrInvalidAlias            = domain.NewError(domain.KindValidation, "invalid_alias", "alias may contain only letters, digits, _ and -")
+	ErrAliasRepeated           = domain.NewError(domain.KindConflict, "alias_repeated", "alias is repeated in imported file")
 )
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/probe"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"time"
+)
+
+type HealthService struct {
+	repo        repo.URLs
+	prober      probe.Prober
+	notifier    HealthNotifier
+	interval    time.Duration
+	concurrency int
+}
+
+func newHealthService(repo repo.URLs, prober probe.Prober, notifier HealthNotifier, interval time.Duration,
+	concurrency int) *HealthService {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	return &HealthService{
+		repo:        repo,
+		prober:      prober,
+		notifier:    notifier,
+		interval:    interval,
+		concurrency: concurrency,
+	}
+}
+
+func (s *HealthService) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	return s.repo.ListByOwnerAndHealth(ctx, userId, broken)
+}
+
+// Run checks destinations of active urls every interval until ctx is done.
+// Checking is disabled with non-positive interval
+func (s *HealthService) Run(ctx context.Context) {
+	if s.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Check(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Could not check urls health")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check probes destinations of all active urls once with bounded concurrency
+func (s *HealthService) Check(ctx context.Context) error {
+	urls, err := s.repo.ListActive(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	sem := make(chan struct{}, s.concurrency)
+
+	var wg sync.WaitGroup
+
+	for _, url := range urls {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+
+		go func(url domain.URL) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			s.check(ctx, url)
+		}(url)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+func (s *HealthService) check(ctx context.Context, url domain.URL) {
+	health := domain.URLHealth{
+		CheckedAt: time.Now(),
+	}
+
+	res, err := s.prober.Probe(ctx, url.Original)
+
+	if err != nil {
+		// Interrupted checks are not results
+		if ctx.Err() != nil {
+			return
+		}
+
+		health.Error = err.Error()
+		health.Broken = true
+	} else {
+		health.StatusCode = res.StatusCode
+		health.Latency = res.Latency.Milliseconds()
+		health.Broken = res.StatusCode >= 400
+	}
+
+	if err := s.repo.UpdateHealth(ctx, url.Alias, health); err != nil {
+		log.WithError(err).WithField("alias", url.Alias).Warn("Could not update health of url")
+		return
+	}
+
+	// Notify only once when url becomes broken
+	if health.Broken && !url.Health.Broken && s.notifier != nil {
+		url.Health = health
+
+		if err := s.notifier.NotifyBroken(ctx, url); err != nil {
+			log.WithError(err).WithField("alias", url.Alias).Warn("Could not notify about broken url")
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/mebr0/tiny-url/pkg/probe"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mockHealthService(t *testing.T) (*HealthService, *mockRepo.MockURLs, *mockService.MockHealthNotifier, string) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsRepo := mockRepo.NewMockURLs(mockCtl)
+	notifier := mockService.NewMockHealthNotifier(mockCtl)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+
+	service := newHealthService(urlsRepo, probe.NewHTTPProber(time.Second, 0, http.DefaultTransport), notifier, time.Hour, 2)
+
+	return service, urlsRepo, notifier, srv.URL
+}
+
+func TestHealthService_ListByOwnerAndHealth(t *testing.T) {
+	s, urlsRepo, _, _ := mockHealthService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().ListByOwnerAndHealth(ctx, userId, true).Return([]domain.URL{}, nil)
+
+	res, err := s.ListByOwnerAndHealth(ctx, userId, true)
+
+	require.NoError(t, err)
+	require.IsType(t, []domain.URL{}, res)
+}
+
+func TestHealthService_Check(t *testing.T) {
+	s, urlsRepo, notifier, original := mockHealthService(t)
+
+	ctx := context.Background()
+
+	healthy := domain.URL{Alias: "healthy", Original: original + "/"}
+	broken := domain.URL{Alias: "broken", Original: original + "/missing"}
+	alreadyBroken := domain.URL{Alias: "already", Original: original + "/missing", Health: domain.URLHealth{Broken: true}}
+
+	urlsRepo.EXPECT().ListActive(ctx).Return([]domain.URL{healthy, broken, alreadyBroken}, nil)
+
+	urlsRepo.EXPECT().UpdateHealth(ctx, "healthy", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, health domain.URLHealth) error {
+			require.Equal(t, http.StatusOK, health.StatusCode)
+			require.False(t, health.Broken)
+
+			return nil
+		})
+	urlsRepo.EXPECT().UpdateHealth(ctx, "broken", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, health domain.URLHealth) error {
+			require.Equal(t, http.StatusNotFound, health.StatusCode)
+			require.True(t, health.Broken)
+
+			return nil
+		})
+	urlsRepo.EXPECT().UpdateHealth(ctx, "already", gomock.Any()).Return(nil)
+
+	// Only newly broken url is notified
+	notifier.EXPECT().NotifyBroken(ctx, gomock.Any()).DoAndReturn(func(_ context.Context, url domain.URL) error {
+		require.Equal(t, "broken", url.Alias)
+
+		return nil
+	})
+
+	err := s.Check(ctx)
+
+	require.NoError(t, err)
+}
+
+func TestHealthService_CheckErrUnreachable(t *testing.T) {
+	s, urlsRepo, notifier, _ := mockHealthService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().ListActive(ctx).Return([]domain.URL{{Alias: "alias", Original: "http://127.0.0.1:1/"}}, nil)
+	urlsRepo.EXPECT().UpdateHealth(ctx, "alias", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, health domain.URLHealth) error {
+			require.True(t, health.Broken)
+			require.NotEmpty(t, health.Error)
+
+			return nil
+		})
+	notifier.EXPECT().NotifyBroken(ctx, gomock.Any()).Return(nil)
+
+	err := s.Check(ctx)
+
+	require.NoError(t, err)
+}
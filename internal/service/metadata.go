@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/metadata"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"time"
+)
+
+type MetadataService struct {
+	repo        repo.URLs
+	cacheWriter *cacheWriter
+	fetcher     metadata.Fetcher
+	queue       chan string
+	workers     int
+}
+
+func newMetadataService(repo repo.URLs, cacheWriter *cacheWriter, fetcher metadata.Fetcher, workers int, queueSize int) *MetadataService {
+	return &MetadataService{
+		repo:        repo,
+		cacheWriter: cacheWriter,
+		fetcher:     fetcher,
+		queue:       make(chan string, queueSize),
+		workers:     workers,
+	}
+}
+
+// Enqueue schedules fetching of metadata for url with alias, dropping it if queue is full
+func (s *MetadataService) Enqueue(alias string) {
+	select {
+	case s.queue <- alias:
+	default:
+		log.WithField("alias", alias).Warn("Metadata queue is full, skip alias")
+	}
+}
+
+// Run processes queued aliases with workers until ctx is done
+func (s *MetadataService) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case alias := <-s.queue:
+					url, err := s.repo.Get(ctx, alias)
+
+					if err == nil {
+						_, err = s.refresh(ctx, url)
+					}
+
+					if err != nil {
+						log.WithError(err).WithField("alias", alias).Warn("Could not fetch metadata of url")
+					}
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (s *MetadataService) Refresh(ctx context.Context, alias string, owner primitive.ObjectID) (domain.URL, error) {
+	url, err := s.repo.Get(ctx, alias)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	// If owners do not match, return forbidden
+	if url.Owner != owner {
+		return domain.URL{}, ErrURLForbidden
+	}
+
+	return s.refresh(ctx, url)
+}
+
+// refresh fetches metadata of url and saves it
+func (s *MetadataService) refresh(ctx context.Context, url domain.URL) (domain.URL, error) {
+	meta, err := s.fetcher.Fetch(ctx, url.Original)
+
+	if err != nil {
+		return domain.URL{}, fmt.Errorf("%w: %v", ErrURLMetadataUnavailable, err)
+	}
+
+	url.Metadata = domain.URLMetadata{
+		Title:       meta.Title,
+		Description: meta.Description,
+		Favicon:     meta.Favicon,
+		Image:       meta.Image,
+		FetchedAt:   time.Now(),
+	}
+
+	if err := s.repo.UpdateMetadata(ctx, url.Alias, url.Metadata); err != nil {
+		return domain.URL{}, err
+	}
+
+	// Url is read again for its new version
+	url, err = s.repo.Get(ctx, url.Alias)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	s.cacheWriter.Set(ctx, url)
+
+	return url, nil
+}
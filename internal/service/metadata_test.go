@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	mockCache "github.com/mebr0/tiny-url/internal/cache/mocks"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	"github.com/mebr0/tiny-url/pkg/metadata"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func mockMetadataService(t *testing.T) (*MetadataService, *mockRepo.MockURLs, *mockCache.MockURLs, string) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	urlsRepo := mockRepo.NewMockURLs(mockCtl)
+	urlsCache := mockCache.NewMockURLs(mockCtl)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<html><head><title>Stub</title></head></html>`))
+	}))
+	t.Cleanup(srv.Close)
+
+	service := newMetadataService(urlsRepo, newCacheWriter(urlsCache, time.Millisecond),
+		metadata.NewHTMLFetcher(time.Second, http.DefaultTransport), 1, 1)
+
+	return service, urlsRepo, urlsCache, srv.URL
+}
+
+func TestMetadataService_Refresh(t *testing.T) {
+	s, urlsRepo, urlsCache, original := mockMetadataService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{
+		Alias:    "alias",
+		Original: original + "/",
+		Owner:    owner,
+	}, nil)
+
+	var saved domain.URL
+
+	urlsRepo.EXPECT().UpdateMetadata(ctx, "alias", gomock.Any()).DoAndReturn(
+		func(_ context.Context, alias string, meta domain.URLMetadata) error {
+			saved = domain.URL{Alias: alias, Original: original + "/", Owner: owner, Metadata: meta, Version: 1}
+
+			return nil
+		})
+
+	// Url with new version is written to cache
+	urlsRepo.EXPECT().Get(ctx, "alias").DoAndReturn(func(_ context.Context, _ string) (domain.URL, error) {
+		return saved, nil
+	})
+	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url domain.URL) error {
+		require.Equal(t, int64(1), url.Version)
+
+		return nil
+	})
+
+	res, err := s.Refresh(ctx, "alias", owner)
+
+	require.NoError(t, err)
+	require.Equal(t, "Stub", res.Metadata.Title)
+	require.Equal(t, original+"/favicon.ico", res.Metadata.Favicon)
+}
+
+func TestMetadataService_RefreshErrURLForbidden(t *testing.T) {
+	s, urlsRepo, _, original := mockMetadataService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{
+		Alias:    "alias",
+		Original: original + "/",
+		Owner:    primitive.NilObjectID,
+	}, nil)
+
+	_, err := s.Refresh(ctx, "alias", primitive.NewObjectID())
+
+	require.ErrorIs(t, err, ErrURLForbidden)
+}
+
+func TestMetadataService_RefreshErrURLNotFound(t *testing.T) {
+	s, urlsRepo, _, _ := mockMetadataService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{}, repo.ErrURLNotFound)
+
+	_, err := s.Refresh(ctx, "alias", primitive.NewObjectID())
+
+	require.ErrorIs(t, err, repo.ErrURLNotFound)
+}
+
+func TestMetadataService_RefreshErrURLMetadataUnavailable(t *testing.T) {
+	s, urlsRepo, _, original := mockMetadataService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{
+		Alias:    "alias",
+		Original: original + "/missing",
+		Owner:    owner,
+	}, nil)
+
+	_, err := s.Refresh(ctx, "alias", owner)
+
+	require.ErrorIs(t, err, ErrURLMetadataUnavailable)
+}
+
+func TestMetadataService_Run(t *testing.T) {
+	s, urlsRepo, urlsCache, original := mockMetadataService(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	done := make(chan struct{})
+
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
+		Alias:    "alias",
+		Original: original + "/",
+	}, nil)
+	urlsRepo.EXPECT().UpdateMetadata(gomock.Any(), "alias", gomock.Any()).DoAndReturn(
+		func(_ context.Context, _ string, meta domain.URLMetadata) error {
+			require.Equal(t, "Stub", meta.Title)
+			close(done)
+
+			return nil
+		})
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{Alias: "alias"}, nil).AnyTimes()
+	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	go s.Run(ctx)
+
+	s.Enqueue("alias")
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("metadata was not fetched")
+	}
+}
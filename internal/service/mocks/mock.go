@@ -36,6 +36,49 @@ func (m *MockUsers) EXPECT() *MockUsersMockRecorder {
 	return m.recorder
 }
 
+// ChangePassword mocks base method.
+func (m *MockUsers) ChangePassword(ctx context.Context, id primitive.ObjectID, toChange domain.PasswordChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangePassword", ctx, id, toChange)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangePassword indicates an expected call of ChangePassword.
+func (mr *MockUsersMockRecorder) ChangePassword(ctx, id, toChange interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangePassword", reflect.TypeOf((*MockUsers)(nil).ChangePassword), ctx, id, toChange)
+}
+
+// Delete mocks base method.
+func (m *MockUsers) Delete(ctx context.Context, id primitive.ObjectID, toDelete domain.UserDelete) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, toDelete)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockUsersMockRecorder) Delete(ctx, id, toDelete interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUsers)(nil).Delete), ctx, id, toDelete)
+}
+
+// Get mocks base method.
+func (m *MockUsers) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, id)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockUsersMockRecorder) Get(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUsers)(nil).Get), ctx, id)
+}
+
 // List mocks base method.
 func (m *MockUsers) List(ctx context.Context) ([]domain.User, error) {
 	m.ctrl.T.Helper()
@@ -51,6 +94,21 @@ func (mr *MockUsersMockRecorder) List(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUsers)(nil).List), ctx)
 }
 
+// Update mocks base method.
+func (m *MockUsers) Update(ctx context.Context, id primitive.ObjectID, toUpdate domain.UserUpdate) (domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, toUpdate)
+	ret0, _ := ret[0].(domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockUsersMockRecorder) Update(ctx, id, toUpdate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUsers)(nil).Update), ctx, id, toUpdate)
+}
+
 // MockAuth is a mock of Auth interface.
 type MockAuth struct {
 	ctrl     *gomock.Controller
@@ -74,6 +132,20 @@ func (m *MockAuth) EXPECT() *MockAuthMockRecorder {
 	return m.recorder
 }
 
+// ForgotPassword mocks base method.
+func (m *MockAuth) ForgotPassword(ctx context.Context, toForgot domain.PasswordForgot) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ForgotPassword", ctx, toForgot)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ForgotPassword indicates an expected call of ForgotPassword.
+func (mr *MockAuthMockRecorder) ForgotPassword(ctx, toForgot interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ForgotPassword", reflect.TypeOf((*MockAuth)(nil).ForgotPassword), ctx, toForgot)
+}
+
 // Login mocks base method.
 func (m *MockAuth) Login(ctx context.Context, toLogin domain.UserLogin) (domain.Tokens, error) {
 	m.ctrl.T.Helper()
@@ -103,6 +175,34 @@ func (mr *MockAuthMockRecorder) Register(ctx, toRegister interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockAuth)(nil).Register), ctx, toRegister)
 }
 
+// ResetPassword mocks base method.
+func (m *MockAuth) ResetPassword(ctx context.Context, toReset domain.PasswordReset) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", ctx, toReset)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthMockRecorder) ResetPassword(ctx, toReset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuth)(nil).ResetPassword), ctx, toReset)
+}
+
+// Verify mocks base method.
+func (m *MockAuth) Verify(ctx context.Context, toVerify domain.UserVerify) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", ctx, toVerify)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockAuthMockRecorder) Verify(ctx, toVerify interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockAuth)(nil).Verify), ctx, toVerify)
+}
+
 // MockURLs is a mock of URLs interface.
 type MockURLs struct {
 	ctrl     *gomock.Controller
@@ -126,6 +226,18 @@ func (m *MockURLs) EXPECT() *MockURLsMockRecorder {
 	return m.recorder
 }
 
+// Click mocks base method.
+func (m *MockURLs) Click(ctx context.Context, url domain.URL, click domain.Click) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Click", ctx, url, click)
+}
+
+// Click indicates an expected call of Click.
+func (mr *MockURLsMockRecorder) Click(ctx, url, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Click", reflect.TypeOf((*MockURLs)(nil).Click), ctx, url, click)
+}
+
 // Create mocks base method.
 func (m *MockURLs) Create(ctx context.Context, toCreate domain.URLCreate) (domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +267,20 @@ func (mr *MockURLsMockRecorder) Delete(ctx, alias, owner interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockURLs)(nil).Delete), ctx, alias, owner)
 }
 
+// DeleteByOwner mocks base method.
+func (m *MockURLs) DeleteByOwner(ctx context.Context, owner primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByOwner", ctx, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByOwner indicates an expected call of DeleteByOwner.
+func (mr *MockURLsMockRecorder) DeleteByOwner(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByOwner", reflect.TypeOf((*MockURLs)(nil).DeleteByOwner), ctx, owner)
+}
+
 // Get mocks base method.
 func (m *MockURLs) Get(ctx context.Context, alias string) (domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -185,6 +311,21 @@ func (mr *MockURLsMockRecorder) GetByOwner(ctx, alias, owner interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByOwner", reflect.TypeOf((*MockURLs)(nil).GetByOwner), ctx, alias, owner)
 }
 
+// Import mocks base method.
+func (m *MockURLs) Import(ctx context.Context, owner primitive.ObjectID, records []domain.URLRecord, options domain.URLImportOptions) (domain.URLImportResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Import", ctx, owner, records, options)
+	ret0, _ := ret[0].(domain.URLImportResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Import indicates an expected call of Import.
+func (mr *MockURLsMockRecorder) Import(ctx, owner, records, options interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Import", reflect.TypeOf((*MockURLs)(nil).Import), ctx, owner, records, options)
+}
+
 // ListByOwner mocks base method.
 func (m *MockURLs) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -215,6 +356,21 @@ func (mr *MockURLsMockRecorder) ListByOwnerAndExpiration(ctx, userId, expired in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwnerAndExpiration", reflect.TypeOf((*MockURLs)(nil).ListByOwnerAndExpiration), ctx, userId, expired)
 }
 
+// ListVariantStats mocks base method.
+func (m *MockURLs) ListVariantStats(ctx context.Context, alias string, owner primitive.ObjectID) ([]domain.VariantStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListVariantStats", ctx, alias, owner)
+	ret0, _ := ret[0].([]domain.VariantStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListVariantStats indicates an expected call of ListVariantStats.
+func (mr *MockURLsMockRecorder) ListVariantStats(ctx, alias, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListVariantStats", reflect.TypeOf((*MockURLs)(nil).ListVariantStats), ctx, alias, owner)
+}
+
 // Prolong mocks base method.
 func (m *MockURLs) Prolong(ctx context.Context, alias string, owner primitive.ObjectID, toProlong domain.URLProlong) (domain.URL, error) {
 	m.ctrl.T.Helper()
@@ -229,3 +385,556 @@ func (mr *MockURLsMockRecorder) Prolong(ctx, alias, owner, toProlong interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prolong", reflect.TypeOf((*MockURLs)(nil).Prolong), ctx, alias, owner, toProlong)
 }
+
+// PromoteVariant mocks base method.
+func (m *MockURLs) PromoteVariant(ctx context.Context, alias string, owner primitive.ObjectID, name string) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteVariant", ctx, alias, owner, name)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteVariant indicates an expected call of PromoteVariant.
+func (mr *MockURLsMockRecorder) PromoteVariant(ctx, alias, owner, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteVariant", reflect.TypeOf((*MockURLs)(nil).PromoteVariant), ctx, alias, owner, name)
+}
+
+// Run mocks base method.
+func (m *MockURLs) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockURLsMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockURLs)(nil).Run), ctx)
+}
+
+// Search mocks base method.
+func (m *MockURLs) Search(ctx context.Context, owner primitive.ObjectID, query string) ([]domain.URLSearchResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, owner, query)
+	ret0, _ := ret[0].([]domain.URLSearchResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockURLsMockRecorder) Search(ctx, owner, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockURLs)(nil).Search), ctx, owner, query)
+}
+
+// SetRules mocks base method.
+func (m *MockURLs) SetRules(ctx context.Context, alias string, owner primitive.ObjectID, rules []domain.RedirectRule) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRules", ctx, alias, owner, rules)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetRules indicates an expected call of SetRules.
+func (mr *MockURLsMockRecorder) SetRules(ctx, alias, owner, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRules", reflect.TypeOf((*MockURLs)(nil).SetRules), ctx, alias, owner, rules)
+}
+
+// SetVariants mocks base method.
+func (m *MockURLs) SetVariants(ctx context.Context, alias string, owner primitive.ObjectID, toSet domain.URLVariants) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariants", ctx, alias, owner, toSet)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVariants indicates an expected call of SetVariants.
+func (mr *MockURLsMockRecorder) SetVariants(ctx, alias, owner, toSet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariants", reflect.TypeOf((*MockURLs)(nil).SetVariants), ctx, alias, owner, toSet)
+}
+
+// TransferOwner mocks base method.
+func (m *MockURLs) TransferOwner(ctx context.Context, owner, successor primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TransferOwner", ctx, owner, successor)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TransferOwner indicates an expected call of TransferOwner.
+func (mr *MockURLsMockRecorder) TransferOwner(ctx, owner, successor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TransferOwner", reflect.TypeOf((*MockURLs)(nil).TransferOwner), ctx, owner, successor)
+}
+
+// MockMetadata is a mock of Metadata interface.
+type MockMetadata struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetadataMockRecorder
+}
+
+// MockMetadataMockRecorder is the mock recorder for MockMetadata.
+type MockMetadataMockRecorder struct {
+	mock *MockMetadata
+}
+
+// NewMockMetadata creates a new mock instance.
+func NewMockMetadata(ctrl *gomock.Controller) *MockMetadata {
+	mock := &MockMetadata{ctrl: ctrl}
+	mock.recorder = &MockMetadataMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetadata) EXPECT() *MockMetadataMockRecorder {
+	return m.recorder
+}
+
+// Enqueue mocks base method.
+func (m *MockMetadata) Enqueue(alias string) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Enqueue", alias)
+}
+
+// Enqueue indicates an expected call of Enqueue.
+func (mr *MockMetadataMockRecorder) Enqueue(alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enqueue", reflect.TypeOf((*MockMetadata)(nil).Enqueue), alias)
+}
+
+// Refresh mocks base method.
+func (m *MockMetadata) Refresh(ctx context.Context, alias string, owner primitive.ObjectID) (domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", ctx, alias, owner)
+	ret0, _ := ret[0].(domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MockMetadataMockRecorder) Refresh(ctx, alias, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MockMetadata)(nil).Refresh), ctx, alias, owner)
+}
+
+// Run mocks base method.
+func (m *MockMetadata) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockMetadataMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockMetadata)(nil).Run), ctx)
+}
+
+// MockHealth is a mock of Health interface.
+type MockHealth struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthMockRecorder
+}
+
+// MockHealthMockRecorder is the mock recorder for MockHealth.
+type MockHealthMockRecorder struct {
+	mock *MockHealth
+}
+
+// NewMockHealth creates a new mock instance.
+func NewMockHealth(ctrl *gomock.Controller) *MockHealth {
+	mock := &MockHealth{ctrl: ctrl}
+	mock.recorder = &MockHealthMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealth) EXPECT() *MockHealthMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockHealth) Check(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockHealthMockRecorder) Check(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockHealth)(nil).Check), ctx)
+}
+
+// ListByOwnerAndHealth mocks base method.
+func (m *MockHealth) ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwnerAndHealth", ctx, userId, broken)
+	ret0, _ := ret[0].([]domain.URL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwnerAndHealth indicates an expected call of ListByOwnerAndHealth.
+func (mr *MockHealthMockRecorder) ListByOwnerAndHealth(ctx, userId, broken interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwnerAndHealth", reflect.TypeOf((*MockHealth)(nil).ListByOwnerAndHealth), ctx, userId, broken)
+}
+
+// Run mocks base method.
+func (m *MockHealth) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockHealthMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockHealth)(nil).Run), ctx)
+}
+
+// MockHealthNotifier is a mock of HealthNotifier interface.
+type MockHealthNotifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockHealthNotifierMockRecorder
+}
+
+// MockHealthNotifierMockRecorder is the mock recorder for MockHealthNotifier.
+type MockHealthNotifierMockRecorder struct {
+	mock *MockHealthNotifier
+}
+
+// NewMockHealthNotifier creates a new mock instance.
+func NewMockHealthNotifier(ctrl *gomock.Controller) *MockHealthNotifier {
+	mock := &MockHealthNotifier{ctrl: ctrl}
+	mock.recorder = &MockHealthNotifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHealthNotifier) EXPECT() *MockHealthNotifierMockRecorder {
+	return m.recorder
+}
+
+// NotifyBroken mocks base method.
+func (m *MockHealthNotifier) NotifyBroken(ctx context.Context, url domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyBroken", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyBroken indicates an expected call of NotifyBroken.
+func (mr *MockHealthNotifierMockRecorder) NotifyBroken(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyBroken", reflect.TypeOf((*MockHealthNotifier)(nil).NotifyBroken), ctx, url)
+}
+
+// MockWebhooks is a mock of Webhooks interface.
+type MockWebhooks struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhooksMockRecorder
+}
+
+// MockWebhooksMockRecorder is the mock recorder for MockWebhooks.
+type MockWebhooksMockRecorder struct {
+	mock *MockWebhooks
+}
+
+// NewMockWebhooks creates a new mock instance.
+func NewMockWebhooks(ctrl *gomock.Controller) *MockWebhooks {
+	mock := &MockWebhooks{ctrl: ctrl}
+	mock.recorder = &MockWebhooksMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhooks) EXPECT() *MockWebhooksMockRecorder {
+	return m.recorder
+}
+
+// Create mocks base method.
+func (m *MockWebhooks) Create(ctx context.Context, toCreate domain.WebhookCreate) (domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, toCreate)
+	ret0, _ := ret[0].(domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockWebhooksMockRecorder) Create(ctx, toCreate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockWebhooks)(nil).Create), ctx, toCreate)
+}
+
+// Delete mocks base method.
+func (m *MockWebhooks) Delete(ctx context.Context, id, owner primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockWebhooksMockRecorder) Delete(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockWebhooks)(nil).Delete), ctx, id, owner)
+}
+
+// Emit mocks base method.
+func (m *MockWebhooks) Emit(ctx context.Context, owner primitive.ObjectID, event string, data interface{}) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Emit", ctx, owner, event, data)
+}
+
+// Emit indicates an expected call of Emit.
+func (mr *MockWebhooksMockRecorder) Emit(ctx, owner, event, data interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Emit", reflect.TypeOf((*MockWebhooks)(nil).Emit), ctx, owner, event, data)
+}
+
+// ListByOwner mocks base method.
+func (m *MockWebhooks) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByOwner", ctx, owner)
+	ret0, _ := ret[0].([]domain.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByOwner indicates an expected call of ListByOwner.
+func (mr *MockWebhooksMockRecorder) ListByOwner(ctx, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByOwner", reflect.TypeOf((*MockWebhooks)(nil).ListByOwner), ctx, owner)
+}
+
+// ListDeliveries mocks base method.
+func (m *MockWebhooks) ListDeliveries(ctx context.Context, id, owner primitive.ObjectID) ([]domain.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListDeliveries", ctx, id, owner)
+	ret0, _ := ret[0].([]domain.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListDeliveries indicates an expected call of ListDeliveries.
+func (mr *MockWebhooksMockRecorder) ListDeliveries(ctx, id, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListDeliveries", reflect.TypeOf((*MockWebhooks)(nil).ListDeliveries), ctx, id, owner)
+}
+
+// NotifyBroken mocks base method.
+func (m *MockWebhooks) NotifyBroken(ctx context.Context, url domain.URL) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NotifyBroken", ctx, url)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NotifyBroken indicates an expected call of NotifyBroken.
+func (mr *MockWebhooksMockRecorder) NotifyBroken(ctx, url interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NotifyBroken", reflect.TypeOf((*MockWebhooks)(nil).NotifyBroken), ctx, url)
+}
+
+// Run mocks base method.
+func (m *MockWebhooks) Run(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Run", ctx)
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockWebhooksMockRecorder) Run(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockWebhooks)(nil).Run), ctx)
+}
+
+// MockAudit is a mock of Audit interface.
+type MockAudit struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditMockRecorder
+}
+
+// MockAuditMockRecorder is the mock recorder for MockAudit.
+type MockAuditMockRecorder struct {
+	mock *MockAudit
+}
+
+// NewMockAudit creates a new mock instance.
+func NewMockAudit(ctrl *gomock.Controller) *MockAudit {
+	mock := &MockAudit{ctrl: ctrl}
+	mock.recorder = &MockAuditMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAudit) EXPECT() *MockAuditMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockAudit) List(ctx context.Context, userId primitive.ObjectID, filter domain.AuditFilter) ([]domain.AuditEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, userId, filter)
+	ret0, _ := ret[0].([]domain.AuditEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockAuditMockRecorder) List(ctx, userId, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAudit)(nil).List), ctx, userId, filter)
+}
+
+// Record mocks base method.
+func (m *MockAudit) Record(ctx context.Context, actor primitive.ObjectID, action, target string, before, after interface{}, ignored ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, actor, action, target, before, after}
+	for _, a := range ignored {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Record", varargs...)
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockAuditMockRecorder) Record(ctx, actor, action, target, before, after interface{}, ignored ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, actor, action, target, before, after}, ignored...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockAudit)(nil).Record), varargs...)
+}
+
+// MockStats is a mock of Stats interface.
+type MockStats struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsMockRecorder
+}
+
+// MockStatsMockRecorder is the mock recorder for MockStats.
+type MockStatsMockRecorder struct {
+	mock *MockStats
+}
+
+// NewMockStats creates a new mock instance.
+func NewMockStats(ctrl *gomock.Controller) *MockStats {
+	mock := &MockStats{ctrl: ctrl}
+	mock.recorder = &MockStatsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStats) EXPECT() *MockStatsMockRecorder {
+	return m.recorder
+}
+
+// DeleteByAlias mocks base method.
+func (m *MockStats) DeleteByAlias(ctx context.Context, alias string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByAlias", ctx, alias)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByAlias indicates an expected call of DeleteByAlias.
+func (mr *MockStatsMockRecorder) DeleteByAlias(ctx, alias interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByAlias", reflect.TypeOf((*MockStats)(nil).DeleteByAlias), ctx, alias)
+}
+
+// Get mocks base method.
+func (m *MockStats) Get(ctx context.Context, owner primitive.ObjectID, query domain.StatsQuery) (domain.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, owner, query)
+	ret0, _ := ret[0].(domain.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatsMockRecorder) Get(ctx, owner, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStats)(nil).Get), ctx, owner, query)
+}
+
+// Record mocks base method.
+func (m *MockStats) Record(ctx context.Context, url domain.URL, click domain.Click) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, url, click)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockStatsMockRecorder) Record(ctx, url, click interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockStats)(nil).Record), ctx, url, click)
+}
+
+// UpdateOwner mocks base method.
+func (m *MockStats) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOwner", ctx, alias, owner)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOwner indicates an expected call of UpdateOwner.
+func (mr *MockStatsMockRecorder) UpdateOwner(ctx, alias, owner interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOwner", reflect.TypeOf((*MockStats)(nil).UpdateOwner), ctx, alias, owner)
+}
+
+// MockReadiness is a mock of Readiness interface.
+type MockReadiness struct {
+	ctrl     *gomock.Controller
+	recorder *MockReadinessMockRecorder
+}
+
+// MockReadinessMockRecorder is the mock recorder for MockReadiness.
+type MockReadinessMockRecorder struct {
+	mock *MockReadiness
+}
+
+// NewMockReadiness creates a new mock instance.
+func NewMockReadiness(ctrl *gomock.Controller) *MockReadiness {
+	mock := &MockReadiness{ctrl: ctrl}
+	mock.recorder = &MockReadinessMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReadiness) EXPECT() *MockReadinessMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockReadiness) Check(ctx context.Context) domain.Readiness {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", ctx)
+	ret0, _ := ret[0].(domain.Readiness)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockReadinessMockRecorder) Check(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockReadiness)(nil).Check), ctx)
+}
+
+// Stop mocks base method.
+func (m *MockReadiness) Stop() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Stop")
+}
+
+// Stop indicates an expected call of Stop.
+func (mr *MockReadinessMockRecorder) Stop() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stop", reflect.TypeOf((*MockReadiness)(nil).Stop))
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Dependency is external system required to serve requests
+type Dependency struct {
+	Name string
+	Ping func(ctx context.Context) error
+}
+
+type ReadinessService struct {
+	dependencies []Dependency
+	timeout      time.Duration
+	stopping     int32
+}
+
+func newReadinessService(dependencies []Dependency, timeout time.Duration) *ReadinessService {
+	return &ReadinessService{
+		dependencies: dependencies,
+		timeout:      timeout,
+	}
+}
+
+// Check pings dependencies concurrently, each of them must respond within timeout.
+// Dependencies are not checked after instance started stopping
+func (s *ReadinessService) Check(ctx context.Context) domain.Readiness {
+	readiness := domain.Readiness{
+		Status:       domain.ReadinessReady,
+		Dependencies: make(map[string]domain.DependencyStatus, len(s.dependencies)),
+	}
+
+	if atomic.LoadInt32(&s.stopping) == 1 {
+		readiness.Status = domain.ReadinessStopping
+
+		return readiness
+	}
+
+	statuses := make([]domain.DependencyStatus, len(s.dependencies))
+
+	var wg sync.WaitGroup
+
+	for i, dependency := range s.dependencies {
+		wg.Add(1)
+
+		go func(i int, dependency Dependency) {
+			defer wg.Done()
+
+			statuses[i] = s.ping(ctx, dependency)
+		}(i, dependency)
+	}
+
+	wg.Wait()
+
+	for i, dependency := range s.dependencies {
+		readiness.Dependencies[dependency.Name] = statuses[i]
+
+		if statuses[i].Status != domain.DependencyUp {
+			readiness.Status = domain.ReadinessNotReady
+		}
+	}
+
+	return readiness
+}
+
+// Stop marks instance as not ready, so no new requests are routed to it during shutdown
+func (s *ReadinessService) Stop() {
+	atomic.StoreInt32(&s.stopping, 1)
+}
+
+func (s *ReadinessService) ping(ctx context.Context, dependency Dependency) domain.DependencyStatus {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if err := dependency.Ping(ctx); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("dependency", dependency.Name).Warn("Dependency is unavailable")
+
+		return domain.DependencyStatus{Status: domain.DependencyDown}
+	}
+
+	return domain.DependencyStatus{Status: domain.DependencyUp}
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestReadinessService_Check(t *testing.T) {
+	up := func(ctx context.Context) error {
+		return nil
+	}
+	down := func(ctx context.Context) error {
+		return errors.New("connection refused")
+	}
+	hanging := func(ctx context.Context) error {
+		<-ctx.Done()
+
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name         string
+		dependencies []Dependency
+		expected     domain.Readiness
+	}{
+		{
+			name:         "ready",
+			dependencies: []Dependency{{Name: "mongo", Ping: up}, {Name: "redis", Ping: up}},
+			expected: domain.Readiness{
+				Status: domain.ReadinessReady,
+				Dependencies: map[string]domain.DependencyStatus{
+					"mongo": {Status: domain.DependencyUp},
+					"redis": {Status: domain.DependencyUp},
+				},
+			},
+		},
+		{
+			name:         "dependency down",
+			dependencies: []Dependency{{Name: "mongo", Ping: up}, {Name: "redis", Ping: down}},
+			expected: domain.Readiness{
+				Status: domain.ReadinessNotReady,
+				Dependencies: map[string]domain.DependencyStatus{
+					"mongo": {Status: domain.DependencyUp},
+					"redis": {Status: domain.DependencyDown},
+				},
+			},
+		},
+		{
+			name:         "dependency timeout",
+			dependencies: []Dependency{{Name: "mongo", Ping: hanging}},
+			expected: domain.Readiness{
+				Status: domain.ReadinessNotReady,
+				Dependencies: map[string]domain.DependencyStatus{
+					"mongo": {Status: domain.DependencyDown},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newReadinessService(tt.dependencies, 10*time.Millisecond)
+
+			readiness := s.Check(context.Background())
+			require.Equal(t, tt.expected, readiness)
+
+			// Errors of drivers are never shown to clients
+			body, err := json.Marshal(readiness)
+			require.NoError(t, err)
+			require.NotContains(t, string(body), "connection refused")
+			require.NotContains(t, string(body), context.DeadlineExceeded.Error())
+		})
+	}
+}
+
+func TestReadinessService_Stop(t *testing.T) {
+	pinged := false
+
+	s := newReadinessService([]Dependency{{Name: "mongo", Ping: func(ctx context.Context) error {
+		pinged = true
+
+		return nil
+	}}}, time.Second)
+
+	s.Stop()
+
+	readiness := s.Check(context.Background())
+
+	require.Equal(t, domain.ReadinessStopping, readiness.Status)
+	require.False(t, readiness.Ready())
+	require.False(t, pinged)
+}
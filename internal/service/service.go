@@ -7,6 +7,10 @@ import (
 	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/pkg/auth"
 	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/mebr0/tiny-url/pkg/mail"
+	"github.com/mebr0/tiny-url/pkg/metadata"
+	"github.com/mebr0/tiny-url/pkg/probe"
+	"github.com/mebr0/tiny-url/pkg/webhook"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"time"
 )
@@ -15,35 +19,171 @@ import (
 
 type Users interface {
 	List(ctx context.Context) ([]domain.User, error)
+	Get(ctx context.Context, id primitive.ObjectID) (domain.User, error)
+	Update(ctx context.Context, id primitive.ObjectID, toUpdate domain.UserUpdate) (domain.User, error)
+	ChangePassword(ctx context.Context, id primitive.ObjectID, toChange domain.PasswordChange) error
+	Delete(ctx context.Context, id primitive.ObjectID, toDelete domain.UserDelete) error
 }
 
 type Auth interface {
 	Register(ctx context.Context, toRegister domain.UserRegister) error
 	Login(ctx context.Context, toLogin domain.UserLogin) (domain.Tokens, error)
+	Verify(ctx context.Context, toVerify domain.UserVerify) error
+	ForgotPassword(ctx context.Context, toForgot domain.PasswordForgot) error
+	ResetPassword(ctx context.Context, toReset domain.PasswordReset) error
 }
 
 type URLs interface {
 	ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.URL, error)
 	ListByOwnerAndExpiration(ctx context.Context, userId primitive.ObjectID, expired bool) ([]domain.URL, error)
+	Search(ctx context.Context, owner primitive.ObjectID, query string) ([]domain.URLSearchResult, error)
 	Create(ctx context.Context, toCreate domain.URLCreate) (domain.URL, error)
 	Get(ctx context.Context, alias string) (domain.URL, error)
 	GetByOwner(ctx context.Context, alias string, owner primitive.ObjectID) (domain.URL, error)
 	Prolong(ctx context.Context, alias string, owner primitive.ObjectID, toProlong domain.URLProlong) (domain.URL, error)
+	SetRules(ctx context.Context, alias string, owner primitive.ObjectID, rules []domain.RedirectRule) (domain.URL, error)
+	SetVariants(ctx context.Context, alias string, owner primitive.ObjectID, toSet domain.URLVariants) (domain.URL, error)
+	ListVariantStats(ctx context.Context, alias string, owner primitive.ObjectID) ([]domain.VariantStats, error)
+	PromoteVariant(ctx context.Context, alias string, owner primitive.ObjectID, name string) (domain.URL, error)
 	Delete(ctx context.Context, alias string, owner primitive.ObjectID) error
+	DeleteByOwner(ctx context.Context, owner primitive.ObjectID) error
+	TransferOwner(ctx context.Context, owner primitive.ObjectID, successor primitive.ObjectID) error
+	Import(ctx context.Context, owner primitive.ObjectID, records []domain.URLRecord,
+		options domain.URLImportOptions) (domain.URLImportResult, error)
+	Click(ctx context.Context, url domain.URL, click domain.Click)
+	Run(ctx context.Context)
+}
+
+type Metadata interface {
+	Enqueue(alias string)
+	Refresh(ctx context.Context, alias string, owner primitive.ObjectID) (domain.URL, error)
+	Run(ctx context.Context)
+}
+
+type Health interface {
+	ListByOwnerAndHealth(ctx context.Context, userId primitive.ObjectID, broken bool) ([]domain.URL, error)
+	Check(ctx context.Context) error
+	Run(ctx context.Context)
+}
+
+// HealthNotifier is notified when destination of url becomes broken
+type HealthNotifier interface {
+	NotifyBroken(ctx context.Context, url domain.URL) error
+}
+
+type Webhooks interface {
+	ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error)
+	Create(ctx context.Context, toCreate domain.WebhookCreate) (domain.Webhook, error)
+	Delete(ctx context.Context, id primitive.ObjectID, owner primitive.ObjectID) error
+	ListDeliveries(ctx context.Context, id primitive.ObjectID, owner primitive.ObjectID) ([]domain.WebhookDelivery, error)
+	Emit(ctx context.Context, owner primitive.ObjectID, event string, data interface{})
+	NotifyBroken(ctx context.Context, url domain.URL) error
+	Run(ctx context.Context)
+}
+
+type Audit interface {
+	List(ctx context.Context, userId primitive.ObjectID, filter domain.AuditFilter) ([]domain.AuditEntry, error)
+	Record(ctx context.Context, actor primitive.ObjectID, action string, target string, before interface{},
+		after interface{}, ignored ...string)
+}
+
+type Stats interface {
+	Get(ctx context.Context, owner primitive.ObjectID, query domain.StatsQuery) (domain.Stats, error)
+	Record(ctx context.Context, url domain.URL, click domain.Click) error
+	UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error
+	DeleteByAlias(ctx context.Context, alias string) error
+}
+
+type Readiness interface {
+	Check(ctx context.Context) domain.Readiness
+	Stop()
 }
 
 type Services struct {
 	Users
 	Auth
 	URLs
+	Metadata
+	Health
+	Webhooks
+	Audit
+	Stats
+	Readiness
+
+	cacheWriter *cacheWriter
+	authService *AuthService
+	urlsService *URLsService
 }
 
-func NewServices(repos *repo.Repos, caches *cache.Caches, hasher hash.PasswordHasher, tokenManager auth.TokenManager,
-	urlEncoder hash.URLEncoder, accessTokenTTL time.Duration, aliasLength int, defaultExpiration int,
-	urlCountLimit int) *Services {
+type Deps struct {
+	Repos               *repo.Repos
+	Caches              *cache.Caches
+	Hasher              hash.PasswordHasher
+	TokenManager        auth.TokenManager
+	URLEncoder          hash.URLEncoder
+	MetadataFetcher     metadata.Fetcher
+	HealthProber        probe.Prober
+	WebhookSender       webhook.Sender
+	Mailer              mail.Sender
+	AccessTokenTTL      time.Duration
+	VerificationTTL     time.Duration
+	PasswordResetTTL    time.Duration
+	MailLinkURL         string
+	UsersDeletionPolicy string
+	UsersSuccessor      primitive.ObjectID
+	AliasLength         int
+	DefaultExpiration   int
+	URLCountLimit       int
+	MetadataWorkers     int
+	MetadataQueueSize   int
+	HealthInterval      time.Duration
+	HealthConcurrency   int
+	ExpirationInterval  time.Duration
+	WebhookWorkers      int
+	WebhookBatchSize    int
+	WebhookPollInterval time.Duration
+	WebhookMaxAttempts  int
+	WebhookBackoff      time.Duration
+	Dependencies        []Dependency
+	ReadinessTimeout    time.Duration
+	AuditAdmins         []string
+}
+
+func NewServices(deps Deps) *Services {
+	cacheWriter := newCacheWriter(deps.Caches.URLs, cacheWriteBackoff)
+	metadataService := newMetadataService(deps.Repos.URLs, cacheWriter, deps.MetadataFetcher, deps.MetadataWorkers,
+		deps.MetadataQueueSize)
+	webhooksService := newWebhooksService(deps.Repos.Webhooks, deps.WebhookSender, deps.WebhookWorkers,
+		deps.WebhookBatchSize, deps.WebhookMaxAttempts, deps.WebhookBackoff, deps.WebhookPollInterval)
+	auditService := newAuditService(deps.Repos.Audit, deps.Repos.Users, deps.AuditAdmins)
+	statsService := newStatsService(deps.Repos.Stats, deps.Repos.URLs)
+	authService := newAuthService(deps.Repos.Users, deps.Repos.Tokens, auditService, deps.Hasher, deps.TokenManager,
+		deps.Mailer, deps.AccessTokenTTL, deps.VerificationTTL, deps.PasswordResetTTL, deps.MailLinkURL)
+	urlsService := newURLsService(deps.Repos.URLs, deps.Caches.URLs, cacheWriter, metadataService, webhooksService,
+		auditService, statsService, deps.URLEncoder, deps.AliasLength, deps.DefaultExpiration, deps.URLCountLimit,
+		deps.ExpirationInterval)
+
 	return &Services{
-		Users: newUsersService(repos.Users),
-		Auth:  newAuthService(repos.Users, hasher, tokenManager, accessTokenTTL),
-		URLs:  newURLsService(repos.URLs, caches.URLs, urlEncoder, aliasLength, defaultExpiration, urlCountLimit),
+		Users: newUsersService(deps.Repos.Users, deps.Repos.Tokens, urlsService, webhooksService, auditService,
+			authService, deps.Hasher, deps.UsersDeletionPolicy, deps.UsersSuccessor),
+		Auth:     authService,
+		URLs:     urlsService,
+		Metadata: metadataService,
+		Health: newHealthService(deps.Repos.URLs, deps.HealthProber, webhooksService, deps.HealthInterval,
+			deps.HealthConcurrency),
+		Webhooks:    webhooksService,
+		Audit:       auditService,
+		Stats:       statsService,
+		Readiness:   newReadinessService(deps.Dependencies, deps.ReadinessTimeout),
+		cacheWriter: cacheWriter,
+		authService: authService,
+		urlsService: urlsService,
 	}
 }
+
+// Wait blocks until clicks, background writes to cache, emails and updates of last login are finished
+func (s *Services) Wait() {
+	s.urlsService.Wait()
+	s.cacheWriter.Wait()
+	s.authService.Wait()
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/hll"
+	"github.com/mebr0/tiny-url/pkg/useragent"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StatsService counts clicks in hourly rollups and aggregates them into series and breakdowns in time zone of user
+type StatsService struct {
+	repo     repo.Stats
+	urlsRepo repo.URLs
+}
+
+func newStatsService(repo repo.Stats, urlsRepo repo.URLs) *StatsService {
+	return &StatsService{
+		repo:     repo,
+		urlsRepo: urlsRepo,
+	}
+}
+
+// Get aggregates clicks of url or of every url of owner if query has no alias
+func (s *StatsService) Get(ctx context.Context, owner primitive.ObjectID, query domain.StatsQuery) (domain.Stats, error) {
+	ctx, span := tracer.Start(ctx, "StatsService.Get")
+	defer span.End()
+
+	if query.Alias != "" {
+		url, err := s.urlsRepo.Get(ctx, query.Alias)
+
+		if err != nil {
+			return domain.Stats{}, err
+		}
+
+		if url.Owner != owner {
+			return domain.Stats{}, ErrURLForbidden
+		}
+	}
+
+	// Rollups are hourly, so range is widened to whole hours
+	from := query.From.UTC().Truncate(time.Hour)
+	to := query.To.UTC().Add(time.Hour - time.Nanosecond).Truncate(time.Hour)
+
+	rollups, err := s.repo.List(ctx, domain.StatsFilter{
+		Owner: owner,
+		Alias: query.Alias,
+		From:  from,
+		To:    to,
+	})
+
+	if err != nil {
+		return domain.Stats{}, err
+	}
+
+	stats := domain.Stats{
+		Alias:    query.Alias,
+		From:     from.In(query.Location),
+		To:       to.In(query.Location),
+		Timezone: query.Location.String(),
+		Interval: query.Interval,
+		Series:   make([]domain.StatsPoint, 0),
+	}
+
+	// Every bucket of range is present in series even without clicks
+	points := make(map[int64]int)
+	sketches := make([]*hll.Sketch, 0)
+
+	for bucket := statsBucket(from, query.Location, query.Interval); bucket.Before(to); bucket = nextStatsBucket(bucket, query.Interval) {
+		points[bucket.Unix()] = len(stats.Series)
+		stats.Series = append(stats.Series, domain.StatsPoint{Time: bucket})
+		sketches = append(sketches, hll.New())
+	}
+
+	total := hll.New()
+	referrers := make(map[string]int64)
+	countries := make(map[string]int64)
+	browsers := make(map[string]int64)
+	oses := make(map[string]int64)
+
+	for _, rollup := range rollups {
+		i, ok := points[statsBucket(rollup.Hour, query.Location, query.Interval).Unix()]
+
+		if !ok {
+			continue
+		}
+
+		stats.Series[i].Clicks += rollup.Clicks
+		stats.Series[i].Bots += rollup.Bots
+		stats.Clicks += rollup.Clicks
+		stats.Bots += rollup.Bots
+
+		for register, rank := range rollup.Visitors {
+			if register, err := strconv.Atoi(register); err == nil {
+				sketches[i].Observe(register, rank)
+			}
+		}
+
+		addCounts(referrers, rollup.Referrers)
+		addCounts(countries, rollup.Countries)
+		addCounts(browsers, rollup.Browsers)
+		addCounts(oses, rollup.OS)
+	}
+
+	for i, sketch := range sketches {
+		stats.Series[i].Visitors = int64(sketch.Count())
+		total.Merge(sketch)
+	}
+
+	stats.Visitors = int64(total.Count())
+	stats.Referrers = topCounts(referrers, query.Limit)
+	stats.Countries = topCounts(countries, query.Limit)
+	stats.Browsers = topCounts(browsers, query.Limit)
+	stats.OS = topCounts(oses, query.Limit)
+
+	return stats, nil
+}
+
+// Record counts click of url in rollup of its hour, only hash of visitor is kept.
+// Clicks of bots are counted apart from clicks of people without their details
+func (s *StatsService) Record(ctx context.Context, url domain.URL, click domain.Click) error {
+	ctx, span := startSpan(ctx, "StatsService.Record", url.Alias)
+	defer span.End()
+
+	hour := click.Time.UTC().Truncate(time.Hour)
+
+	if click.Bot {
+		return s.repo.AddClick(ctx, domain.StatsHit{Alias: url.Alias, Owner: url.Owner, Hour: hour, Bot: true})
+	}
+
+	register, rank := hll.Position(hll.Hash(click.Visitor))
+
+	return s.repo.AddClick(ctx, domain.StatsHit{
+		Alias:           url.Alias,
+		Owner:           url.Owner,
+		Hour:            hour,
+		Referrer:        valueOr(click.Referrer, domain.ReferrerDirect),
+		Country:         valueOr(click.Country, domain.CountryUnknown),
+		Browser:         valueOr(click.Browser, useragent.BrowserOther),
+		OS:              valueOr(click.OS, useragent.OSOther),
+		VisitorRegister: register,
+		VisitorRank:     rank,
+	})
+}
+
+// UpdateOwner gives clicks of url to its new owner
+func (s *StatsService) UpdateOwner(ctx context.Context, alias string, owner primitive.ObjectID) error {
+	return s.repo.UpdateOwner(ctx, alias, owner)
+}
+
+// DeleteByAlias removes clicks of deleted url, so they are not counted for url created with the same alias
+func (s *StatsService) DeleteByAlias(ctx context.Context, alias string) error {
+	return s.repo.DeleteByAlias(ctx, alias)
+}
+
+// statsBucket returns start of bucket containing t in time zone, weeks start on Monday
+func statsBucket(t time.Time, location *time.Location, interval string) time.Time {
+	t = t.In(location)
+
+	switch interval {
+	case domain.StatsHour:
+		// Offset of time zone may be not whole hours
+		return t.Add(-time.Duration(t.Minute())*time.Minute - time.Duration(t.Second())*time.Second -
+			time.Duration(t.Nanosecond()))
+	case domain.StatsWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, location)
+	}
+}
+
+// nextStatsBucket returns start of bucket following bucket, days may be shorter or longer than 24 hours
+func nextStatsBucket(bucket time.Time, interval string) time.Time {
+	switch interval {
+	case domain.StatsHour:
+		return bucket.Add(time.Hour)
+	case domain.StatsWeek:
+		return bucket.AddDate(0, 0, 7)
+	default:
+		return bucket.AddDate(0, 0, 1)
+	}
+}
+
+func addCounts(to map[string]int64, counts map[string]int64) {
+	for value, count := range counts {
+		to[value] += count
+	}
+}
+
+// topCounts returns at most limit values with the most clicks, ties are ordered by value
+func topCounts(counts map[string]int64, limit int) []domain.StatsCount {
+	top := make([]domain.StatsCount, 0, len(counts))
+
+	for value, count := range counts {
+		top = append(top, domain.StatsCount{Value: value, Clicks: count})
+	}
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Clicks == top[j].Clicks {
+			return top[i].Value < top[j].Value
+		}
+
+		return top[i].Clicks > top[j].Clicks
+	})
+
+	if len(top) > limit {
+		top = top[:limit]
+	}
+
+	return top
+}
+
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	"github.com/mebr0/tiny-url/pkg/hll"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+	"time"
+)
+
+func mockStatsService(t *testing.T) (*StatsService, *mockRepo.MockStats, *mockRepo.MockURLs) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	statsRepo := mockRepo.NewMockStats(mockCtl)
+	urlsRepo := mockRepo.NewMockURLs(mockCtl)
+
+	service := newStatsService(statsRepo, urlsRepo)
+
+	return service, statsRepo, urlsRepo
+}
+
+func TestStatsService_Get(t *testing.T) {
+	s, statsRepo, urlsRepo := mockStatsService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	almaty := time.FixedZone("Asia/Almaty", 6*60*60)
+
+	urlsRepo.EXPECT().Get(gomock.Any(), "qwerty").Return(domain.URL{Alias: "qwerty", Owner: owner}, nil)
+	statsRepo.EXPECT().List(gomock.Any(), domain.StatsFilter{
+		Owner: owner,
+		Alias: "qwerty",
+		From:  time.Date(2021, 5, 8, 18, 0, 0, 0, time.UTC),
+		To:    time.Date(2021, 5, 10, 18, 0, 0, 0, time.UTC),
+	}).Return([]domain.StatsRollup{
+		{
+			Hour:      time.Date(2021, 5, 8, 20, 0, 0, 0, time.UTC),
+			Clicks:    2,
+			Referrers: map[string]int64{"google.com": 2},
+			Countries: map[string]int64{"KZ": 2},
+			Browsers:  map[string]int64{"chrome": 2},
+			OS:        map[string]int64{"android": 2},
+			Visitors:  map[string]uint8{"1": 3},
+		},
+		{
+			// The same visitor at the end of the same local day
+			Hour:      time.Date(2021, 5, 9, 17, 0, 0, 0, time.UTC),
+			Clicks:    1,
+			Referrers: map[string]int64{"google.com": 1},
+			Countries: map[string]int64{"KZ": 1},
+			Browsers:  map[string]int64{"chrome": 1},
+			OS:        map[string]int64{"android": 1},
+			Visitors:  map[string]uint8{"1": 3},
+		},
+		{
+			Hour:      time.Date(2021, 5, 9, 18, 0, 0, 0, time.UTC),
+			Clicks:    3,
+			Bots:      4,
+			Referrers: map[string]int64{domain.ReferrerDirect: 3},
+			Countries: map[string]int64{"US": 3},
+			Browsers:  map[string]int64{"firefox": 3},
+			OS:        map[string]int64{"linux": 3},
+			Visitors:  map[string]uint8{"2": 1},
+		},
+	}, nil)
+
+	res, err := s.Get(ctx, owner, domain.StatsQuery{
+		Alias:    "qwerty",
+		From:     time.Date(2021, 5, 9, 0, 20, 0, 0, almaty),
+		To:       time.Date(2021, 5, 11, 0, 0, 0, 0, almaty),
+		Location: almaty,
+		Interval: domain.StatsDay,
+		Limit:    1,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.Stats{
+		Alias:    "qwerty",
+		From:     time.Date(2021, 5, 9, 0, 0, 0, 0, almaty),
+		To:       time.Date(2021, 5, 11, 0, 0, 0, 0, almaty),
+		Timezone: "Asia/Almaty",
+		Interval: domain.StatsDay,
+		Clicks:   6,
+		Bots:     4,
+		Visitors: 2,
+		Series: []domain.StatsPoint{
+			{Time: time.Date(2021, 5, 9, 0, 0, 0, 0, almaty), Clicks: 3, Visitors: 1},
+			{Time: time.Date(2021, 5, 10, 0, 0, 0, 0, almaty), Clicks: 3, Bots: 4, Visitors: 1},
+		},
+		// Ties are ordered by value
+		Referrers: []domain.StatsCount{{Value: domain.ReferrerDirect, Clicks: 3}},
+		Countries: []domain.StatsCount{{Value: "KZ", Clicks: 3}},
+		Browsers:  []domain.StatsCount{{Value: "chrome", Clicks: 3}},
+		OS:        []domain.StatsCount{{Value: "android", Clicks: 3}},
+	}, res)
+}
+
+func TestStatsService_GetEmptyWeeks(t *testing.T) {
+	s, statsRepo, _ := mockStatsService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	statsRepo.EXPECT().List(gomock.Any(), gomock.Any()).Return([]domain.StatsRollup{}, nil)
+
+	// 2021-05-12 is Wednesday
+	res, err := s.Get(ctx, owner, domain.StatsQuery{
+		From:     time.Date(2021, 5, 12, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2021, 5, 24, 0, 0, 0, 0, time.UTC),
+		Location: time.UTC,
+		Interval: domain.StatsWeek,
+		Limit:    10,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, []domain.StatsPoint{
+		{Time: time.Date(2021, 5, 10, 0, 0, 0, 0, time.UTC)},
+		{Time: time.Date(2021, 5, 17, 0, 0, 0, 0, time.UTC)},
+	}, res.Series)
+	require.Empty(t, res.Referrers)
+}
+
+func TestStatsService_GetErrURLForbidden(t *testing.T) {
+	s, _, urlsRepo := mockStatsService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().Get(gomock.Any(), "qwerty").Return(domain.URL{Alias: "qwerty", Owner: primitive.NewObjectID()}, nil)
+
+	_, err := s.Get(ctx, primitive.NewObjectID(), domain.StatsQuery{Alias: "qwerty", Location: time.UTC})
+
+	require.ErrorIs(t, err, ErrURLForbidden)
+}
+
+func TestStatsService_Record(t *testing.T) {
+	s, statsRepo, _ := mockStatsService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	register, rank := hll.Position(hll.Hash("192.0.2.1 curl/7.68.0"))
+
+	statsRepo.EXPECT().AddClick(gomock.Any(), domain.StatsHit{
+		Alias:           "qwerty",
+		Owner:           owner,
+		Hour:            time.Date(2021, 5, 9, 9, 0, 0, 0, time.UTC),
+		Referrer:        domain.ReferrerDirect,
+		Country:         domain.CountryUnknown,
+		Browser:         "other",
+		OS:              "other",
+		VisitorRegister: register,
+		VisitorRank:     rank,
+	}).Return(nil)
+
+	err := s.Record(ctx, domain.URL{Alias: "qwerty", Owner: owner}, domain.Click{
+		Time:    time.Date(2021, 5, 9, 15, 29, 18, 0, time.FixedZone("", 6*60*60)),
+		Visitor: "192.0.2.1 curl/7.68.0",
+	})
+
+	require.NoError(t, err)
+}
+
+func TestStatsService_RecordBot(t *testing.T) {
+	s, statsRepo, _ := mockStatsService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	statsRepo.EXPECT().AddClick(gomock.Any(), domain.StatsHit{
+		Alias: "qwerty",
+		Owner: owner,
+		Hour:  time.Date(2021, 5, 9, 9, 0, 0, 0, time.UTC),
+		Bot:   true,
+	}).Return(nil)
+
+	err := s.Record(ctx, domain.URL{Alias: "qwerty", Owner: owner}, domain.Click{
+		Time:     time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC),
+		Referrer: "slack.com",
+		Visitor:  "192.0.2.1 Slackbot-LinkExpanding 1.0",
+		Bot:      true,
+	})
+
+	require.NoError(t, err)
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/mebr0/tiny-url/internal/service")
+
+// startSpan starts span of service method working with url by alias
+func startSpan(ctx context.Context, name string, alias string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name, trace.WithAttributes(aliasAttribute(alias)))
+}
+
+func aliasAttribute(alias string) attribute.KeyValue {
+	return attribute.String("url.alias", alias)
+}
+
+// cacheResultAttribute whether url was found in cache: hit, missing or miss
+func cacheResultAttribute(result string) attribute.KeyValue {
+	return attribute.String("cache.result", result)
+}
@@ -2,33 +2,60 @@ package service
 
 import (
 	"context"
-	"github.com/go-redis/redis/v8"
 	"github.com/mebr0/tiny-url/internal/cache"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/metrics"
 	"github.com/mebr0/tiny-url/internal/repo"
 	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/mebr0/tiny-url/pkg/highlight"
+	"github.com/mebr0/tiny-url/pkg/urlutil"
 	log "github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/sync/singleflight"
+	"sync"
 	"time"
 )
 
+// Max count of URLs returned by search
+const searchLimit = 50
+
 type URLsService struct {
-	repo              repo.URLs
-	cache             cache.URLs
-	urlEncoder        hash.URLEncoder
-	aliasLength       int
-	defaultExpiration int
-	urlCountLimit     int
+	repo               repo.URLs
+	cache              cache.URLs
+	cacheWriter        *cacheWriter
+	metadata           Metadata
+	webhooks           Webhooks
+	audit              Audit
+	stats              Stats
+	urlEncoder         hash.URLEncoder
+	aliasLength        int
+	defaultExpiration  int
+	urlCountLimit      int
+	expirationInterval time.Duration
+
+	// Concurrent loads of the same missed alias share one query to database
+	loads singleflight.Group
+	// Clicks counted in background, finished on shutdown
+	clicks sync.WaitGroup
 }
 
-func newURLsService(repo repo.URLs, cache cache.URLs, urlEncoder hash.URLEncoder, aliasLength int, defaultExpiration int, urlCountLimit int) *URLsService {
+func newURLsService(repo repo.URLs, cache cache.URLs, cacheWriter *cacheWriter, metadata Metadata, webhooks Webhooks,
+	audit Audit, stats Stats, urlEncoder hash.URLEncoder, aliasLength int, defaultExpiration int, urlCountLimit int,
+	expirationInterval time.Duration) *URLsService {
 	return &URLsService{
-		repo:              repo,
-		cache:             cache,
-		urlEncoder:        urlEncoder,
-		aliasLength:       aliasLength,
-		defaultExpiration: defaultExpiration,
-		urlCountLimit:     urlCountLimit,
+		repo:               repo,
+		cache:              cache,
+		cacheWriter:        cacheWriter,
+		metadata:           metadata,
+		webhooks:           webhooks,
+		audit:              audit,
+		stats:              stats,
+		urlEncoder:         urlEncoder,
+		aliasLength:        aliasLength,
+		defaultExpiration:  defaultExpiration,
+		urlCountLimit:      urlCountLimit,
+		expirationInterval: expirationInterval,
 	}
 }
 
@@ -40,9 +67,41 @@ func (s *URLsService) ListByOwnerAndExpiration(ctx context.Context, userId primi
 	return s.repo.ListByOwnerAndExpiration(ctx, userId, expired)
 }
 
+// Search finds urls of owner by alias, original URL, tags and title ordered by relevance
+func (s *URLsService) Search(ctx context.Context, owner primitive.ObjectID, query string) ([]domain.URLSearchResult, error) {
+	ctx, span := tracer.Start(ctx, "URLsService.Search")
+	defer span.End()
+
+	results, err := s.repo.Search(ctx, owner, query, searchLimit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	terms := highlight.Terms(query)
+
+	for i := range results {
+		results[i].Highlights = highlights(results[i].URL, terms)
+	}
+
+	return results, nil
+}
+
 func (s *URLsService) Create(ctx context.Context, toCreate domain.URLCreate) (domain.URL, error) {
+	ctx, span := tracer.Start(ctx, "URLsService.Create")
+	defer span.End()
+
+	// Add UTM parameters to original URL
+	original, err := urlutil.SetQuery(toCreate.Original, toCreate.UTM.Values())
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	toCreate.Original = original
+
 	// Get URL from database
-	_, err := s.repo.GetByOriginalAndOwner(ctx, toCreate.Original, toCreate.Owner)
+	_, err = s.repo.GetByOriginalAndOwner(ctx, toCreate.Original, toCreate.Owner)
 
 	// If other error than not found return it
 	if err != nil && err != repo.ErrURLNotFound {
@@ -94,48 +153,97 @@ func (s *URLsService) Create(ctx context.Context, toCreate domain.URLCreate) (do
 			}
 
 			// If alias already exists try one more
-			log.Warn("Could not create alias")
+			logging.FromContext(ctx).WithField("try", try).Warn("Could not create alias, it already exists")
+			metrics.AliasCollisions.Inc()
 			try += 1
 
 			continue
 		}
 
-		return s.repo.Get(ctx, id)
+		span.SetAttributes(aliasAttribute(id))
+
+		// Alias may be cached as missing, so it is dropped before URL is returned to owner
+		s.cacheWriter.Delete(ctx, id)
+
+		// Fetch metadata of original URL in background
+		s.metadata.Enqueue(id)
+
+		created, err := s.repo.Get(ctx, id)
+
+		if err != nil {
+			return domain.URL{}, err
+		}
+
+		s.audit.Record(ctx, created.Owner, domain.AuditURLCreated, id, nil, created, auditIgnoredURLFields...)
+		s.webhooks.Emit(ctx, created.Owner, domain.EventURLCreated, created)
+
+		return created, nil
 	}
 
 	return domain.URL{}, ErrNoPossibleAliasEncoding
 }
 
 func (s *URLsService) Get(ctx context.Context, alias string) (domain.URL, error) {
+	ctx, span := startSpan(ctx, "URLsService.Get", alias)
+	defer span.End()
+
 	// Get URL from cache
 	url, err := s.cache.Get(ctx, alias)
 
 	if err == nil {
+		metrics.URLCacheRequests.WithLabelValues(metrics.ResultHit).Inc()
+		span.SetAttributes(cacheResultAttribute(metrics.ResultHit))
+
 		return url, nil
 	}
 
-	if err != redis.Nil {
-		log.Warn("Error while get from cache " + err.Error())
+	// Alias is recently checked to be missing in database
+	if err == cache.ErrMissing {
+		metrics.URLCacheRequests.WithLabelValues(metrics.ResultMissing).Inc()
+		span.SetAttributes(cacheResultAttribute(metrics.ResultMissing))
+
+		return domain.URL{}, repo.ErrURLNotFound
 	}
 
-	// Get URL from database
-	url, err = s.repo.Get(ctx, alias)
+	metrics.URLCacheRequests.WithLabelValues(metrics.ResultMiss).Inc()
+	span.SetAttributes(cacheResultAttribute(metrics.ResultMiss))
 
-	if err != nil {
-		return domain.URL{}, err
+	if err != cache.ErrNotFound {
+		logging.FromContext(ctx).WithError(err).WithField("alias", alias).Warn("Could not get url from cache")
 	}
 
-	// Async update cache
-	go func() {
-		c, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+	// Get URL from database once for all concurrent misses. Load is shared, so it is not cancelled with request
+	// that started it, while every request still waits for it only until it is cancelled
+	loads := s.loads.DoChan(alias, func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(logging.Detach(ctx), time.Duration(5)*time.Second)
 		defer cancel()
 
-		if err := s.cache.Set(c, url); err != nil {
-			log.Warn("Could not save to cache " + err.Error())
+		url, err := s.repo.Get(ctx, alias)
+
+		// Missing alias is cached too, so scanning of random aliases does not reach database
+		if err == repo.ErrURLNotFound {
+			s.cacheWriter.FillMissing(ctx, alias)
 		}
-	}()
 
-	return url, nil
+		if err != nil {
+			return domain.URL{}, err
+		}
+
+		s.cacheWriter.Fill(ctx, url)
+
+		return url, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return domain.URL{}, ctx.Err()
+	case loaded := <-loads:
+		if loaded.Err != nil {
+			return domain.URL{}, loaded.Err
+		}
+
+		return loaded.Val.(domain.URL), nil
+	}
 }
 
 func (s *URLsService) GetByOwner(ctx context.Context, alias string, owner primitive.ObjectID) (domain.URL, error) {
@@ -154,7 +262,12 @@ func (s *URLsService) GetByOwner(ctx context.Context, alias string, owner primit
 }
 
 func (s *URLsService) Prolong(ctx context.Context, alias string, owner primitive.ObjectID, toProlong domain.URLProlong) (domain.URL, error) {
-	if _, err := s.GetByOwner(ctx, alias, owner); err != nil {
+	ctx, span := startSpan(ctx, "URLsService.Prolong", alias)
+	defer span.End()
+
+	before, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
 		return domain.URL{}, err
 	}
 
@@ -162,37 +275,355 @@ func (s *URLsService) Prolong(ctx context.Context, alias string, owner primitive
 		return domain.URL{}, err
 	}
 
-	// Async update cache
-	go func() {
-		c, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
-		defer cancel()
+	url, err := s.refreshAudited(ctx, alias, owner, domain.AuditURLProlonged, before)
 
-		if err := s.cache.Delete(c, alias); err != nil {
-			log.Warn("Could not delete from cache " + err.Error())
-		}
-	}()
+	if err != nil {
+		return domain.URL{}, err
+	}
 
-	return s.GetByOwner(ctx, alias, owner)
+	s.webhooks.Emit(ctx, owner, domain.EventURLProlonged, url)
+
+	return url, nil
+}
+
+func (s *URLsService) SetRules(ctx context.Context, alias string, owner primitive.ObjectID, rules []domain.RedirectRule) (domain.URL, error) {
+	ctx, span := startSpan(ctx, "URLsService.SetRules", alias)
+	defer span.End()
+
+	before, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if err := s.repo.UpdateRules(ctx, alias, rules); err != nil {
+		return domain.URL{}, err
+	}
+
+	return s.refreshAudited(ctx, alias, owner, domain.AuditURLRulesUpdated, before)
+}
+
+// SetVariants replaces variants of url, clicks of previous variants are discarded
+func (s *URLsService) SetVariants(ctx context.Context, alias string, owner primitive.ObjectID, toSet domain.URLVariants) (domain.URL, error) {
+	ctx, span := startSpan(ctx, "URLsService.SetVariants", alias)
+	defer span.End()
+
+	before, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	if err := s.repo.UpdateVariants(ctx, alias, domain.NewVariants(toSet.Variants), toSet.Sticky); err != nil {
+		return domain.URL{}, err
+	}
+
+	return s.refreshAudited(ctx, alias, owner, domain.AuditURLVariantsUpdated, before)
+}
+
+func (s *URLsService) ListVariantStats(ctx context.Context, alias string, owner primitive.ObjectID) ([]domain.VariantStats, error) {
+	url, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return url.VariantStats(), nil
+}
+
+// PromoteVariant makes destination of variant original URL and removes all variants
+func (s *URLsService) PromoteVariant(ctx context.Context, alias string, owner primitive.ObjectID, name string) (domain.URL, error) {
+	ctx, span := startSpan(ctx, "URLsService.PromoteVariant", alias)
+	defer span.End()
+
+	url, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	variant, ok := url.Variant(name)
+
+	if !ok {
+		return domain.URL{}, ErrVariantNotFound
+	}
+
+	if err := s.repo.CollapseVariants(ctx, alias, variant.Destination); err != nil {
+		return domain.URL{}, err
+	}
+
+	// Original URL is changed, so its metadata is outdated
+	s.metadata.Enqueue(alias)
+
+	return s.refreshAudited(ctx, alias, owner, domain.AuditURLVariantPromoted, url)
 }
 
 func (s *URLsService) Delete(ctx context.Context, alias string, owner primitive.ObjectID) error {
-	if _, err := s.GetByOwner(ctx, alias, owner); err != nil {
+	ctx, span := startSpan(ctx, "URLsService.Delete", alias)
+	defer span.End()
+
+	url, err := s.GetByOwner(ctx, alias, owner)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.remove(ctx, url); err != nil {
+		return err
+	}
+
+	s.webhooks.Emit(ctx, owner, domain.EventURLDeleted, url)
+
+	return nil
+}
+
+// DeleteByOwner deletes every url of owner, webhooks are not notified since they are deleted along with owner
+func (s *URLsService) DeleteByOwner(ctx context.Context, owner primitive.ObjectID) error {
+	ctx, span := tracer.Start(ctx, "URLsService.DeleteByOwner")
+	defer span.End()
+
+	urls, err := s.repo.ListByOwner(ctx, owner)
+
+	if err != nil {
 		return err
 	}
 
-	if err := s.repo.Delete(ctx, alias); err != nil {
+	for _, url := range urls {
+		if err := s.remove(ctx, url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// TransferOwner gives every url of owner with its statistics to successor
+func (s *URLsService) TransferOwner(ctx context.Context, owner primitive.ObjectID, successor primitive.ObjectID) error {
+	ctx, span := tracer.Start(ctx, "URLsService.TransferOwner")
+	defer span.End()
+
+	urls, err := s.repo.ListByOwner(ctx, owner)
+
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		// Statistics go first, so url left to owner on failure is transferred with them on retry
+		if err := s.stats.UpdateOwner(ctx, url.Alias, successor); err != nil {
+			return err
+		}
+
+		if err := s.repo.UpdateOwner(ctx, url.Alias, successor); err != nil {
+			return err
+		}
+
+		if _, err := s.refreshAudited(ctx, url.Alias, owner, domain.AuditURLTransferred, url); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// remove deletes url with its statistics and makes its alias missing in cache
+func (s *URLsService) remove(ctx context.Context, url domain.URL) error {
+	if err := s.repo.Delete(ctx, url.Alias); err != nil {
 		return err
 	}
 
-	// Async update cache
+	s.cacheWriter.SetMissing(ctx, url.Alias)
+
+	// Stale statistics are only wasted space, so deletion of url is not failed by them
+	if err := s.stats.DeleteByAlias(ctx, url.Alias); err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("alias", url.Alias).Warn("Could not delete statistics of url")
+	}
+
+	s.audit.Record(ctx, url.Owner, domain.AuditURLDeleted, url.Alias, url, nil, auditIgnoredURLFields...)
+
+	return nil
+}
+
+// refresh reads changed url from database and writes it to cache
+func (s *URLsService) refresh(ctx context.Context, alias string) (domain.URL, error) {
+	url, err := s.repo.Get(ctx, alias)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	s.cacheWriter.Set(ctx, url)
+
+	return url, nil
+}
+
+// refreshAudited refreshes changed url and records action of owner with changes since before
+func (s *URLsService) refreshAudited(ctx context.Context, alias string, owner primitive.ObjectID, action string,
+	before domain.URL) (domain.URL, error) {
+	url, err := s.refresh(ctx, alias)
+
+	if err != nil {
+		return domain.URL{}, err
+	}
+
+	s.audit.Record(ctx, owner, action, alias, before, url, auditIgnoredURLFields...)
+
+	return url, nil
+}
+
+// Click counts redirection with url and its variant if any in total and in statistics,
+// emits event when clicks reach milestone. Requests of bots are counted only in statistics
+func (s *URLsService) Click(ctx context.Context, url domain.URL, click domain.Click) {
+	ctx, span := startSpan(ctx, "URLsService.Click", url.Alias)
+
+	s.clicks.Add(1)
+
+	// Async update clicks, continuing trace and log of request
 	go func() {
-		c, cancel := context.WithTimeout(context.Background(), time.Duration(5)*time.Second)
+		defer s.clicks.Done()
+		defer span.End()
+
+		c, cancel := context.WithTimeout(logging.Detach(ctx), time.Duration(5)*time.Second)
 		defer cancel()
 
-		if err := s.cache.Delete(c, alias); err != nil {
-			log.Warn("Could not delete from cache " + err.Error())
+		// Bots do not consume clicks of url, they are only counted in statistics
+		if !click.Bot {
+			clicks, err := s.repo.IncrementClicks(c, url.Alias, click.Variant)
+
+			if err != nil {
+				logging.FromContext(c).WithError(err).WithField("alias", url.Alias).Warn("Could not increment clicks of url")
+				return
+			}
+
+			if isClickMilestone(clicks) {
+				url.Clicks = clicks
+
+				s.webhooks.Emit(c, url.Owner, domain.EventURLMilestone, url)
+			}
+		}
+
+		if err := s.stats.Record(c, url, click); err != nil {
+			logging.FromContext(c).WithError(err).WithField("alias", url.Alias).Warn("Could not record statistics of url")
 		}
 	}()
+}
+
+// Wait blocks until clicks counted in background are finished
+func (s *URLsService) Wait() {
+	s.clicks.Wait()
+}
+
+// Run emits events about expired urls every expiration interval until ctx is done
+func (s *URLsService) Run(ctx context.Context) {
+	if s.expirationInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.expirationInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.notifyExpired(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Could not notify about expired urls")
+		}
+
+		if err := s.countURLs(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Warn("Could not count urls")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *URLsService) notifyExpired(ctx context.Context) error {
+	urls, err := s.repo.ListNewlyExpired(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	for _, url := range urls {
+		if err := s.repo.SetExpirationNotified(ctx, url.Alias); err != nil {
+			return err
+		}
+
+		s.webhooks.Emit(ctx, url.Owner, domain.EventURLExpired, url)
+	}
+
+	return nil
+}
+
+// countURLs updates gauges of active and expired urls
+func (s *URLsService) countURLs(ctx context.Context) error {
+	active, err := s.repo.CountByExpiration(ctx, false)
+
+	if err != nil {
+		return err
+	}
+
+	expired, err := s.repo.CountByExpiration(ctx, true)
+
+	if err != nil {
+		return err
+	}
+
+	metrics.URLs.WithLabelValues(metrics.StateActive).Set(float64(active))
+	metrics.URLs.WithLabelValues(metrics.StateExpired).Set(float64(expired))
 
 	return nil
 }
+
+// highlights finds terms in searchable fields of url
+func highlights(url domain.URL, terms []string) []domain.Highlight {
+	// Pairs of field name and value
+	fields := [][2]string{
+		{"alias", url.Alias},
+		{"original", url.Original},
+		{"title", url.Metadata.Title},
+	}
+
+	for _, tag := range url.Tags {
+		fields = append(fields, [2]string{"tags", tag})
+	}
+
+	result := make([]domain.Highlight, 0)
+
+	for _, field := range fields {
+		ranges := highlight.Find(field[1], terms)
+
+		if len(ranges) == 0 {
+			continue
+		}
+
+		matches := make([]domain.TextRange, 0, len(ranges))
+
+		for _, r := range ranges {
+			matches = append(matches, domain.TextRange{Start: r.Start, End: r.End})
+		}
+
+		result = append(result, domain.Highlight{
+			Field:   field[0],
+			Value:   field[1],
+			Matches: matches,
+		})
+	}
+
+	return result
+}
+
+// isClickMilestone whether clicks count is power of ten starting from 10
+func isClickMilestone(clicks int64) bool {
+	if clicks < 10 {
+		return false
+	}
+
+	for clicks%10 == 0 {
+		clicks /= 10
+	}
+
+	return clicks == 1
+}
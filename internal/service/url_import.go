@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"github.com/go-playground/validator/v10"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/hash"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Imported aliases keep characters safe in path of redirection
+var aliasPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Imported records are checked by the same binding tags as request bodies
+var recordValidator = newRecordValidator()
+
+// urlImport is progress of one import
+type urlImport struct {
+	options domain.URLImportOptions
+	// Count of urls of owner including already imported ones
+	count int
+	// Aliases used by previous rows
+	taken map[string]bool
+}
+
+// Import creates urls of owner from records of file, every row is imported or rejected on its own.
+// Row conflicts with url having its alias or, if alias is empty, with url of owner having its original URL.
+// Dry run resolves every row the same way without changing any url
+func (s *URLsService) Import(ctx context.Context, owner primitive.ObjectID, records []domain.URLRecord,
+	options domain.URLImportOptions) (domain.URLImportResult, error) {
+	ctx, span := tracer.Start(ctx, "URLsService.Import")
+	defer span.End()
+
+	urls, err := s.ListByOwner(ctx, owner)
+
+	if err != nil {
+		return domain.URLImportResult{}, err
+	}
+
+	imp := &urlImport{
+		options: options,
+		count:   len(urls),
+		taken:   make(map[string]bool),
+	}
+
+	result := domain.URLImportResult{
+		DryRun: options.DryRun,
+		Rows:   make([]domain.URLImportRow, 0, len(records)),
+	}
+
+	for i, record := range records {
+		row, err := s.importRecord(ctx, owner, record, imp)
+
+		// Rejected row is reported, other errors stop import
+		var rejected *domain.Error
+
+		if errors.As(err, &rejected) {
+			row = domain.URLImportRow{Status: domain.ImportFailed, Error: rejected.Message}
+		} else if err != nil {
+			return domain.URLImportResult{}, err
+		}
+
+		row.Row = i + 1
+
+		switch row.Status {
+		case domain.ImportCreated, domain.ImportRenamed:
+			result.Created++
+		case domain.ImportOverwritten:
+			result.Overwritten++
+		case domain.ImportSkipped:
+			result.Skipped++
+		case domain.ImportFailed:
+			result.Failed++
+		}
+
+		result.Rows = append(result.Rows, row)
+	}
+
+	return result, nil
+}
+
+func (s *URLsService) importRecord(ctx context.Context, owner primitive.ObjectID, record domain.URLRecord,
+	imp *urlImport) (domain.URLImportRow, error) {
+	if err := validateRecord(record); err != nil {
+		return domain.URLImportRow{}, err
+	}
+
+	alias := record.Alias
+	status := domain.ImportCreated
+
+	existing, conflict, err := s.findConflict(ctx, owner, record, imp)
+
+	if err != nil {
+		return domain.URLImportRow{}, err
+	}
+
+	if conflict {
+		switch imp.options.OnConflict {
+		case domain.ConflictOverwrite:
+			// Row repeating alias of file has nothing stored to overwrite yet
+			if imp.taken[existing.Alias] {
+				return domain.URLImportRow{}, ErrAliasRepeated
+			}
+
+			if existing.Owner != owner {
+				return domain.URLImportRow{}, ErrURLForbidden
+			}
+
+			imp.taken[existing.Alias] = true
+
+			if !imp.options.DryRun {
+				if err := s.overwriteImported(ctx, existing, record); err != nil {
+					return domain.URLImportRow{}, err
+				}
+			}
+
+			return domain.URLImportRow{Alias: existing.Alias, Status: domain.ImportOverwritten}, nil
+		case domain.ConflictRename:
+			// Without alias conflict is the same original URL, which owner cannot have twice
+			if record.Alias == "" {
+				return domain.URLImportRow{Alias: existing.Alias, Status: domain.ImportSkipped}, nil
+			}
+
+			alias = ""
+			status = domain.ImportRenamed
+		default:
+			return domain.URLImportRow{Alias: existing.Alias, Status: domain.ImportSkipped}, nil
+		}
+	}
+
+	if imp.count > s.urlCountLimit {
+		return domain.URLImportRow{}, ErrURLLimit
+	}
+
+	if alias == "" {
+		if alias, err = s.freeAlias(ctx, record.Original, owner, imp.taken); err != nil {
+			return domain.URLImportRow{}, err
+		}
+	}
+
+	imp.taken[alias] = true
+	imp.count++
+
+	if !imp.options.DryRun {
+		if err := s.createImported(ctx, record.URL(alias, owner, s.defaultExpiration)); err != nil {
+			return domain.URLImportRow{}, err
+		}
+	}
+
+	return domain.URLImportRow{Alias: alias, Status: status}, nil
+}
+
+// findConflict returns url conflicting with record, rows of the same file are stored only without dry run,
+// so url having alias of previous row is returned without fields
+func (s *URLsService) findConflict(ctx context.Context, owner primitive.ObjectID, record domain.URLRecord,
+	imp *urlImport) (domain.URL, bool, error) {
+	var existing domain.URL
+	var err error
+
+	if record.Alias != "" {
+		if imp.taken[record.Alias] {
+			return domain.URL{Alias: record.Alias}, true, nil
+		}
+
+		existing, err = s.repo.Get(ctx, record.Alias)
+	} else {
+		existing, err = s.repo.GetByOriginalAndOwner(ctx, record.Original, owner)
+	}
+
+	if err == repo.ErrURLNotFound {
+		return domain.URL{}, false, nil
+	}
+
+	if err != nil {
+		return domain.URL{}, false, err
+	}
+
+	return existing, true, nil
+}
+
+// freeAlias generates alias of original URL not used by stored urls and previous rows
+func (s *URLsService) freeAlias(ctx context.Context, original string, owner primitive.ObjectID,
+	taken map[string]bool) (string, error) {
+	for try := 0; ; try++ {
+		alias, err := s.urlEncoder.Encode(original, owner, try, s.aliasLength)
+
+		if err != nil {
+			if err == hash.ErrURLAliasLengthExceed {
+				return "", ErrNoPossibleAliasEncoding
+			}
+
+			return "", err
+		}
+
+		if taken[alias] {
+			continue
+		}
+
+		_, err = s.repo.Get(ctx, alias)
+
+		if err == repo.ErrURLNotFound {
+			return alias, nil
+		}
+
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+func (s *URLsService) createImported(ctx context.Context, url domain.URL) error {
+	if _, err := s.repo.Create(ctx, url); err != nil {
+		return err
+	}
+
+	// Alias may be cached as missing
+	s.cacheWriter.Delete(ctx, url.Alias)
+
+	if url.Metadata.Title == "" {
+		s.metadata.Enqueue(url.Alias)
+	}
+
+	created, err := s.repo.Get(ctx, url.Alias)
+
+	if err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, url.Owner, domain.AuditURLImported, url.Alias, nil, created, auditIgnoredURLFields...)
+	s.webhooks.Emit(ctx, url.Owner, domain.EventURLCreated, created)
+
+	return nil
+}
+
+func (s *URLsService) overwriteImported(ctx context.Context, existing domain.URL, record domain.URLRecord) error {
+	url := record.URL(existing.Alias, existing.Owner, s.defaultExpiration)
+
+	if record.CreatedAt.IsZero() {
+		url.CreatedAt = existing.CreatedAt
+	}
+
+	if err := s.repo.Replace(ctx, url); err != nil {
+		return err
+	}
+
+	if url.Metadata.Title == "" {
+		s.metadata.Enqueue(url.Alias)
+	}
+
+	_, err := s.refreshAudited(ctx, url.Alias, existing.Owner, domain.AuditURLImported, existing)
+
+	return err
+}
+
+// validateRecord checks fields of record, reason of rejection names the first invalid field
+func validateRecord(record domain.URLRecord) error {
+	if record.Alias != "" && !aliasPattern.MatchString(record.Alias) {
+		return ErrInvalidAlias
+	}
+
+	if err := recordValidator.Struct(record); err != nil {
+		var invalid validator.ValidationErrors
+
+		if !errors.As(err, &invalid) || len(invalid) == 0 {
+			return err
+		}
+
+		// Namespace starts with name of record type
+		field := invalid[0].Namespace()
+
+		if i := strings.IndexByte(field, '.'); i >= 0 {
+			field = field[i+1:]
+		}
+
+		return invalidRecord(field)
+	}
+
+	// Bookmark files keep scripts and pages of browser, which are never redirected to
+	if u, err := url.Parse(record.Original); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return invalidRecord("original")
+	}
+
+	// Variants are stored type without binding tags
+	for _, v := range record.Variants {
+		if v.Name == "" || v.Weight < 1 || v.Clicks < 0 || recordValidator.Var(v.Destination, "url") != nil {
+			return invalidRecord("variants")
+		}
+	}
+
+	return nil
+}
+
+func invalidRecord(field string) error {
+	return domain.NewError(domain.KindValidation, "invalid_record", "invalid "+field)
+}
+
+func newRecordValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+
+	// Fields are named as in exported file
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+
+	return v
+}
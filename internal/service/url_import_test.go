@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"testing"
+)
+
+func TestURLsService_ImportDryRun(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "docs").Return(domain.URL{}, repo.ErrURLNotFound)
+	urlsRepo.EXPECT().GetByOriginalAndOwner(gomock.Any(), "https://blog.example.com/", owner).
+		Return(domain.URL{Alias: "blog", Owner: owner}, nil)
+
+	res, err := s.Import(ctx, owner, []domain.URLRecord{
+		{Alias: "docs", Original: "https://docs.example.com/"},
+		{Original: "https://blog.example.com/"},
+		{Alias: "docs", Original: "https://docs.example.com/again"},
+		{Original: "docs.example.com"},
+		{Alias: "../admin", Original: "https://docs.example.com/"},
+		{Original: "javascript:alert(1)"},
+	}, domain.URLImportOptions{DryRun: true, OnConflict: domain.ConflictSkip})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.URLImportResult{
+		DryRun:  true,
+		Created: 1,
+		Skipped: 2,
+		Failed:  3,
+		Rows: []domain.URLImportRow{
+			{Row: 1, Alias: "docs", Status: domain.ImportCreated},
+			{Row: 2, Alias: "blog", Status: domain.ImportSkipped},
+			{Row: 3, Alias: "docs", Status: domain.ImportSkipped},
+			{Row: 4, Status: domain.ImportFailed, Error: "invalid original"},
+			{Row: 5, Status: domain.ImportFailed, Error: ErrInvalidAlias.Message},
+			{Row: 6, Status: domain.ImportFailed, Error: "invalid original"},
+		},
+	}, res)
+}
+
+func TestURLsService_ImportOverwrite(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	existing := domain.URL{Alias: "docs", Original: "https://docs.example.com/", Owner: owner, Version: 2}
+	overwritten := domain.URL{Alias: "docs", Original: "https://docs.example.com/new", Owner: owner, Version: 3}
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return([]domain.URL{existing}, nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "docs").Return(existing, nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "other").Return(domain.URL{Alias: "other", Owner: primitive.NewObjectID()}, nil)
+	urlsRepo.EXPECT().Replace(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url domain.URL) error {
+		require.Equal(t, "docs", url.Alias)
+		require.Equal(t, "https://docs.example.com/new", url.Original)
+		require.Equal(t, int64(4), url.Clicks)
+
+		return nil
+	})
+
+	// Overwritten url is read from database and written through cache
+	urlsRepo.EXPECT().Get(gomock.Any(), "docs").Return(overwritten, nil)
+	urlsCache.EXPECT().Set(gomock.Any(), overwritten).Return(nil)
+
+	res, err := s.Import(ctx, owner, []domain.URLRecord{
+		{Alias: "docs", Original: "https://docs.example.com/new", Clicks: 4},
+		{Alias: "other", Original: "https://docs.example.com/other"},
+	}, domain.URLImportOptions{OnConflict: domain.ConflictOverwrite})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.URLImportResult{
+		Overwritten: 1,
+		Failed:      1,
+		Rows: []domain.URLImportRow{
+			{Row: 1, Alias: "docs", Status: domain.ImportOverwritten},
+			{Row: 2, Status: domain.ImportFailed, Error: ErrURLForbidden.Message},
+		},
+	}, res)
+}
+
+func TestURLsService_ImportRename(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	var renamed string
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "docs").Return(domain.URL{Alias: "docs", Owner: owner}, nil)
+
+	// Generated alias is free
+	urlsRepo.EXPECT().Get(gomock.Any(), gomock.Not("docs")).DoAndReturn(func(_ context.Context, alias string) (domain.URL, error) {
+		renamed = alias
+
+		return domain.URL{}, repo.ErrURLNotFound
+	})
+	urlsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url domain.URL) (string, error) {
+		require.Equal(t, renamed, url.Alias)
+		require.Equal(t, owner, url.Owner)
+		require.Equal(t, "Docs", url.Metadata.Title)
+		require.False(t, url.ExpiredAt.IsZero())
+
+		return url.Alias, nil
+	})
+	urlsCache.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), gomock.Not("docs")).Return(domain.URL{}, nil)
+
+	res, err := s.Import(ctx, owner, []domain.URLRecord{
+		{Alias: "docs", Original: "https://docs.example.com/", Title: "Docs"},
+	}, domain.URLImportOptions{OnConflict: domain.ConflictRename})
+
+	require.NoError(t, err)
+	require.Equal(t, domain.URLImportResult{
+		Created: 1,
+		Rows: []domain.URLImportRow{
+			{Row: 1, Alias: renamed, Status: domain.ImportRenamed},
+		},
+	}, res)
+}
+
+func TestURLsService_ImportLimit(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return(make([]domain.URL, 4), nil)
+	urlsRepo.EXPECT().GetByOriginalAndOwner(gomock.Any(), "https://docs.example.com/", owner).
+		Return(domain.URL{}, repo.ErrURLNotFound)
+
+	res, err := s.Import(ctx, owner, []domain.URLRecord{
+		{Original: "https://docs.example.com/"},
+	}, domain.URLImportOptions{})
+
+	require.NoError(t, err)
+	require.Equal(t, []domain.URLImportRow{
+		{Row: 1, Status: domain.ImportFailed, Error: ErrURLLimit.Message},
+	}, res.Rows)
+}
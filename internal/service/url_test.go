@@ -2,15 +2,22 @@ package service
 
 import (
 	"context"
-	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/cache"
 	mockCache "github.com/mebr0/tiny-url/internal/cache/mocks"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/metrics"
 	"github.com/mebr0/tiny-url/internal/repo"
 	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/mebr0/tiny-url/internal/tracing"
 	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel/trace"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -23,8 +30,21 @@ func mockURLService(t *testing.T) (*URLsService, *mockRepo.MockURLs, *mockCache.
 
 	urlsRepo := mockRepo.NewMockURLs(mockCtl)
 	urlsCache := mockCache.NewMockURLs(mockCtl)
+	metadata := mockService.NewMockMetadata(mockCtl)
 
-	service := newURLsService(urlsRepo, urlsCache, hash.NewMD5URLEncoder(), 6, 10000, 3)
+	webhooks := mockService.NewMockWebhooks(mockCtl)
+	audit := mockService.NewMockAudit(mockCtl)
+	stats := mockService.NewMockStats(mockCtl)
+
+	metadata.EXPECT().Enqueue(gomock.Any()).AnyTimes()
+	webhooks.EXPECT().Emit(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).AnyTimes()
+	audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).AnyTimes()
+	stats.EXPECT().DeleteByAlias(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	stats.EXPECT().UpdateOwner(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	service := newURLsService(urlsRepo, urlsCache, newCacheWriter(urlsCache, time.Millisecond), metadata, webhooks,
+		audit, stats, hash.NewMD5URLEncoder(), 6, 10000, 3, time.Minute)
 
 	return service, urlsRepo, urlsCache
 }
@@ -36,7 +56,7 @@ func TestURLsService_ListByOwnerAndExpiration(t *testing.T) {
 
 	userId := primitive.NewObjectID()
 
-	urlsRepo.EXPECT().ListByOwnerAndExpiration(ctx, userId, false).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().ListByOwnerAndExpiration(gomock.Any(), userId, false).Return([]domain.URL{}, nil)
 
 	res, err := service.ListByOwnerAndExpiration(ctx, userId, false)
 
@@ -51,7 +71,7 @@ func TestURLsService_ListByOwner(t *testing.T) {
 
 	userId := primitive.NewObjectID()
 
-	urlsRepo.EXPECT().ListByOwner(ctx, userId).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), userId).Return([]domain.URL{}, nil)
 
 	res, err := service.ListByOwner(ctx, userId)
 
@@ -66,7 +86,7 @@ func TestURLsService_ListByOwnerErr(t *testing.T) {
 
 	userId := primitive.NewObjectID()
 
-	urlsRepo.EXPECT().ListByOwner(ctx, userId).Return([]domain.URL{}, errDefault)
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), userId).Return([]domain.URL{}, errDefault)
 
 	_, err := service.ListByOwner(ctx, userId)
 
@@ -74,16 +94,17 @@ func TestURLsService_ListByOwnerErr(t *testing.T) {
 }
 
 func TestURLsService_Create(t *testing.T) {
-	service, urlsRepo, _ := mockURLService(t)
+	service, urlsRepo, urlsCache := mockURLService(t)
 
 	ctx := context.Background()
 
 	userId := primitive.NewObjectID()
 
-	urlsRepo.EXPECT().GetByOriginalAndOwner(ctx, "url", userId).Return(domain.URL{}, repo.ErrURLNotFound)
-	urlsRepo.EXPECT().ListByOwner(ctx, userId).Return([]domain.URL{}, nil)
-	urlsRepo.EXPECT().Create(ctx, gomock.Any()).Return("alias", nil)
-	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{}, nil)
+	urlsRepo.EXPECT().GetByOriginalAndOwner(gomock.Any(), "url", userId).Return(domain.URL{}, repo.ErrURLNotFound)
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), userId).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Return("alias", nil)
+	urlsCache.EXPECT().Delete(gomock.Any(), "alias").Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, nil)
 
 	res, err := service.Create(ctx, domain.URLCreate{
 		Original: "url",
@@ -95,6 +116,37 @@ func TestURLsService_Create(t *testing.T) {
 	require.IsType(t, domain.URL{}, res)
 }
 
+func TestURLsService_CreateWithUTM(t *testing.T) {
+	service, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	userId := primitive.NewObjectID()
+	original := "https://example.com/?ref=1&utm_campaign=sale&utm_source=mail"
+
+	urlsRepo.EXPECT().GetByOriginalAndOwner(gomock.Any(), original, userId).Return(domain.URL{}, repo.ErrURLNotFound)
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), userId).Return([]domain.URL{}, nil)
+	urlsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, url domain.URL) (string, error) {
+		require.Equal(t, original, url.Original)
+
+		return "alias", nil
+	})
+	urlsCache.EXPECT().Delete(gomock.Any(), "alias").Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, nil)
+
+	_, err := service.Create(ctx, domain.URLCreate{
+		Original: "https://example.com/?ref=1&utm_source=old",
+		Duration: 25,
+		UTM: domain.UTM{
+			Source:   "mail",
+			Campaign: "sale",
+		},
+		Owner: userId,
+	})
+
+	require.NoError(t, err)
+}
+
 func TestURLsService_CreateErrURLAlreadyExists(t *testing.T) {
 	service, urlsRepo, _ := mockURLService(t)
 
@@ -102,7 +154,7 @@ func TestURLsService_CreateErrURLAlreadyExists(t *testing.T) {
 
 	userId := primitive.NewObjectID()
 
-	urlsRepo.EXPECT().GetByOriginalAndOwner(ctx, "url", userId).Return(domain.URL{
+	urlsRepo.EXPECT().GetByOriginalAndOwner(gomock.Any(), "url", userId).Return(domain.URL{
 		ExpiredAt: time.Now().Add(time.Duration(1) * time.Minute),
 	}, nil)
 
@@ -120,7 +172,7 @@ func TestURLsService_GetFromCache(t *testing.T) {
 
 	ctx := context.Background()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{}, nil)
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, nil)
 
 	res, err := service.Get(ctx, "alias")
 
@@ -128,13 +180,43 @@ func TestURLsService_GetFromCache(t *testing.T) {
 	require.IsType(t, domain.URL{}, res)
 }
 
+func TestURLsService_GetSpan(t *testing.T) {
+	service, _, urlsCache := mockURLService(t)
+
+	_, exporter := tracing.NewInMemoryProvider()
+
+	ctx := context.Background()
+
+	var cacheSpan trace.SpanContext
+
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) (domain.URL, error) {
+		cacheSpan = trace.SpanContextFromContext(ctx)
+
+		return domain.URL{}, nil
+	})
+
+	_, err := service.Get(ctx, "alias")
+
+	require.NoError(t, err)
+
+	spans := exporter.GetSpans()
+
+	require.Len(t, spans, 1)
+	require.Equal(t, "URLsService.Get", spans[0].Name)
+	require.Contains(t, spans[0].Attributes, aliasAttribute("alias"))
+	require.Contains(t, spans[0].Attributes, cacheResultAttribute(metrics.ResultHit))
+
+	// Cache is called within span of service
+	require.Equal(t, spans[0].SpanContext, cacheSpan)
+}
+
 func TestURLsService_GetFromDatabase(t *testing.T) {
 	service, urlsRepo, urlsCache := mockURLService(t)
 
 	ctx := context.Background()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{}, redis.Nil)
-	urlsRepo.EXPECT().Get(ctx, "alias").Return(domain.URL{
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, cache.ErrNotFound)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
 		ExpiredAt: time.Now().Add(time.Duration(1) * time.Minute),
 	}, nil)
 	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil)
@@ -145,13 +227,145 @@ func TestURLsService_GetFromDatabase(t *testing.T) {
 	require.IsType(t, domain.URL{}, res)
 }
 
+func TestURLsService_GetMissing(t *testing.T) {
+	service, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	// The first request reaches database and caches missing alias
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, cache.ErrNotFound)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, repo.ErrURLNotFound)
+
+	setMissing := make(chan struct{})
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) error {
+		close(setMissing)
+		return nil
+	})
+
+	_, err := service.Get(ctx, "alias")
+
+	require.ErrorIs(t, err, repo.ErrURLNotFound)
+
+	<-setMissing
+
+	// The next one is answered by cache
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, cache.ErrMissing)
+
+	_, err = service.Get(ctx, "alias")
+
+	require.ErrorIs(t, err, repo.ErrURLNotFound)
+}
+
+func TestURLsService_GetConcurrentMisses(t *testing.T) {
+	service, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	const clients = 5
+
+	var misses sync.WaitGroup
+	misses.Add(clients)
+
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) (domain.URL, error) {
+		misses.Done()
+		return domain.URL{}, cache.ErrNotFound
+	}).Times(clients)
+
+	// Database is queried once, after every client missed cache
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) (domain.URL, error) {
+		misses.Wait()
+		time.Sleep(10 * time.Millisecond)
+
+		return domain.URL{Alias: alias}, nil
+	}).Times(1)
+	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < clients; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			res, err := service.Get(ctx, "alias")
+
+			require.NoError(t, err)
+			require.Equal(t, "alias", res.Alias)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestURLsService_GetCancelledLeader(t *testing.T) {
+	service, urlsRepo, urlsCache := mockURLService(t)
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+
+	loading := make(chan struct{})
+	release := make(chan struct{})
+
+	missed := make(chan struct{})
+
+	gomock.InOrder(
+		urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{}, cache.ErrNotFound),
+		urlsCache.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) (domain.URL, error) {
+			close(missed)
+			return domain.URL{}, cache.ErrNotFound
+		}),
+	)
+
+	// Database is queried once, with context not cancelled together with the first request
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").DoAndReturn(func(ctx context.Context, alias string) (domain.URL, error) {
+		close(loading)
+		<-release
+
+		if err := ctx.Err(); err != nil {
+			return domain.URL{}, err
+		}
+
+		return domain.URL{Alias: alias}, nil
+	}).Times(1)
+	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).MaxTimes(1)
+
+	leaderErr := make(chan error)
+
+	go func() {
+		_, err := service.Get(leaderCtx, "alias")
+		leaderErr <- err
+	}()
+
+	<-loading
+
+	res := make(chan domain.URL)
+
+	go func() {
+		url, err := service.Get(context.Background(), "alias")
+
+		require.NoError(t, err)
+		res <- url
+	}()
+
+	// The first request gives up, while load it started goes on for the second one
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	// Let the second request join the load before it finishes
+	<-missed
+	time.Sleep(10 * time.Millisecond)
+
+	close(release)
+	require.Equal(t, "alias", (<-res).Alias)
+}
+
 func TestURLsService_GetByOwner(t *testing.T) {
 	s, _, urlsCache := mockURLService(t)
 
 	ctx := context.Background()
 	owner := primitive.NewObjectID()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
 		Owner: owner,
 	}, nil)
 
@@ -166,7 +380,7 @@ func TestURLsService_GetByOwnerErrURLForbidden(t *testing.T) {
 
 	ctx := context.Background()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
 		Owner: primitive.NilObjectID,
 	}, nil)
 
@@ -182,17 +396,116 @@ func TestURLsService_Prolong(t *testing.T) {
 
 	owner := primitive.NewObjectID()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
 		Owner: owner,
-	}, nil).Times(2)
+	}, nil)
 
-	urlsRepo.EXPECT().Prolong(ctx, "alias", domain.URLProlong{Duration: 5}).Return(nil)
-	urlsCache.EXPECT().Delete(gomock.Any(), "alias").Return(nil)
+	urlsRepo.EXPECT().Prolong(gomock.Any(), "alias", domain.URLProlong{Duration: 5}).Return(nil)
+
+	// Prolonged url is read from database and written through cache
+	prolonged := domain.URL{Alias: "alias", Owner: owner, ExpiredAt: time.Now().Add(5 * time.Second), Version: 1}
+
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(prolonged, nil)
+	urlsCache.EXPECT().Set(gomock.Any(), prolonged).Return(nil)
 
 	res, err := s.Prolong(ctx, "alias", owner, domain.URLProlong{Duration: 5})
 
 	require.NoError(t, err)
-	require.IsType(t, domain.URL{}, res)
+	require.Equal(t, prolonged, res)
+}
+
+func TestURLsService_Search(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().Search(gomock.Any(), owner, "docs start", int64(searchLimit)).Return([]domain.URLSearchResult{
+		{
+			URL: domain.URL{
+				Alias:    "alias",
+				Original: "https://docs.example.com/",
+				Tags:     []string{"Docs", "blog"},
+				Metadata: domain.URLMetadata{Title: "Getting started"},
+			},
+			Score: 1.5,
+		},
+	}, nil)
+
+	res, err := s.Search(ctx, owner, "docs start")
+
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, []domain.Highlight{
+		{Field: "original", Value: "https://docs.example.com/", Matches: []domain.TextRange{{Start: 8, End: 12}}},
+		{Field: "title", Value: "Getting started", Matches: []domain.TextRange{{Start: 8, End: 13}}},
+		{Field: "tags", Value: "Docs", Matches: []domain.TextRange{{Start: 0, End: 4}}},
+	}, res[0].Highlights)
+}
+
+func TestURLsService_SetRules(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	rules := []domain.RedirectRule{{OS: "ios", Destination: "https://apps.apple.com"}}
+
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
+		Owner: owner,
+	}, nil)
+
+	urlsRepo.EXPECT().UpdateRules(gomock.Any(), "alias", rules).Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{Owner: owner, Rules: rules}, nil)
+	urlsCache.EXPECT().Set(gomock.Any(), domain.URL{Owner: owner, Rules: rules}).Return(nil)
+
+	res, err := s.SetRules(ctx, "alias", owner, rules)
+
+	require.NoError(t, err)
+	require.Equal(t, rules, res.Rules)
+}
+
+func TestURLsService_PromoteVariant(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
+		Owner:    owner,
+		Original: "https://google.com",
+		Variants: []domain.Variant{
+			{Name: "a", Destination: "https://google.com/a", Weight: 1},
+			{Name: "b", Destination: "https://google.com/b", Weight: 1},
+		},
+	}, nil)
+
+	urlsRepo.EXPECT().CollapseVariants(gomock.Any(), "alias", "https://google.com/b").Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{Owner: owner, Original: "https://google.com/b"}, nil)
+	urlsCache.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil)
+
+	res, err := s.PromoteVariant(ctx, "alias", owner, "b")
+
+	require.NoError(t, err)
+	require.Equal(t, "https://google.com/b", res.Original)
+}
+
+func TestURLsService_PromoteVariantErrVariantNotFound(t *testing.T) {
+	s, _, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
+		Owner: owner,
+	}, nil)
+
+	_, err := s.PromoteVariant(ctx, "alias", owner, "b")
+
+	require.ErrorIs(t, err, ErrVariantNotFound)
 }
 
 func TestURLsService_Delete(t *testing.T) {
@@ -202,14 +515,185 @@ func TestURLsService_Delete(t *testing.T) {
 
 	owner := primitive.NewObjectID()
 
-	urlsCache.EXPECT().Get(ctx, "alias").Return(domain.URL{
+	urlsCache.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{
+		Alias: "alias",
 		Owner: owner,
 	}, nil)
 
-	urlsRepo.EXPECT().Delete(ctx, "alias").Return(nil)
-	urlsCache.EXPECT().Delete(gomock.Any(), "alias").Return(nil)
+	urlsRepo.EXPECT().Delete(gomock.Any(), "alias").Return(nil)
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "alias").Return(nil)
 
 	err := s.Delete(ctx, "alias", owner)
 
 	require.NoError(t, err)
 }
+
+func TestURLsService_DeleteByOwner(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return([]domain.URL{
+		{Alias: "first", Owner: owner},
+		{Alias: "second", Owner: owner},
+	}, nil)
+
+	urlsRepo.EXPECT().Delete(gomock.Any(), "first").Return(nil)
+	urlsRepo.EXPECT().Delete(gomock.Any(), "second").Return(nil)
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "first").Return(nil)
+	urlsCache.EXPECT().SetMissing(gomock.Any(), "second").Return(nil)
+
+	err := s.DeleteByOwner(ctx, owner)
+	s.cacheWriter.Wait()
+
+	require.NoError(t, err)
+}
+
+func TestURLsService_TransferOwner(t *testing.T) {
+	s, urlsRepo, urlsCache := mockURLService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	successor := primitive.NewObjectID()
+
+	urlsRepo.EXPECT().ListByOwner(gomock.Any(), owner).Return([]domain.URL{
+		{Alias: "alias", Owner: owner},
+	}, nil)
+
+	urlsRepo.EXPECT().UpdateOwner(gomock.Any(), "alias", successor).Return(nil)
+	urlsRepo.EXPECT().Get(gomock.Any(), "alias").Return(domain.URL{Alias: "alias", Owner: successor}, nil)
+	urlsCache.EXPECT().Set(gomock.Any(), domain.URL{Alias: "alias", Owner: successor}).Return(nil)
+
+	err := s.TransferOwner(ctx, owner, successor)
+	s.cacheWriter.Wait()
+
+	require.NoError(t, err)
+}
+
+func TestURLsService_Click(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	stats := mockService.NewMockStats(gomock.NewController(t))
+	s.stats = stats
+
+	done := make(chan struct{})
+
+	url := domain.URL{Alias: "alias"}
+	click := domain.Click{Variant: "a", Time: time.Now(), Referrer: "google.com"}
+
+	urlsRepo.EXPECT().IncrementClicks(gomock.Any(), "alias", "a").Return(int64(1), nil)
+	stats.EXPECT().Record(gomock.Any(), url, click).DoAndReturn(func(_ context.Context, _ domain.URL, _ domain.Click) error {
+		close(done)
+
+		return nil
+	})
+
+	s.Click(context.Background(), url, click)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("click was not recorded")
+	}
+}
+
+func TestURLsService_ClickBot(t *testing.T) {
+	s, _, _ := mockURLService(t)
+
+	stats := mockService.NewMockStats(gomock.NewController(t))
+	s.stats = stats
+
+	done := make(chan struct{})
+
+	url := domain.URL{Alias: "alias"}
+	click := domain.Click{Time: time.Now(), Bot: true}
+
+	// Clicks of url are not incremented
+	stats.EXPECT().Record(gomock.Any(), url, click).DoAndReturn(func(_ context.Context, _ domain.URL, _ domain.Click) error {
+		close(done)
+
+		return nil
+	})
+
+	s.Click(context.Background(), url, click)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("click was not recorded")
+	}
+}
+
+func TestURLsService_ClickWait(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	stats := mockService.NewMockStats(gomock.NewController(t))
+	s.stats = stats
+
+	var persisted int32
+
+	url := domain.URL{Alias: "alias"}
+	click := domain.Click{Time: time.Now()}
+
+	// Slow database is still written before shutdown
+	urlsRepo.EXPECT().IncrementClicks(gomock.Any(), "alias", "").DoAndReturn(func(_ context.Context, _ string, _ string) (int64, error) {
+		time.Sleep(50 * time.Millisecond)
+		atomic.StoreInt32(&persisted, 1)
+
+		return 1, nil
+	})
+	stats.EXPECT().Record(gomock.Any(), url, click).Return(nil)
+
+	s.Click(context.Background(), url, click)
+	s.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&persisted))
+}
+
+func TestURLsService_notifyExpired(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().ListNewlyExpired(gomock.Any()).Return([]domain.URL{{Alias: "first"}, {Alias: "second"}}, nil)
+	urlsRepo.EXPECT().SetExpirationNotified(gomock.Any(), "first").Return(nil)
+	urlsRepo.EXPECT().SetExpirationNotified(gomock.Any(), "second").Return(nil)
+
+	err := s.notifyExpired(ctx)
+
+	require.NoError(t, err)
+}
+
+func TestURLsService_countURLs(t *testing.T) {
+	s, urlsRepo, _ := mockURLService(t)
+
+	ctx := context.Background()
+
+	urlsRepo.EXPECT().CountByExpiration(gomock.Any(), false).Return(int64(3), nil)
+	urlsRepo.EXPECT().CountByExpiration(gomock.Any(), true).Return(int64(2), nil)
+
+	err := s.countURLs(ctx)
+
+	require.NoError(t, err)
+	require.Equal(t, float64(3), testutil.ToFloat64(metrics.URLs.WithLabelValues(metrics.StateActive)))
+	require.Equal(t, float64(2), testutil.ToFloat64(metrics.URLs.WithLabelValues(metrics.StateExpired)))
+}
+
+func TestIsClickMilestone(t *testing.T) {
+	for clicks, expected := range map[int64]bool{
+		0:    false,
+		1:    false,
+		9:    false,
+		10:   true,
+		11:   false,
+		20:   false,
+		100:  true,
+		1000: true,
+		1010: false,
+	} {
+		require.Equal(t, expected, isClickMilestone(clicks), clicks)
+	}
+}
@@ -4,18 +4,202 @@ import (
 	"context"
 	"github.com/mebr0/tiny-url/internal/domain"
 	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/hash"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// UsersService lets users manage their own accounts. Urls of deleted user are deleted or transferred to
+// successor as user chooses, policy is applied by default. Without successor urls are only deleted
 type UsersService struct {
-	repo repo.Users
+	repo      repo.Users
+	tokens    repo.Tokens
+	urls      URLs
+	webhooks  Webhooks
+	audit     Audit
+	auth      *AuthService
+	hasher    hash.PasswordHasher
+	policy    string
+	successor primitive.ObjectID
 }
 
-func newUsersService(repo repo.Users) *UsersService {
+func newUsersService(repo repo.Users, tokens repo.Tokens, urls URLs, webhooks Webhooks, audit Audit, auth *AuthService,
+	hasher hash.PasswordHasher, policy string, successor primitive.ObjectID) *UsersService {
 	return &UsersService{
-		repo: repo,
+		repo:      repo,
+		tokens:    tokens,
+		urls:      urls,
+		webhooks:  webhooks,
+		audit:     audit,
+		auth:      auth,
+		hasher:    hasher,
+		policy:    policy,
+		successor: successor,
 	}
 }
 
 func (s *UsersService) List(ctx context.Context) ([]domain.User, error) {
 	return s.repo.List(ctx)
 }
+
+func (s *UsersService) Get(ctx context.Context, id primitive.ObjectID) (domain.User, error) {
+	return s.repo.Get(ctx, id)
+}
+
+// Update sets name of user at once, new email is applied only after it is verified by token sent to it
+func (s *UsersService) Update(ctx context.Context, id primitive.ObjectID, toUpdate domain.UserUpdate) (domain.User, error) {
+	before, err := s.repo.Get(ctx, id)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	changeEmail := toUpdate.Email != "" && toUpdate.Email != before.Email
+
+	// Taken email is reported before anything is changed, not after user verifies it
+	if changeEmail {
+		if _, err := s.repo.GetByEmail(ctx, toUpdate.Email); err != repo.ErrUserNotFound {
+			if err == nil {
+				return domain.User{}, repo.ErrUserAlreadyExists
+			}
+
+			return domain.User{}, err
+		}
+	}
+
+	if toUpdate.Name != "" && toUpdate.Name != before.Name {
+		if err := s.repo.UpdateName(ctx, id, toUpdate.Name); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	if changeEmail {
+		pending := domain.User{ID: id, Name: before.Name, Email: toUpdate.Email}
+
+		if err := s.auth.sendToken(ctx, pending, domain.TokenVerification); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	after, err := s.repo.Get(ctx, id)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	s.audit.Record(ctx, id, domain.AuditUserUpdated, id.Hex(), before, after, auditIgnoredUserFields...)
+
+	return after, nil
+}
+
+// ChangePassword sets new password of user proven by current one, issued access tokens and pending password
+// resets are revoked
+func (s *UsersService) ChangePassword(ctx context.Context, id primitive.ObjectID, toChange domain.PasswordChange) error {
+	if _, err := s.checkPassword(ctx, id, toChange.Password); err != nil {
+		return err
+	}
+
+	passwordHash, err := s.hasher.Hash(toChange.NewPassword)
+
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, id, passwordHash); err != nil {
+		return err
+	}
+
+	if err := s.tokens.DeleteByUser(ctx, id, domain.TokenPasswordReset); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, id, domain.AuditUserPasswordChange, id.Hex(), nil, nil)
+
+	return nil
+}
+
+// Delete removes user proven by password with webhooks and tokens, urls are deleted or transferred to successor.
+// Access tokens are revoked first, so no urls are created meanwhile. User is removed last, so failed deletion
+// is finished by retry
+func (s *UsersService) Delete(ctx context.Context, id primitive.ObjectID, toDelete domain.UserDelete) error {
+	user, err := s.checkPassword(ctx, id, toDelete.Password)
+
+	if err != nil {
+		return err
+	}
+
+	policy := toDelete.URLs
+
+	if policy == "" {
+		policy = s.policy
+	}
+
+	// Successor cannot be deleted whatever is done with its urls, since urls of others are transferred to it
+	if !s.successor.IsZero() && id == s.successor {
+		return ErrSuccessorDeletion
+	}
+
+	if policy == domain.UserURLsTransfer && s.successor.IsZero() {
+		return ErrNoSuccessor
+	}
+
+	if err := s.repo.RevokeTokens(ctx, id); err != nil {
+		return err
+	}
+
+	if policy == domain.UserURLsTransfer {
+		if err := s.urls.TransferOwner(ctx, id, s.successor); err != nil {
+			return err
+		}
+	} else {
+		if err := s.urls.DeleteByOwner(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	hooks, err := s.webhooks.ListByOwner(ctx, id)
+
+	if err != nil {
+		return err
+	}
+
+	for _, hook := range hooks {
+		if err := s.webhooks.Delete(ctx, hook.ID, id); err != nil {
+			return err
+		}
+	}
+
+	for _, purpose := range []string{domain.TokenVerification, domain.TokenPasswordReset} {
+		if err := s.tokens.DeleteByUser(ctx, id, purpose); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, id, domain.AuditUserDeleted, id.Hex(), user, nil, auditIgnoredUserFields...)
+
+	return nil
+}
+
+// checkPassword returns user if password is its current one
+func (s *UsersService) checkPassword(ctx context.Context, id primitive.ObjectID, password string) (domain.User, error) {
+	user, err := s.repo.Get(ctx, id)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	passwordHash, err := s.hasher.Hash(password)
+
+	if err != nil {
+		return domain.User{}, err
+	}
+
+	if user.Password != passwordHash {
+		return domain.User{}, ErrWrongPassword
+	}
+
+	return user, nil
+}
@@ -1,36 +1,291 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"github.com/golang/mock/gomock"
 	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
 	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	mockService "github.com/mebr0/tiny-url/internal/service/mocks"
+	"github.com/mebr0/tiny-url/pkg/auth"
+	"github.com/mebr0/tiny-url/pkg/hash"
+	"github.com/mebr0/tiny-url/pkg/mail"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
+	"time"
 )
 
-func mockUsersService(t *testing.T) (*UsersService, *mockRepo.MockUsers) {
+type usersServiceMocks struct {
+	users    *mockRepo.MockUsers
+	tokens   *mockRepo.MockTokens
+	urls     *mockService.MockURLs
+	webhooks *mockService.MockWebhooks
+	mails    *bytes.Buffer
+}
+
+// Id of user receiving urls of deleted users
+var successorId = primitive.NewObjectID()
+
+func mockUsersService(t *testing.T, policy string) (*UsersService, usersServiceMocks) {
 	t.Helper()
 
 	mockCtl := gomock.NewController(t)
 	defer mockCtl.Finish()
 
-	usersRepo := mockRepo.NewMockUsers(mockCtl)
+	mocks := usersServiceMocks{
+		users:    mockRepo.NewMockUsers(mockCtl),
+		tokens:   mockRepo.NewMockTokens(mockCtl),
+		urls:     mockService.NewMockURLs(mockCtl),
+		webhooks: mockService.NewMockWebhooks(mockCtl),
+		mails:    &bytes.Buffer{},
+	}
+
+	audit := mockService.NewMockAudit(mockCtl)
+	authManager, _ := auth.NewJWTManager("key")
+
+	audit.EXPECT().Record(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(),
+		gomock.Any()).AnyTimes()
+
+	hasher := hash.NewSHA1PasswordHasher("")
+
+	authService := newAuthService(mocks.users, mocks.tokens, audit, hasher, authManager,
+		mail.NewWriterSender(mocks.mails, "noreply@tiny.url"), time.Hour, 24*time.Hour, time.Hour, "https://tiny.url")
+
+	service := newUsersService(mocks.users, mocks.tokens, mocks.urls, mocks.webhooks, audit, authService, hasher,
+		policy, successorId)
+
+	return service, mocks
+}
+
+// hashed returns user with hash of password as it is stored
+func hashed(t *testing.T, user domain.User, password string) domain.User {
+	t.Helper()
+
+	passwordHash, err := hash.NewSHA1PasswordHasher("").Hash(password)
+	require.NoError(t, err)
 
-	service := newUsersService(usersRepo)
+	user.Password = passwordHash
 
-	return service, usersRepo
+	return user
 }
 
 func TestUsersService_List(t *testing.T) {
-	service, usersRepo := mockUsersService(t)
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
 
 	ctx := context.Background()
 
-	usersRepo.EXPECT().List(ctx).Return([]domain.User{}, nil)
+	mocks.users.EXPECT().List(ctx).Return([]domain.User{}, nil)
 
 	res, err := service.List(ctx)
 
 	require.NoError(t, err)
 	require.IsType(t, []domain.User{}, res)
 }
+
+func TestUsersService_UpdateName(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := domain.User{ID: primitive.NewObjectID(), Name: "Sirius", Email: "sirius@gmail.com"}
+	updated := user
+	updated.Name = "Regulus"
+
+	gomock.InOrder(
+		mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil),
+		mocks.users.EXPECT().UpdateName(ctx, user.ID, "Regulus").Return(nil),
+		mocks.users.EXPECT().Get(ctx, user.ID).Return(updated, nil),
+	)
+
+	res, err := service.Update(ctx, user.ID, domain.UserUpdate{Name: "Regulus", Email: "sirius@gmail.com"})
+
+	require.NoError(t, err)
+	require.Equal(t, updated, res)
+}
+
+func TestUsersService_UpdateEmail(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := domain.User{ID: primitive.NewObjectID(), Name: "Sirius", Email: "sirius@gmail.com"}
+
+	var issued domain.UserToken
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil).Times(2)
+	mocks.users.EXPECT().GetByEmail(ctx, "orion@gmail.com").Return(domain.User{}, repo.ErrUserNotFound)
+	mocks.tokens.EXPECT().Create(ctx, gomock.Any()).DoAndReturn(func(ctx context.Context, token domain.UserToken) error {
+		issued = token
+
+		return nil
+	})
+
+	res, err := service.Update(ctx, user.ID, domain.UserUpdate{Email: "orion@gmail.com"})
+	service.auth.Wait()
+
+	require.NoError(t, err)
+	// Email is changed only after verification
+	require.Equal(t, "sirius@gmail.com", res.Email)
+	require.Equal(t, "orion@gmail.com", issued.Email)
+	require.Equal(t, domain.TokenVerification, issued.Purpose)
+	require.Contains(t, mocks.mails.String(), "To: orion@gmail.com")
+	require.Equal(t, hashToken(mailedToken(t, mocks.mails, "verify")), issued.Hash)
+}
+
+func TestUsersService_UpdateErrEmailTaken(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := domain.User{ID: primitive.NewObjectID(), Name: "Sirius", Email: "sirius@gmail.com"}
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().GetByEmail(ctx, "orion@gmail.com").Return(domain.User{ID: primitive.NewObjectID()}, nil)
+
+	// Name is not changed either
+	_, err := service.Update(ctx, user.ID, domain.UserUpdate{Name: "Regulus", Email: "orion@gmail.com"})
+
+	require.ErrorIs(t, err, repo.ErrUserAlreadyExists)
+	require.Zero(t, mocks.mails.Len())
+}
+
+func TestUsersService_ChangePassword(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+	newHash := hashed(t, user, "asdfgh456").Password
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().UpdatePassword(ctx, user.ID, newHash).Return(nil)
+	mocks.tokens.EXPECT().DeleteByUser(ctx, user.ID, domain.TokenPasswordReset).Return(nil)
+
+	err := service.ChangePassword(ctx, user.ID, domain.PasswordChange{Password: "qwerty123", NewPassword: "asdfgh456"})
+
+	require.NoError(t, err)
+}
+
+func TestUsersService_ChangePasswordErrWrongPassword(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+
+	err := service.ChangePassword(ctx, user.ID, domain.PasswordChange{Password: "wrong", NewPassword: "asdfgh456"})
+
+	require.ErrorIs(t, err, ErrWrongPassword)
+}
+
+func TestUsersService_Delete(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+	hook := domain.Webhook{ID: primitive.NewObjectID(), Owner: user.ID}
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().RevokeTokens(ctx, user.ID).Return(nil)
+	mocks.urls.EXPECT().DeleteByOwner(ctx, user.ID).Return(nil)
+	mocks.webhooks.EXPECT().ListByOwner(ctx, user.ID).Return([]domain.Webhook{hook}, nil)
+	mocks.webhooks.EXPECT().Delete(ctx, hook.ID, user.ID).Return(nil)
+	mocks.tokens.EXPECT().DeleteByUser(ctx, user.ID, domain.TokenVerification).Return(nil)
+	mocks.tokens.EXPECT().DeleteByUser(ctx, user.ID, domain.TokenPasswordReset).Return(nil)
+	mocks.users.EXPECT().Delete(ctx, user.ID).Return(nil)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123"})
+
+	require.NoError(t, err)
+}
+
+func TestUsersService_DeleteTransfer(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsTransfer)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID(), Email: "sirius@gmail.com"}, "qwerty123")
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().RevokeTokens(ctx, user.ID).Return(nil)
+	mocks.urls.EXPECT().TransferOwner(ctx, user.ID, successorId).Return(nil)
+	mocks.webhooks.EXPECT().ListByOwner(ctx, user.ID).Return([]domain.Webhook{}, nil)
+	mocks.tokens.EXPECT().DeleteByUser(ctx, user.ID, gomock.Any()).Return(nil).Times(2)
+	mocks.users.EXPECT().Delete(ctx, user.ID).Return(nil)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123"})
+
+	require.NoError(t, err)
+}
+
+func TestUsersService_DeleteChosenPolicy(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsTransfer)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+
+	// Urls are deleted as user chooses, not transferred by policy of server
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().RevokeTokens(ctx, user.ID).Return(nil)
+	mocks.urls.EXPECT().DeleteByOwner(ctx, user.ID).Return(nil)
+	mocks.webhooks.EXPECT().ListByOwner(ctx, user.ID).Return([]domain.Webhook{}, nil)
+	mocks.tokens.EXPECT().DeleteByUser(ctx, user.ID, gomock.Any()).Return(nil).Times(2)
+	mocks.users.EXPECT().Delete(ctx, user.ID).Return(nil)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123", URLs: domain.UserURLsDelete})
+
+	require.NoError(t, err)
+}
+
+func TestUsersService_DeleteErrNoSuccessor(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+	service.successor = primitive.NilObjectID
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123", URLs: domain.UserURLsTransfer})
+
+	require.ErrorIs(t, err, ErrNoSuccessor)
+}
+
+func TestUsersService_DeleteErrSuccessorDeletion(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	// Successor is kept even if its own urls would be deleted
+	user := hashed(t, domain.User{ID: successorId}, "qwerty123")
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123"})
+
+	require.ErrorIs(t, err, ErrSuccessorDeletion)
+}
+
+func TestUsersService_DeleteErrURLs(t *testing.T) {
+	service, mocks := mockUsersService(t, domain.UserURLsDelete)
+
+	ctx := context.Background()
+
+	user := hashed(t, domain.User{ID: primitive.NewObjectID()}, "qwerty123")
+
+	mocks.users.EXPECT().Get(ctx, user.ID).Return(user, nil)
+	mocks.users.EXPECT().RevokeTokens(ctx, user.ID).Return(nil)
+	mocks.urls.EXPECT().DeleteByOwner(ctx, user.ID).Return(errDefault)
+
+	err := service.Delete(ctx, user.ID, domain.UserDelete{Password: "qwerty123"})
+
+	// User is kept, so deletion can be retried
+	require.ErrorIs(t, err, errDefault)
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/logging"
+	"github.com/mebr0/tiny-url/internal/repo"
+	"github.com/mebr0/tiny-url/pkg/webhook"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"sync"
+	"time"
+)
+
+const (
+	// Count of deliveries returned in delivery log
+	deliveriesLimit = 100
+	// Time for claimed delivery to be attempted, after it delivery is claimed again
+	deliveryLease = 10 * time.Minute
+	// Timeout of storing deliveries of emitted event
+	emitTimeout = 5 * time.Second
+)
+
+// WebhooksService stores deliveries of emitted events and attempts pending deliveries with workers.
+// Failed deliveries are rescheduled with exponential backoff, so slow receivers do not hold workers
+// and deliveries survive restart
+type WebhooksService struct {
+	repo         repo.Webhooks
+	sender       webhook.Sender
+	workers      int
+	batchSize    int
+	maxAttempts  int
+	backoff      time.Duration
+	pollInterval time.Duration
+	wake         chan struct{}
+}
+
+func newWebhooksService(repo repo.Webhooks, sender webhook.Sender, workers int, batchSize int, maxAttempts int,
+	backoff time.Duration, pollInterval time.Duration) *WebhooksService {
+	return &WebhooksService{
+		repo:         repo,
+		sender:       sender,
+		workers:      workers,
+		batchSize:    batchSize,
+		maxAttempts:  maxAttempts,
+		backoff:      backoff,
+		pollInterval: pollInterval,
+		wake:         make(chan struct{}, 1),
+	}
+}
+
+func (s *WebhooksService) ListByOwner(ctx context.Context, owner primitive.ObjectID) ([]domain.Webhook, error) {
+	return s.repo.ListByOwner(ctx, owner)
+}
+
+func (s *WebhooksService) Create(ctx context.Context, toCreate domain.WebhookCreate) (domain.Webhook, error) {
+	id, err := s.repo.Create(ctx, domain.Webhook{
+		URL:       toCreate.URL,
+		Secret:    toCreate.Secret,
+		Events:    toCreate.Events,
+		CreatedAt: time.Now(),
+		Owner:     toCreate.Owner,
+	})
+
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	return s.repo.Get(ctx, id)
+}
+
+func (s *WebhooksService) Delete(ctx context.Context, id primitive.ObjectID, owner primitive.ObjectID) error {
+	if _, err := s.getByOwner(ctx, id, owner); err != nil {
+		return err
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *WebhooksService) ListDeliveries(ctx context.Context, id primitive.ObjectID, owner primitive.ObjectID) ([]domain.WebhookDelivery, error) {
+	if _, err := s.getByOwner(ctx, id, owner); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListDeliveries(ctx, id, deliveriesLimit)
+}
+
+// Emit stores pending deliveries of event to webhooks of owner subscribed to it, they are attempted by Run.
+// Deliveries are stored even if ctx is canceled after event
+func (s *WebhooksService) Emit(ctx context.Context, owner primitive.ObjectID, event string, data interface{}) {
+	ctx, cancel := context.WithTimeout(logging.Detach(ctx), emitTimeout)
+	defer cancel()
+
+	hooks, err := s.repo.ListByOwnerAndEvent(ctx, owner, event)
+
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("owner", owner.Hex()).Warn("Could not list webhooks of user")
+		return
+	}
+
+	if len(hooks) == 0 {
+		return
+	}
+
+	now := time.Now()
+
+	payload, err := json.Marshal(domain.WebhookEvent{
+		ID:        primitive.NewObjectID().Hex(),
+		Type:      event,
+		CreatedAt: now,
+		Data:      data,
+	})
+
+	if err != nil {
+		logging.FromContext(ctx).WithError(err).WithField("event", event).Warn("Could not marshal event")
+		return
+	}
+
+	for _, hook := range hooks {
+		_, err := s.repo.CreateDelivery(ctx, domain.WebhookDelivery{
+			Webhook:       hook.ID,
+			Event:         event,
+			Payload:       string(payload),
+			CreatedAt:     now,
+			Pending:       true,
+			NextAttemptAt: now,
+		})
+
+		if err != nil {
+			logging.FromContext(ctx).WithError(err).WithField("webhook", hook.ID.Hex()).Warn("Could not create delivery for webhook")
+		}
+	}
+
+	// Poller is woken up, so new deliveries are not delayed until next poll
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// NotifyBroken emits event about broken url
+func (s *WebhooksService) NotifyBroken(ctx context.Context, url domain.URL) error {
+	s.Emit(ctx, url.Owner, domain.EventURLBroken, url)
+
+	return nil
+}
+
+// Run claims pending deliveries every poll interval and attempts them with workers until ctx is done
+func (s *WebhooksService) Run(ctx context.Context) {
+	claimed := make(chan domain.WebhookDelivery)
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case delivery := <-claimed:
+					s.attempt(ctx, delivery)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.poll(ctx, claimed)
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *WebhooksService) getByOwner(ctx context.Context, id primitive.ObjectID, owner primitive.ObjectID) (domain.Webhook, error) {
+	hook, err := s.repo.Get(ctx, id)
+
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+
+	// If owners do not match, return forbidden
+	if hook.Owner != owner {
+		return domain.Webhook{}, ErrWebhookForbidden
+	}
+
+	return hook, nil
+}
+
+// poll hands due deliveries to workers in batches until none is left
+func (s *WebhooksService) poll(ctx context.Context, claimed chan<- domain.WebhookDelivery) {
+	for {
+		now := time.Now()
+
+		deliveries, err := s.repo.ClaimDeliveries(ctx, now, now.Add(deliveryLease), int64(s.batchSize))
+
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithError(err).Warn("Could not claim deliveries of webhooks")
+			}
+
+			return
+		}
+
+		for _, delivery := range deliveries {
+			select {
+			case <-ctx.Done():
+				return
+			case claimed <- delivery:
+			}
+		}
+
+		if len(deliveries) < s.batchSize {
+			return
+		}
+	}
+}
+
+// attempt sends delivery once and records result. Failed delivery stays pending with exponential backoff
+// until attempts are exhausted, delivery interrupted by shutdown is claimed again after lease
+func (s *WebhooksService) attempt(ctx context.Context, delivery domain.WebhookDelivery) {
+	hook, err := s.repo.Get(ctx, delivery.Webhook)
+
+	if err != nil {
+		// Deliveries of deleted webhook are deleted with it
+		if err != repo.ErrWebhookNotFound && ctx.Err() == nil {
+			log.WithError(err).WithField("delivery", delivery.ID.Hex()).Warn("Could not get webhook of delivery")
+		}
+
+		return
+	}
+
+	status, err := s.sender.Send(ctx, hook.URL, hook.Secret, []byte(delivery.Payload))
+
+	if ctx.Err() != nil {
+		return
+	}
+
+	delivery.Attempts++
+	delivery.StatusCode = status
+	delivery.AttemptedAt = time.Now()
+	delivery.Delivered = err == nil
+	delivery.Pending = false
+	delivery.Error = ""
+
+	if err != nil {
+		delivery.Error = err.Error()
+
+		if delivery.Attempts < s.maxAttempts {
+			delivery.Pending = true
+			delivery.NextAttemptAt = delivery.AttemptedAt.Add(webhook.Backoff(delivery.Attempts, s.backoff))
+		}
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		log.WithError(err).WithField("delivery", delivery.ID.Hex()).Warn("Could not update delivery")
+	}
+}
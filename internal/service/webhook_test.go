@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/internal/repo"
+	mockRepo "github.com/mebr0/tiny-url/internal/repo/mocks"
+	"github.com/mebr0/tiny-url/pkg/database/boltdb"
+	"github.com/mebr0/tiny-url/pkg/webhook"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func mockWebhooksService(t *testing.T) (*WebhooksService, *mockRepo.MockWebhooks) {
+	t.Helper()
+
+	mockCtl := gomock.NewController(t)
+	defer mockCtl.Finish()
+
+	webhooksRepo := mockRepo.NewMockWebhooks(mockCtl)
+
+	service := newWebhooksService(webhooksRepo, webhook.NewHTTPSender(time.Second, http.DefaultTransport), 1, 1, 3,
+		time.Millisecond, time.Millisecond)
+
+	return service, webhooksRepo
+}
+
+func TestWebhooksService_Create(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	id := primitive.NewObjectID()
+
+	webhooksRepo.EXPECT().Create(ctx, gomock.Any()).Return(id, nil)
+	webhooksRepo.EXPECT().Get(ctx, id).Return(domain.Webhook{ID: id}, nil)
+
+	res, err := s.Create(ctx, domain.WebhookCreate{
+		URL:    "https://example.com",
+		Secret: "0123456789abcdef",
+		Events: []string{domain.EventURLCreated},
+		Owner:  primitive.NewObjectID(),
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, id, res.ID)
+}
+
+func TestWebhooksService_DeleteErrWebhookForbidden(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	id := primitive.NewObjectID()
+
+	webhooksRepo.EXPECT().Get(ctx, id).Return(domain.Webhook{ID: id, Owner: primitive.NilObjectID}, nil)
+
+	err := s.Delete(ctx, id, primitive.NewObjectID())
+
+	require.ErrorIs(t, err, ErrWebhookForbidden)
+}
+
+func TestWebhooksService_ListDeliveriesErrWebhookNotFound(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	id := primitive.NewObjectID()
+
+	webhooksRepo.EXPECT().Get(ctx, id).Return(domain.Webhook{}, repo.ErrWebhookNotFound)
+
+	_, err := s.ListDeliveries(ctx, id, primitive.NewObjectID())
+
+	require.ErrorIs(t, err, repo.ErrWebhookNotFound)
+}
+
+func TestWebhooksService_ListDeliveries(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	id := primitive.NewObjectID()
+	owner := primitive.NewObjectID()
+
+	webhooksRepo.EXPECT().Get(ctx, id).Return(domain.Webhook{ID: id, Owner: owner}, nil)
+	webhooksRepo.EXPECT().ListDeliveries(ctx, id, int64(deliveriesLimit)).Return([]domain.WebhookDelivery{}, nil)
+
+	res, err := s.ListDeliveries(ctx, id, owner)
+
+	require.NoError(t, err)
+	require.IsType(t, []domain.WebhookDelivery{}, res)
+}
+
+func TestWebhooksService_Emit(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	owner := primitive.NewObjectID()
+	hooks := []domain.Webhook{{ID: primitive.NewObjectID()}, {ID: primitive.NewObjectID()}}
+
+	webhooksRepo.EXPECT().ListByOwnerAndEvent(gomock.Any(), owner, domain.EventURLCreated).Return(hooks, nil)
+
+	var deliveries []domain.WebhookDelivery
+
+	webhooksRepo.EXPECT().CreateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, delivery domain.WebhookDelivery) (primitive.ObjectID, error) {
+			deliveries = append(deliveries, delivery)
+
+			return primitive.NewObjectID(), nil
+		}).Times(2)
+
+	s.Emit(ctx, owner, domain.EventURLCreated, domain.URL{Alias: "alias"})
+
+	require.Len(t, deliveries, 2)
+
+	for i, delivery := range deliveries {
+		require.Equal(t, hooks[i].ID, delivery.Webhook)
+		require.True(t, delivery.Pending)
+		require.False(t, delivery.NextAttemptAt.After(time.Now()))
+		require.Contains(t, delivery.Payload, `"alias":"alias"`)
+	}
+
+	// Poller is woken up
+	require.Len(t, s.wake, 1)
+}
+
+func TestWebhooksService_attempt(t *testing.T) {
+	s, webhooksRepo := mockWebhooksService(t)
+
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	hook := domain.Webhook{ID: primitive.NewObjectID(), URL: srv.URL, Secret: "secret"}
+
+	webhooksRepo.EXPECT().Get(ctx, hook.ID).Return(hook, nil).Times(2)
+
+	var deliveries []domain.WebhookDelivery
+
+	webhooksRepo.EXPECT().UpdateDelivery(ctx, gomock.Any()).DoAndReturn(
+		func(_ context.Context, delivery domain.WebhookDelivery) error {
+			deliveries = append(deliveries, delivery)
+
+			return nil
+		}).Times(2)
+
+	// Failed delivery is scheduled with backoff instead of waiting for it
+	s.attempt(ctx, domain.WebhookDelivery{Webhook: hook.ID, Payload: `{}`, Pending: true})
+
+	require.Len(t, deliveries, 1)
+	require.True(t, deliveries[0].Pending)
+	require.Equal(t, 1, deliveries[0].Attempts)
+	require.Equal(t, http.StatusServiceUnavailable, deliveries[0].StatusCode)
+	require.Equal(t, deliveries[0].AttemptedAt.Add(time.Millisecond), deliveries[0].NextAttemptAt)
+
+	// The last attempt finishes delivery
+	s.attempt(ctx, domain.WebhookDelivery{Webhook: hook.ID, Payload: `{}`, Pending: true, Attempts: 2})
+
+	require.Len(t, deliveries, 2)
+	require.False(t, deliveries[1].Pending)
+	require.False(t, deliveries[1].Delivered)
+	require.Equal(t, 3, deliveries[1].Attempts)
+}
+
+func TestWebhooksService_RunFailingReceiver(t *testing.T) {
+	db, err := boltdb.NewClient(t.TempDir())
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	_, err = repo.MigrateBolt(db)
+	require.NoError(t, err)
+
+	webhooksRepo := repo.NewBoltRepos(db).Webhooks
+
+	var failed int32
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&failed, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	received := make(chan string, 2)
+
+	working := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		received <- string(body)
+	}))
+	defer working.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	owner := primitive.NewObjectID()
+	hookIds := make([]primitive.ObjectID, 0, 2)
+
+	for _, url := range []string{failing.URL, working.URL} {
+		id, err := webhooksRepo.Create(ctx, domain.Webhook{
+			URL:    url,
+			Secret: "secret",
+			Events: []string{domain.EventURLCreated},
+			Owner:  owner,
+		})
+		require.NoError(t, err)
+
+		hookIds = append(hookIds, id)
+	}
+
+	// Single worker and backoff longer than test, so waiting for retry of failing receiver would block every event
+	s := newWebhooksService(webhooksRepo, webhook.NewHTTPSender(time.Second, http.DefaultTransport), 1, 10, 3,
+		time.Hour, 10*time.Millisecond)
+
+	done := make(chan struct{})
+
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+
+	s.Emit(ctx, owner, domain.EventURLCreated, domain.URL{Alias: "first"})
+	s.Emit(ctx, owner, domain.EventURLCreated, domain.URL{Alias: "second"})
+
+	for _, alias := range []string{"first", "second"} {
+		select {
+		case body := <-received:
+			require.Contains(t, body, `"alias":"`+alias+`"`)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("event %s is not delivered to working receiver", alias)
+		}
+	}
+
+	cancel()
+	<-done
+
+	require.NotZero(t, atomic.LoadInt32(&failed))
+
+	// Deliveries to failing receiver are kept for retry
+	deliveries, err := webhooksRepo.ListDeliveries(context.Background(), hookIds[0], 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	for _, delivery := range deliveries {
+		require.False(t, delivery.Delivered)
+		require.True(t, delivery.Pending)
+		require.True(t, delivery.NextAttemptAt.After(time.Now().Add(time.Minute)))
+	}
+}
@@ -0,0 +1,19 @@
+package tracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Middleware starts span of request continuing trace context of incoming headers
+// and returns trace context of the span in response headers
+func Middleware() []gin.HandlerFunc {
+	return []gin.HandlerFunc{otelgin.Middleware(ServiceName), respond}
+}
+
+// respond writes trace context of request to response headers before handler writes body
+func respond(c *gin.Context) {
+	otel.GetTextMapPropagator().Inject(c.Request.Context(), propagation.HeaderCarrier(c.Writer.Header()))
+}
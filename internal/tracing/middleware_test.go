@@ -0,0 +1,43 @@
+package tracing
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+	_, exporter := NewInMemoryProvider()
+
+	r := gin.New()
+	r.Use(Middleware()...)
+	r.GET("/:alias", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	const (
+		traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+		spanID  = "00f067aa0ba902b7"
+	)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/alias", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-"+spanID+"-01")
+
+	r.ServeHTTP(w, req)
+
+	spans := exporter.GetSpans()
+
+	require.Len(t, spans, 1)
+	require.Equal(t, "/:alias", spans[0].Name)
+	require.Equal(t, traceID, spans[0].SpanContext.TraceID().String())
+	require.Equal(t, spanID, spans[0].Parent.SpanID().String())
+
+	// Response continues the same trace with span of request
+	traceparent := w.Header().Get("traceparent")
+
+	require.True(t, strings.HasPrefix(traceparent, "00-"+traceID+"-"+spans[0].SpanContext.SpanID().String()))
+}
@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
+)
+
+// Name of service in exported spans
+const ServiceName = "tiny-url"
+
+// Supported exporters of spans, tracing is disabled without exporter
+const (
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// NewExporter creates exporter of spans by its kind. OTLP spans are sent over HTTP to endpoint,
+// nil exporter is returned for empty kind
+func NewExporter(ctx context.Context, kind string, endpoint string, insecure bool) (sdktrace.SpanExporter, error) {
+	switch kind {
+	case "":
+		return nil, nil
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint)}
+
+		if insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		return otlptracehttp.New(ctx, opts...)
+	case ExporterStdout:
+		return stdouttrace.New()
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %s", kind)
+	}
+}
+
+// NewProvider creates provider exporting spans in batches and registers it globally together with
+// W3C trace context propagation
+func NewProvider(exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	return register(sdktrace.WithBatcher(exporter))
+}
+
+// NewInMemoryProvider registers provider keeping spans in memory synchronously, so spans
+// can be checked in tests right after they are ended
+func NewInMemoryProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+
+	return register(sdktrace.WithSyncer(exporter)), exporter
+}
+
+func register(opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append(opts, sdktrace.WithResource(resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceNameKey.String(ServiceName),
+	)))
+
+	provider := sdktrace.NewTracerProvider(opts...)
+
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider
+}
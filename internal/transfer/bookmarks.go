@@ -0,0 +1,29 @@
+package transfer
+
+import (
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/mebr0/tiny-url/pkg/bookmarks"
+	"io"
+)
+
+// decodeBookmarks reads links of browser bookmark file, folders are not kept
+func decodeBookmarks(r io.Reader) ([]domain.URLRecord, error) {
+	marks, err := bookmarks.Parse(r)
+
+	if err != nil {
+		return nil, malformed(0, err)
+	}
+
+	records := make([]domain.URLRecord, 0, len(marks))
+
+	for _, mark := range marks {
+		records = append(records, domain.URLRecord{
+			Original:  mark.URL,
+			CreatedAt: mark.AddedAt,
+			Tags:      mark.Tags,
+			Title:     mark.Title,
+		})
+	}
+
+	return records, nil
+}
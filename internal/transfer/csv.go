@@ -0,0 +1,250 @@
+package transfer
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Separator of tags within one cell
+const tagsSeparator = "|"
+
+// Columns of csv file in order of export, rules and variants are kept as json
+var csvColumns = []string{
+	"alias", "original", "createdAt", "expiredAt", "tags", "forwardQuery", "forwardPath", "rules", "variants",
+	"stickyVariants", "title", "description", "favicon", "image", "clicks",
+}
+
+var errNoOriginalColumn = errors.New("original column is missing")
+
+type csvEncoder struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func newCSVEncoder(w io.Writer) *csvEncoder {
+	return &csvEncoder{
+		w: csv.NewWriter(w),
+	}
+}
+
+func (e *csvEncoder) Encode(record domain.URLRecord) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	rules, err := jsonCell(record.Rules, len(record.Rules))
+
+	if err != nil {
+		return err
+	}
+
+	variants, err := jsonCell(record.Variants, len(record.Variants))
+
+	if err != nil {
+		return err
+	}
+
+	err = e.w.Write([]string{
+		record.Alias,
+		record.Original,
+		timeCell(record.CreatedAt),
+		timeCell(record.ExpiredAt),
+		strings.Join(record.Tags, tagsSeparator),
+		strconv.FormatBool(record.ForwardQuery),
+		strconv.FormatBool(record.ForwardPath),
+		rules,
+		variants,
+		strconv.FormatBool(record.StickyVariants),
+		record.Title,
+		record.Description,
+		record.Favicon,
+		record.Image,
+		strconv.FormatInt(record.Clicks, 10),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	// Flush every record, so client receives file while it is being written
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *csvEncoder) Close() error {
+	// Empty export still has header
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+
+	e.w.Flush()
+
+	return e.w.Error()
+}
+
+func (e *csvEncoder) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+
+	e.headerWritten = true
+
+	return e.w.Write(csvColumns)
+}
+
+// decodeCSV reads records by header, so columns may be reordered or omitted except original
+func decodeCSV(r io.Reader) ([]domain.URLRecord, error) {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+
+	if err != nil {
+		if err == io.EOF {
+			return []domain.URLRecord{}, nil
+		}
+
+		return nil, malformed(0, err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+
+	if _, ok := columns["original"]; !ok {
+		return nil, malformed(0, errNoOriginalColumn)
+	}
+
+	records := make([]domain.URLRecord, 0)
+
+	for row := 1; ; row++ {
+		cells, err := reader.Read()
+
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, malformed(row, err)
+		}
+
+		record, err := csvRecord(cells, columns)
+
+		if err != nil {
+			return nil, malformed(row, err)
+		}
+
+		records = append(records, record)
+	}
+}
+
+func csvRecord(cells []string, columns map[string]int) (domain.URLRecord, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok {
+			return strings.TrimSpace(cells[i])
+		}
+
+		return ""
+	}
+
+	record := domain.URLRecord{
+		Alias:       cell("alias"),
+		Original:    cell("original"),
+		Title:       cell("title"),
+		Description: cell("description"),
+		Favicon:     cell("favicon"),
+		Image:       cell("image"),
+	}
+
+	if tags := cell("tags"); tags != "" {
+		record.Tags = strings.Split(tags, tagsSeparator)
+	}
+
+	var err error
+
+	if record.CreatedAt, err = timeValue(cell("createdAt")); err != nil {
+		return domain.URLRecord{}, fmt.Errorf("invalid createdAt: %w", err)
+	}
+
+	if record.ExpiredAt, err = timeValue(cell("expiredAt")); err != nil {
+		return domain.URLRecord{}, fmt.Errorf("invalid expiredAt: %w", err)
+	}
+
+	if record.ForwardQuery, err = boolValue(cell("forwardQuery")); err != nil {
+		return domain.URLRecord{}, fmt.Errorf("invalid forwardQuery: %w", err)
+	}
+
+	if record.ForwardPath, err = boolValue(cell("forwardPath")); err != nil {
+		return domain.URLRecord{}, fmt.Errorf("invalid forwardPath: %w", err)
+	}
+
+	if record.StickyVariants, err = boolValue(cell("stickyVariants")); err != nil {
+		return domain.URLRecord{}, fmt.Errorf("invalid stickyVariants: %w", err)
+	}
+
+	if value := cell("rules"); value != "" {
+		if err := json.Unmarshal([]byte(value), &record.Rules); err != nil {
+			return domain.URLRecord{}, fmt.Errorf("invalid rules: %w", err)
+		}
+	}
+
+	if value := cell("variants"); value != "" {
+		if err := json.Unmarshal([]byte(value), &record.Variants); err != nil {
+			return domain.URLRecord{}, fmt.Errorf("invalid variants: %w", err)
+		}
+	}
+
+	if value := cell("clicks"); value != "" {
+		if record.Clicks, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return domain.URLRecord{}, fmt.Errorf("invalid clicks: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+// jsonCell encodes value of non-empty list to json, empty list is left blank
+func jsonCell(value interface{}, length int) (string, error) {
+	if length == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(value)
+
+	return string(data), err
+}
+
+func timeCell(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// timeValue parses time of cell, blank cell is zero time
+func timeValue(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+// boolValue parses flag of cell, blank cell is false
+func boolValue(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+
+	return strconv.ParseBool(value)
+}
@@ -0,0 +1,20 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mebr0/tiny-url/internal/domain"
+)
+
+var ErrUnknownFormat = errors.New("unknown format")
+
+// malformed error of file answered to client, row 0 means the file as a whole
+func malformed(row int, err error) error {
+	message := "malformed file: " + err.Error()
+
+	if row > 0 {
+		message = fmt.Sprintf("malformed row %d: %s", row, err)
+	}
+
+	return domain.NewError(domain.KindValidation, "malformed_import", message)
+}
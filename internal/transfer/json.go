@@ -0,0 +1,127 @@
+package transfer
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"io"
+)
+
+var errNotArray = errors.New("expected array of urls")
+
+// jsonEncoder writes records as elements of one array
+type jsonEncoder struct {
+	w       io.Writer
+	started bool
+}
+
+func newJSONEncoder(w io.Writer) *jsonEncoder {
+	return &jsonEncoder{
+		w: w,
+	}
+}
+
+func (e *jsonEncoder) Encode(record domain.URLRecord) error {
+	data, err := json.Marshal(record)
+
+	if err != nil {
+		return err
+	}
+
+	delimiter := ","
+
+	if !e.started {
+		delimiter = "["
+		e.started = true
+	}
+
+	_, err = e.w.Write(append([]byte(delimiter), data...))
+
+	return err
+}
+
+func (e *jsonEncoder) Close() error {
+	end := "]\n"
+
+	if !e.started {
+		end = "[]\n"
+	}
+
+	_, err := io.WriteString(e.w, end)
+
+	return err
+}
+
+// ndjsonEncoder writes every record as json on separate line
+type ndjsonEncoder struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONEncoder(w io.Writer) *ndjsonEncoder {
+	return &ndjsonEncoder{
+		encoder: json.NewEncoder(w),
+	}
+}
+
+func (e *ndjsonEncoder) Encode(record domain.URLRecord) error {
+	return e.encoder.Encode(record)
+}
+
+func (e *ndjsonEncoder) Close() error {
+	return nil
+}
+
+func decodeJSON(r io.Reader) ([]domain.URLRecord, error) {
+	decoder := json.NewDecoder(r)
+
+	token, err := decoder.Token()
+
+	if err != nil {
+		return nil, malformed(0, err)
+	}
+
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return nil, malformed(0, errNotArray)
+	}
+
+	records := make([]domain.URLRecord, 0)
+
+	for row := 1; decoder.More(); row++ {
+		var record domain.URLRecord
+
+		if err := decoder.Decode(&record); err != nil {
+			return nil, malformed(row, err)
+		}
+
+		records = append(records, record)
+	}
+
+	// Closing bracket of array
+	if _, err := decoder.Token(); err != nil {
+		return nil, malformed(0, err)
+	}
+
+	return records, nil
+}
+
+func decodeNDJSON(r io.Reader) ([]domain.URLRecord, error) {
+	decoder := json.NewDecoder(r)
+
+	records := make([]domain.URLRecord, 0)
+
+	for row := 1; ; row++ {
+		var record domain.URLRecord
+
+		err := decoder.Decode(&record)
+
+		if err == io.EOF {
+			return records, nil
+		}
+
+		if err != nil {
+			return nil, malformed(row, err)
+		}
+
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,57 @@
+package transfer
+
+import (
+	"github.com/mebr0/tiny-url/internal/domain"
+	"io"
+)
+
+// Encoder writes records of urls one by one, so export is streamed without collecting whole file
+type Encoder interface {
+	Encode(record domain.URLRecord) error
+	// Close finishes file after the last record, it does not close writer
+	Close() error
+}
+
+// NewEncoder create encoder of export format writing to w
+func NewEncoder(w io.Writer, format string) (Encoder, error) {
+	switch format {
+	case domain.FormatCSV:
+		return newCSVEncoder(w), nil
+	case domain.FormatJSON:
+		return newJSONEncoder(w), nil
+	case domain.FormatNDJSON:
+		return newNDJSONEncoder(w), nil
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// Decode reads all records of imported file in format, malformed file is reported with number of its row
+func Decode(r io.Reader, format string) ([]domain.URLRecord, error) {
+	switch format {
+	case domain.FormatCSV:
+		return decodeCSV(r)
+	case domain.FormatJSON:
+		return decodeJSON(r)
+	case domain.FormatNDJSON:
+		return decodeNDJSON(r)
+	case domain.FormatBookmarks:
+		return decodeBookmarks(r)
+	}
+
+	return nil, ErrUnknownFormat
+}
+
+// ContentType media type of file in format
+func ContentType(format string) string {
+	switch format {
+	case domain.FormatCSV:
+		return "text/csv; charset=utf-8"
+	case domain.FormatNDJSON:
+		return "application/x-ndjson"
+	case domain.FormatBookmarks:
+		return "text/html; charset=utf-8"
+	}
+
+	return "application/json; charset=utf-8"
+}
@@ -0,0 +1,151 @@
+package transfer
+
+import (
+	"bytes"
+	"github.com/mebr0/tiny-url/internal/domain"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	records := []domain.URLRecord{
+		{
+			Alias:        "qwerty",
+			Original:     "https://google.com/",
+			CreatedAt:    time.Date(2021, 5, 9, 9, 29, 18, 169000000, time.UTC),
+			ExpiredAt:    time.Date(2021, 6, 9, 9, 29, 18, 0, time.UTC),
+			Tags:         []string{"search", "daily news"},
+			ForwardQuery: true,
+			Rules: []domain.RedirectRule{
+				{OS: "ios", Destination: "https://apps.apple.com/"},
+			},
+			Variants: []domain.Variant{
+				{Name: "a", Destination: "https://google.com/a", Weight: 1, Clicks: 3},
+			},
+			StickyVariants: true,
+			Title:          "Google, \"search\"",
+			Description:    "Search the world's\ninformation",
+			Clicks:         10,
+		},
+		{
+			Alias:    "asdfgh",
+			Original: "https://golang.org/",
+		},
+	}
+
+	for _, format := range []string{domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			encoder, err := NewEncoder(&buf, format)
+			require.NoError(t, err)
+
+			for _, record := range records {
+				require.NoError(t, encoder.Encode(record))
+			}
+
+			require.NoError(t, encoder.Close())
+
+			decoded, err := Decode(&buf, format)
+
+			require.NoError(t, err)
+			require.Equal(t, records, decoded)
+		})
+	}
+}
+
+func TestEmptyExport(t *testing.T) {
+	for _, format := range []string{domain.FormatCSV, domain.FormatJSON, domain.FormatNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			encoder, err := NewEncoder(&buf, format)
+			require.NoError(t, err)
+			require.NoError(t, encoder.Close())
+
+			decoded, err := Decode(&buf, format)
+
+			require.NoError(t, err)
+			require.Equal(t, []domain.URLRecord{}, decoded)
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   string
+		input    string
+		expected []domain.URLRecord
+		err      string
+	}{
+		{
+			name:   "csv with some columns",
+			format: domain.FormatCSV,
+			input:  "original,tags\nhttps://google.com/,search|daily\n",
+			expected: []domain.URLRecord{
+				{Original: "https://google.com/", Tags: []string{"search", "daily"}},
+			},
+		},
+		{
+			name:   "csv without original",
+			format: domain.FormatCSV,
+			input:  "alias,title\nqwerty,Google\n",
+			err:    "malformed file: original column is missing",
+		},
+		{
+			name:   "csv invalid cell",
+			format: domain.FormatCSV,
+			input:  "original,clicks\nhttps://google.com/,10\nhttps://golang.org/,many\n",
+			err:    `malformed row 2: invalid clicks: strconv.ParseInt: parsing "many": invalid syntax`,
+		},
+		{
+			name:   "json object",
+			format: domain.FormatJSON,
+			input:  `{"original":"https://google.com/"}`,
+			err:    "malformed file: expected array of urls",
+		},
+		{
+			name:   "ndjson invalid line",
+			format: domain.FormatNDJSON,
+			input:  "{\"original\":\"https://google.com/\"}\n{\"clicks\":\"many\"}\n",
+			err:    "malformed row 2: json: cannot unmarshal string into Go struct field URLRecord.clicks of type int64",
+		},
+		{
+			name:   "bookmarks",
+			format: domain.FormatBookmarks,
+			input: `<DL><p>
+				<DT><A HREF="https://golang.org/" ADD_DATE="1620552558" TAGS="go">The Go Programming Language</A>
+			</DL><p>`,
+			expected: []domain.URLRecord{
+				{
+					Original:  "https://golang.org/",
+					CreatedAt: time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC),
+					Tags:      []string{"go"},
+					Title:     "The Go Programming Language",
+				},
+			},
+		},
+		{
+			name:   "unknown format",
+			format: "xml",
+			err:    ErrUnknownFormat.Error(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, err := Decode(strings.NewReader(tt.input), tt.format)
+
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, records)
+		})
+	}
+}
@@ -7,10 +7,17 @@ import (
 	"time"
 )
 
-// TokenManager provides token issuing and decoding
+// TokenManager provides token issuing and decoding. Generation of token lets issuer revoke all tokens
+// of subject issued before by changing current generation
 type TokenManager interface {
-	Issue(subject string, ttl time.Duration) (string, error)
-	Decode(token string) (string, error)
+	Issue(subject string, generation int64, ttl time.Duration) (string, error)
+	Decode(token string) (string, int64, error)
+}
+
+// claims of token, tokens issued without generation have zero one
+type claims struct {
+	jwt.StandardClaims
+	Generation int64 `json:"gen,omitempty"`
 }
 
 type JWTManager struct {
@@ -25,17 +32,20 @@ func NewJWTManager(signingKey string) (*JWTManager, error) {
 	return &JWTManager{signingKey: signingKey}, nil
 }
 
-func (m *JWTManager) Issue(subject string, ttl time.Duration) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
-		ExpiresAt: time.Now().Add(ttl).Unix(),
-		Subject:   subject,
+func (m *JWTManager) Issue(subject string, generation int64, ttl time.Duration) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+			Subject:   subject,
+		},
+		Generation: generation,
 	})
 
 	return token.SignedString([]byte(m.signingKey))
 }
 
-func (m *JWTManager) Decode(token string) (string, error) {
-	t, err := jwt.Parse(token, func(token *jwt.Token) (i interface{}, err error) {
+func (m *JWTManager) Decode(token string) (string, int64, error) {
+	t, err := jwt.ParseWithClaims(token, &claims{}, func(token *jwt.Token) (i interface{}, err error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -44,13 +54,13 @@ func (m *JWTManager) Decode(token string) (string, error) {
 	})
 
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	claims, ok := t.Claims.(jwt.MapClaims)
+	c, ok := t.Claims.(*claims)
 	if !ok {
-		return "", fmt.Errorf("error get user claims from t")
+		return "", 0, fmt.Errorf("error get user claims from t")
 	}
 
-	return claims["sub"].(string), nil
+	return c.Subject, c.Generation, nil
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"github.com/dgrijalva/jwt-go"
 	"github.com/stretchr/testify/require"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"testing"
@@ -23,7 +24,7 @@ func TestJWTManager_Issue(t *testing.T) {
 
 	require.NoError(t, err)
 
-	token, err = m.Issue(userId, time.Duration(1)*time.Hour)
+	token, err = m.Issue(userId, 2, time.Duration(1)*time.Hour)
 
 	require.NoError(t, err)
 	require.NotNil(t, token)
@@ -34,10 +35,11 @@ func TestJWTManager_Decode(t *testing.T) {
 
 	require.NoError(t, err)
 
-	id, err := m.Decode(token)
+	id, generation, err := m.Decode(token)
 
 	require.NoError(t, err)
 	require.Equal(t, userId, id)
+	require.Equal(t, int64(2), generation)
 }
 
 func TestJWTManager_DecodeErr(t *testing.T) {
@@ -45,7 +47,27 @@ func TestJWTManager_DecodeErr(t *testing.T) {
 
 	require.NoError(t, err)
 
-	_, err = m.Decode("qwe")
+	_, _, err = m.Decode("qwe")
 
 	require.Error(t, err)
 }
+
+func TestJWTManager_DecodeWithoutGeneration(t *testing.T) {
+	m, err := NewJWTManager("key")
+
+	require.NoError(t, err)
+
+	// Tokens issued before generations have zero one
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Subject:   userId,
+	}).SignedString([]byte("key"))
+
+	require.NoError(t, err)
+
+	id, generation, err := m.Decode(legacy)
+
+	require.NoError(t, err)
+	require.Equal(t, userId, id)
+	require.Zero(t, generation)
+}
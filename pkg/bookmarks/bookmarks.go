@@ -0,0 +1,124 @@
+package bookmarks
+
+import (
+	"golang.org/x/net/html"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Bookmark is link of Netscape bookmark file exported by browsers
+type Bookmark struct {
+	URL   string
+	Title string
+	// Labels from TAGS attribute
+	Tags []string
+	// Names of enclosing folders from outermost to innermost
+	Folders []string
+	// Zero if ADD_DATE attribute is missing
+	AddedAt time.Time
+}
+
+// Parse reads bookmarks of Netscape bookmark file in order of appearance
+func Parse(r io.Reader) ([]Bookmark, error) {
+	var bookmarks []Bookmark
+	var folders []string
+	var current *Bookmark
+
+	z := html.NewTokenizer(r)
+
+	// Name of folder whose list of bookmarks is not opened yet
+	folder := ""
+	inFolder := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return bookmarks, nil
+			}
+
+			return nil, z.Err()
+		case html.StartTagToken:
+			name, hasAttr := z.TagName()
+
+			switch string(name) {
+			case "h3":
+				folder = ""
+				inFolder = true
+			case "dl":
+				folders = append(folders, folder)
+				folder = ""
+			case "a":
+				if !hasAttr {
+					continue
+				}
+
+				current = newBookmark(attributes(z), folders)
+			}
+		case html.TextToken:
+			if current != nil {
+				current.Title += string(z.Text())
+			} else if inFolder {
+				folder += string(z.Text())
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+
+			switch string(name) {
+			case "h3":
+				folder = strings.TrimSpace(folder)
+				inFolder = false
+			case "dl":
+				if len(folders) > 0 {
+					folders = folders[:len(folders)-1]
+				}
+			case "a":
+				if current != nil {
+					current.Title = strings.TrimSpace(current.Title)
+					bookmarks = append(bookmarks, *current)
+					current = nil
+				}
+			}
+		}
+	}
+}
+
+func newBookmark(attrs map[string]string, folders []string) *Bookmark {
+	bookmark := &Bookmark{
+		URL: attrs["href"],
+	}
+
+	// The outermost list is the file itself, not a folder
+	for _, folder := range folders {
+		if folder != "" {
+			bookmark.Folders = append(bookmark.Folders, folder)
+		}
+	}
+
+	for _, tag := range strings.Split(attrs["tags"], ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			bookmark.Tags = append(bookmark.Tags, tag)
+		}
+	}
+
+	if added, err := strconv.ParseInt(attrs["add_date"], 10, 64); err == nil && added > 0 {
+		bookmark.AddedAt = time.Unix(added, 0).UTC()
+	}
+
+	return bookmark
+}
+
+func attributes(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+
+	for {
+		key, val, more := z.TagAttr()
+		attrs[strings.ToLower(string(key))] = strings.TrimSpace(string(val))
+
+		if !more {
+			return attrs
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package bookmarks
+
+import (
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+	"time"
+)
+
+const file = `<!DOCTYPE NETSCAPE-Bookmark-file-1>
+<META HTTP-EQUIV="Content-Type" CONTENT="text/html; charset=UTF-8">
+<TITLE>Bookmarks</TITLE>
+<H1>Bookmarks</H1>
+<DL><p>
+    <DT><H3 ADD_DATE="1620552558">Work</H3>
+    <DL><p>
+        <DT><H3>Docs</H3>
+        <DL><p>
+            <DT><A HREF="https://golang.org/doc/" ADD_DATE="1620552558" TAGS="go, docs">Documentation &amp; guides</A>
+        </DL><p>
+        <DT><A HREF="https://github.com/">GitHub</A>
+    </DL><p>
+    <DT><A HREF="https://google.com/" ADD_DATE="0">Google</A>
+</DL><p>`
+
+func TestParse(t *testing.T) {
+	bookmarks, err := Parse(strings.NewReader(file))
+
+	require.NoError(t, err)
+	require.Equal(t, []Bookmark{
+		{
+			URL:     "https://golang.org/doc/",
+			Title:   "Documentation & guides",
+			Tags:    []string{"go", "docs"},
+			Folders: []string{"Work", "Docs"},
+			AddedAt: time.Date(2021, 5, 9, 9, 29, 18, 0, time.UTC),
+		},
+		{
+			URL:     "https://github.com/",
+			Title:   "GitHub",
+			Folders: []string{"Work"},
+		},
+		{
+			URL:   "https://google.com/",
+			Title: "Google",
+		},
+	}, bookmarks)
+}
@@ -0,0 +1,162 @@
+package bot
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultSignatures are lowercase parts of User-Agent headers of link previews, crawlers and scripted clients.
+// Only concrete tokens are listed, since generic words like "bot" or "preview" appear in agents of apps and browsers
+var DefaultSignatures = []string{
+	"googlebot", "google-inspectiontool", "adsbot-google", "bingbot", "bingpreview", "yandexbot", "baiduspider",
+	"duckduckbot", "applebot", "petalbot", "yahoo! slurp", "ahrefsbot", "semrushbot", "mj12bot", "dotbot",
+	"bytespider", "gptbot", "slackbot", "slack-imgproxy", "twitterbot", "facebookexternalhit", "facebookcatalog",
+	"linkedinbot", "discordbot", "telegrambot", "redditbot", "pinterestbot", "whatsapp/", "skypeuripreview",
+	"embedly", "iframely", "vkshare", "qwantify", "bitlybot", "outbrain", "headlesschrome", "phantomjs",
+	"chrome-lighthouse", "curl/", "wget/", "python-requests", "python-urllib", "go-http-client", "okhttp",
+	"java/", "apache-httpclient", "node-fetch", "axios/", "libwww-perl",
+}
+
+// Detector tells requests of bots from requests of people
+type Detector interface {
+	IsBot(r *http.Request) bool
+}
+
+// SignatureDetector detects bots by signatures in User-Agent header and by headers browsers always send
+type SignatureDetector struct {
+	mu         sync.RWMutex
+	signatures []string
+}
+
+func NewSignatureDetector(signatures []string) *SignatureDetector {
+	d := &SignatureDetector{}
+	d.SetSignatures(signatures)
+
+	return d
+}
+
+// SetSignatures replaces signatures, matching is case-insensitive
+func (d *SignatureDetector) SetSignatures(signatures []string) {
+	lower := make([]string, 0, len(signatures))
+
+	for _, signature := range signatures {
+		if signature = strings.ToLower(strings.TrimSpace(signature)); signature != "" {
+			lower = append(lower, signature)
+		}
+	}
+
+	d.mu.Lock()
+	d.signatures = lower
+	d.mu.Unlock()
+}
+
+func (d *SignatureDetector) IsBot(r *http.Request) bool {
+	ua := r.UserAgent()
+
+	if ua == "" {
+		return true
+	}
+
+	// Prefetches of browsers are not visits either
+	for _, header := range []string{"Purpose", "Sec-Purpose", "X-Purpose", "X-Moz"} {
+		if purpose := strings.ToLower(r.Header.Get(header)); strings.Contains(purpose, "prefetch") ||
+			strings.Contains(purpose, "preview") {
+			return true
+		}
+	}
+
+	// Every browser identifies as Mozilla and sends preferred languages
+	if !strings.HasPrefix(ua, "Mozilla/") && r.Header.Get("Accept-Language") == "" {
+		return true
+	}
+
+	ua = strings.ToLower(ua)
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, signature := range d.signatures {
+		if strings.Contains(ua, signature) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileDetector detects bots by default signatures and signatures from file, reloaded when file is modified
+type FileDetector struct {
+	*SignatureDetector
+
+	path    string
+	modTime time.Time
+}
+
+// NewFileDetector reads signatures from file with one signature per line, lines starting with # are ignored
+func NewFileDetector(path string) (*FileDetector, error) {
+	d := &FileDetector{
+		SignatureDetector: NewSignatureDetector(DefaultSignatures),
+		path:              path,
+	}
+
+	if _, err := d.Reload(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Reload reads signatures again if file was modified since last reading and reports whether they were read
+func (d *FileDetector) Reload() (bool, error) {
+	info, err := os.Stat(d.path)
+
+	if err != nil {
+		return false, err
+	}
+
+	if info.ModTime().Equal(d.modTime) {
+		return false, nil
+	}
+
+	f, err := os.Open(d.path)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer f.Close()
+
+	signatures, err := ReadSignatures(f)
+
+	if err != nil {
+		return false, err
+	}
+
+	d.SetSignatures(append(signatures, DefaultSignatures...))
+	d.modTime = info.ModTime()
+
+	return true, nil
+}
+
+// ReadSignatures reads signatures in format of signatures file from r
+func ReadSignatures(r io.Reader) ([]string, error) {
+	signatures := make([]string, 0)
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		signatures = append(signatures, line)
+	}
+
+	return signatures, scanner.Err()
+}
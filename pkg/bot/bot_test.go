@@ -0,0 +1,135 @@
+package bot
+
+import (
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const chrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36"
+
+func TestSignatureDetector_IsBot(t *testing.T) {
+	d := NewSignatureDetector(DefaultSignatures)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected bool
+	}{
+		{name: "browser", headers: map[string]string{"User-Agent": chrome, "Accept-Language": "en"}},
+		{name: "browser without languages", headers: map[string]string{"User-Agent": chrome}},
+		{name: "app with languages", headers: map[string]string{"User-Agent": "Telegram/7.8", "Accept-Language": "ru"}},
+		{name: "empty user agent", headers: map[string]string{}, expected: true},
+		{
+			name:     "slack preview",
+			headers:  map[string]string{"User-Agent": "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"},
+			expected: true,
+		},
+		{
+			name:     "twitter card",
+			headers:  map[string]string{"User-Agent": "Twitterbot/1.0"},
+			expected: true,
+		},
+		{
+			name:     "facebook",
+			headers:  map[string]string{"User-Agent": "facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)"},
+			expected: true,
+		},
+		{
+			name:     "crawler pretending to be browser",
+			headers:  map[string]string{"User-Agent": "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"},
+			expected: true,
+		},
+		{
+			name:     "prefetch",
+			headers:  map[string]string{"User-Agent": chrome, "Accept-Language": "en", "Sec-Purpose": "prefetch"},
+			expected: true,
+		},
+		{
+			name:     "script",
+			headers:  map[string]string{"User-Agent": "Go-http-client/1.1", "Accept-Language": "en"},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+
+			for key, value := range tt.headers {
+				r.Header.Set(key, value)
+			}
+
+			require.Equal(t, tt.expected, d.IsBot(r))
+		})
+	}
+}
+
+func TestSignatureDetector_IsBotBrowsers(t *testing.T) {
+	d := NewSignatureDetector(DefaultSignatures)
+
+	agents := map[string]string{
+		"chrome":         chrome,
+		"firefox":        "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0",
+		"safari":         "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15",
+		"edge":           "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59",
+		"iphone safari":  "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1",
+		"android chrome": "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36",
+		"samsung":        "Mozilla/5.0 (Linux; Android 11; SM-G991B) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/14.2 Chrome/87.0.4280.141 Mobile Safari/537.36",
+		"in-app preview": "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Mobile/15E148 PreviewApp/3.2",
+		"bot framework":  "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36 Robotics/1.0",
+	}
+
+	for name, agent := range agents {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("User-Agent", agent)
+			r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+
+			require.False(t, d.IsBot(r))
+		})
+	}
+}
+
+func TestFileDetector_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bots.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("# custom signatures\nMonitor\n"), 0644))
+
+	d, err := NewFileDetector(path)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("User-Agent", chrome+" UptimeMonitor")
+	r.Header.Set("Accept-Language", "en")
+
+	require.True(t, d.IsBot(r))
+
+	// Unchanged file is not read again
+	reloaded, err := d.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("checker\n"), 0644))
+	require.NoError(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Minute)))
+
+	reloaded, err = d.Reload()
+	require.NoError(t, err)
+	require.True(t, reloaded)
+	require.False(t, d.IsBot(r))
+
+	// Default signatures are kept
+	r.Header.Set("User-Agent", "Twitterbot/1.0")
+	require.True(t, d.IsBot(r))
+}
+
+func TestReadSignatures(t *testing.T) {
+	signatures, err := ReadSignatures(strings.NewReader("# comment\n\n  Slackbot  \ncurl\n"))
+
+	require.NoError(t, err)
+	require.Equal(t, []string{"Slackbot", "curl"}, signatures)
+}
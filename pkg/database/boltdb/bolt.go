@@ -0,0 +1,23 @@
+package boltdb
+
+import (
+	"go.etcd.io/bbolt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const timeout = 10 * time.Second
+
+// Name of database file inside data directory
+const fileName = "tiny-url.db"
+
+// NewClient opens database file in data directory, creating both if needed.
+// File is locked while opened, so only one instance may use data directory
+func NewClient(dataDir string) (*bbolt.DB, error) {
+	if err := os.MkdirAll(dataDir, 0700); err != nil {
+		return nil, err
+	}
+
+	return bbolt.Open(filepath.Join(dataDir, fileName), 0600, &bbolt.Options{Timeout: timeout})
+}
@@ -0,0 +1,85 @@
+package boltdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"go.etcd.io/bbolt"
+	"sort"
+	"time"
+)
+
+// Bucket with records of applied migrations
+var migrationsBucket = []byte("migrations")
+
+var ErrDuplicateMigration = errors.New("migration version is not unique")
+
+// Migration creates buckets or changes data of database
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *bbolt.Tx) error
+}
+
+// Migrate applies migrations not recorded in migrations bucket in order of versions
+// and returns versions of applied ones. Each migration is applied in its own transaction
+func Migrate(db *bbolt.DB, migrations []Migration) ([]int, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateMigration, sorted[i].Version)
+		}
+	}
+
+	versions := make([]int, 0)
+
+	for _, m := range sorted {
+		applied := false
+
+		err := db.Update(func(tx *bbolt.Tx) error {
+			records, err := tx.CreateBucketIfNotExists(migrationsBucket)
+
+			if err != nil {
+				return err
+			}
+
+			key := versionKey(m.Version)
+
+			if records.Get(key) != nil {
+				return nil
+			}
+
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+
+			applied = true
+
+			return records.Put(key, []byte(m.Description+" "+time.Now().Format(time.RFC3339)))
+		})
+
+		if err != nil {
+			return versions, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+
+		if applied {
+			versions = append(versions, m.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// versionKey encodes version in big endian, so records are ordered by version
+func versionKey(version int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(version))
+
+	return key
+}
@@ -0,0 +1,49 @@
+package boltdb
+
+import (
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	db, err := NewClient(t.TempDir())
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = db.Close()
+	})
+
+	migrations := []Migration{
+		{Version: 2, Description: "second", Up: func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucket([]byte("second"))
+			return err
+		}},
+		{Version: 1, Description: "first", Up: func(tx *bbolt.Tx) error {
+			_, err := tx.CreateBucket([]byte("first"))
+			return err
+		}},
+	}
+
+	versions, err := Migrate(db, migrations)
+	require.NoError(t, err)
+	require.Equal(t, []int{1, 2}, versions)
+
+	// Applied migrations are not repeated, otherwise creation of existing bucket fails
+	versions, err = Migrate(db, migrations)
+	require.NoError(t, err)
+	require.Empty(t, versions)
+}
+
+func TestMigrateErrDuplicateMigration(t *testing.T) {
+	migrations := []Migration{
+		{Version: 2, Description: "second"},
+		{Version: 1, Description: "first"},
+		{Version: 2, Description: "second again"},
+	}
+
+	// Versions are validated before any access to database
+	_, err := Migrate(nil, migrations)
+
+	require.ErrorIs(t, err, ErrDuplicateMigration)
+}
@@ -0,0 +1,106 @@
+package mongodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"sort"
+	"time"
+)
+
+// Collection with records of applied migrations
+const migrationsCollection = "migrations"
+
+var ErrDuplicateMigration = errors.New("migration version is not unique")
+
+// Migration changes indexes or data of database. Migrations must be idempotent,
+// because concurrently started instances may apply the same migration twice
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(ctx context.Context, db *mongo.Database) error
+}
+
+type migrationRecord struct {
+	Version     int       `bson:"_id"`
+	Description string    `bson:"description"`
+	AppliedAt   time.Time `bson:"appliedAt"`
+}
+
+// Migrate applies migrations not recorded in migrations collection in order of versions
+// and returns versions of applied ones
+func Migrate(ctx context.Context, db *mongo.Database, migrations []Migration) ([]int, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateMigration, sorted[i].Version)
+		}
+	}
+
+	records := db.Collection(migrationsCollection)
+
+	applied, err := appliedVersions(ctx, records)
+
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0)
+
+	for _, m := range sorted {
+		if applied[m.Version] {
+			continue
+		}
+
+		if err := m.Up(ctx, db); err != nil {
+			return versions, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+
+		record := migrationRecord{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   time.Now(),
+		}
+
+		opts := options.Replace().SetUpsert(true)
+
+		if _, err := records.ReplaceOne(ctx, bson.M{"_id": m.Version}, record, opts); err != nil {
+			return versions, err
+		}
+
+		versions = append(versions, m.Version)
+	}
+
+	return versions, nil
+}
+
+func appliedVersions(ctx context.Context, records *mongo.Collection) (map[int]bool, error) {
+	var applied []migrationRecord
+
+	cur, err := records.Find(ctx, bson.M{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cur.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+
+	versions := make(map[int]bool, len(applied))
+
+	for _, record := range applied {
+		versions[record.Version] = true
+	}
+
+	return versions, nil
+}
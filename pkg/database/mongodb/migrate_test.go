@@ -0,0 +1,20 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMigrateErrDuplicateMigration(t *testing.T) {
+	migrations := []Migration{
+		{Version: 2, Description: "second"},
+		{Version: 1, Description: "first"},
+		{Version: 2, Description: "second again"},
+	}
+
+	// Versions are validated before any access to database
+	_, err := Migrate(context.Background(), nil, migrations)
+
+	require.ErrorIs(t, err, ErrDuplicateMigration)
+}
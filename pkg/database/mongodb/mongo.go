@@ -10,8 +10,9 @@ import (
 
 const timeout = 10 * time.Second
 
-// NewClient established connection to a mongoDb instance using provided URI and auth credentials
-func NewClient(uri, username, password string) (*mongo.Client, error) {
+// NewClient established connection to a mongoDb instance using provided URI and auth credentials,
+// extra options are applied on top of them
+func NewClient(uri, username, password string, extra ...*options.ClientOptions) (*mongo.Client, error) {
 	opts := options.Client().ApplyURI(uri)
 
 	if username != "" && password != "" {
@@ -21,7 +22,7 @@ func NewClient(uri, username, password string) (*mongo.Client, error) {
 		})
 	}
 
-	client, err := mongo.NewClient(opts)
+	client, err := mongo.NewClient(append([]*options.ClientOptions{opts}, extra...)...)
 	if err != nil {
 		return nil, err
 	}
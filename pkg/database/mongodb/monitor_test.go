@@ -0,0 +1,38 @@
+package mongodb
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/event"
+	"testing"
+)
+
+func TestChainMonitors(t *testing.T) {
+	calls := make([]string, 0)
+
+	first := &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			calls = append(calls, "first started")
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			calls = append(calls, "first succeeded")
+		},
+	}
+	second := &event.CommandMonitor{
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			calls = append(calls, "second succeeded")
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			calls = append(calls, "second failed")
+		},
+	}
+
+	monitor := ChainMonitors(first, second)
+	ctx := context.Background()
+
+	monitor.Started(ctx, &event.CommandStartedEvent{})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{})
+	monitor.Failed(ctx, &event.CommandFailedEvent{})
+
+	require.Equal(t, []string{"first started", "first succeeded", "second succeeded", "second failed"}, calls)
+}
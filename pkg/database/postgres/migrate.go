@@ -0,0 +1,100 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Key of advisory lock held while applying migrations
+const migrationsLock = 7260917
+
+var ErrDuplicateMigration = errors.New("migration version is not unique")
+
+// Migration changes schema or data of database with SQL statements
+type Migration struct {
+	Version     int
+	Description string
+	Up          string
+}
+
+// Migrate applies migrations not recorded in schema_migrations table in order of versions
+// and returns versions of applied ones. Each migration is applied in its own transaction,
+// concurrent runs are serialized with advisory lock
+func Migrate(ctx context.Context, db *sql.DB, migrations []Migration) ([]int, error) {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateMigration, sorted[i].Version)
+		}
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at  TIMESTAMPTZ NOT NULL
+	)`); err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0)
+
+	for _, m := range sorted {
+		applied, err := apply(ctx, db, m)
+
+		if err != nil {
+			return versions, fmt.Errorf("migration %d (%s): %w", m.Version, m.Description, err)
+		}
+
+		if applied {
+			versions = append(versions, m.Version)
+		}
+	}
+
+	return versions, nil
+}
+
+// apply runs migration unless it is already recorded
+func apply(ctx context.Context, db *sql.DB, m Migration) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock($1)", migrationsLock); err != nil {
+		return false, err
+	}
+
+	var exists bool
+
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", m.Version).Scan(&exists); err != nil {
+		return false, err
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return false, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version, description, applied_at) VALUES ($1, $2, $3)",
+		m.Version, m.Description, time.Now()); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
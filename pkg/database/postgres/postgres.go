@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+const timeout = 10 * time.Second
+
+// Code of unique_violation error
+const uniqueViolation = "23505"
+
+// NewClient opens pool of connections to a postgres instance using provided DSN and checks connection
+func NewClient(dsn string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dsn)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func IsDuplicate(err error) bool {
+	var e *pq.Error
+
+	if errors.As(err, &e) {
+		return e.Code == uniqueViolation
+	}
+
+	return false
+}
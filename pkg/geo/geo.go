@@ -0,0 +1,110 @@
+package geo
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+var ErrUnknownCountry = errors.New("country of ip is unknown")
+
+// Resolver provides resolving of country by ip address
+type Resolver interface {
+	Country(ctx context.Context, ip net.IP) (string, error)
+}
+
+// NopResolver never resolves country
+type NopResolver struct {
+}
+
+func NewNopResolver() *NopResolver {
+	return &NopResolver{}
+}
+
+func (r *NopResolver) Country(ctx context.Context, ip net.IP) (string, error) {
+	return "", ErrUnknownCountry
+}
+
+type network struct {
+	ipNet   *net.IPNet
+	country string
+}
+
+// CIDRResolver resolves country by the most specific network containing ip
+type CIDRResolver struct {
+	networks []network
+}
+
+// NewCIDRResolver reads networks from CSV file with lines in format <cidr>,<ISO 3166-1 alpha-2 country code>
+func NewCIDRResolver(path string) (*CIDRResolver, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return ReadCIDRResolver(f)
+}
+
+// ReadCIDRResolver reads networks in CSV format from r
+func ReadCIDRResolver(r io.Reader) (*CIDRResolver, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.Comment = '#'
+
+	var networks []network
+
+	for {
+		record, err := reader.Read()
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		_, ipNet, err := net.ParseCIDR(strings.TrimSpace(record[0]))
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %s: %w", record[0], err)
+		}
+
+		networks = append(networks, network{
+			ipNet:   ipNet,
+			country: strings.ToUpper(strings.TrimSpace(record[1])),
+		})
+	}
+
+	return &CIDRResolver{networks: networks}, nil
+}
+
+func (r *CIDRResolver) Country(ctx context.Context, ip net.IP) (string, error) {
+	country := ""
+	longest := -1
+
+	for _, n := range r.networks {
+		if !n.ipNet.Contains(ip) {
+			continue
+		}
+
+		if ones, _ := n.ipNet.Mask.Size(); ones > longest {
+			country = n.country
+			longest = ones
+		}
+	}
+
+	if country == "" {
+		return "", ErrUnknownCountry
+	}
+
+	return country, nil
+}
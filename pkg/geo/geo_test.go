@@ -0,0 +1,54 @@
+package geo
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"strings"
+	"testing"
+)
+
+const networks = `# network,country
+10.0.0.0/8,us
+10.1.0.0/16,KZ
+2001:db8::/32,DE
+`
+
+func TestCIDRResolver_Country(t *testing.T) {
+	r, err := ReadCIDRResolver(strings.NewReader(networks))
+
+	require.NoError(t, err)
+
+	for ip, expected := range map[string]string{
+		"10.0.0.1":    "US",
+		"10.1.2.3":    "KZ",
+		"2001:db8::1": "DE",
+	} {
+		country, err := r.Country(context.Background(), net.ParseIP(ip))
+
+		require.NoError(t, err)
+		require.Equal(t, expected, country, ip)
+	}
+}
+
+func TestCIDRResolver_CountryErrUnknownCountry(t *testing.T) {
+	r, err := ReadCIDRResolver(strings.NewReader(networks))
+
+	require.NoError(t, err)
+
+	_, err = r.Country(context.Background(), net.ParseIP("192.168.0.1"))
+
+	require.ErrorIs(t, err, ErrUnknownCountry)
+}
+
+func TestReadCIDRResolverErr(t *testing.T) {
+	_, err := ReadCIDRResolver(strings.NewReader("10.0.0.0,US"))
+
+	require.Error(t, err)
+}
+
+func TestNopResolver_Country(t *testing.T) {
+	_, err := NewNopResolver().Country(context.Background(), net.ParseIP("10.0.0.1"))
+
+	require.ErrorIs(t, err, ErrUnknownCountry)
+}
@@ -0,0 +1,89 @@
+package highlight
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Range of matched characters in text, end is exclusive
+type Range struct {
+	Start int
+	End   int
+}
+
+// Terms splits search query into lowercase terms by non alphanumeric characters, skipping negated words
+func Terms(query string) []string {
+	var terms []string
+
+	for _, word := range strings.Fields(query) {
+		if strings.HasPrefix(word, "-") {
+			continue
+		}
+
+		for _, term := range strings.FieldsFunc(word, isDelimiter) {
+			terms = append(terms, strings.ToLower(term))
+		}
+	}
+
+	return terms
+}
+
+// Find returns ordered and merged ranges of case insensitive occurrences of terms in text.
+// Offsets are counted in characters, not bytes
+func Find(text string, terms []string) []Range {
+	runes := []rune(text)
+
+	for i, r := range runes {
+		runes[i] = unicode.ToLower(r)
+	}
+
+	matched := make([]bool, len(runes))
+
+	for _, term := range terms {
+		t := []rune(term)
+
+		if len(t) == 0 {
+			continue
+		}
+
+		for i := 0; i+len(t) <= len(runes); i++ {
+			if equal(runes[i:i+len(t)], t) {
+				for j := i; j < i+len(t); j++ {
+					matched[j] = true
+				}
+			}
+		}
+	}
+
+	var ranges []Range
+
+	for i := 0; i < len(matched); i++ {
+		if !matched[i] {
+			continue
+		}
+
+		start := i
+
+		for i < len(matched) && matched[i] {
+			i++
+		}
+
+		ranges = append(ranges, Range{Start: start, End: i})
+	}
+
+	return ranges
+}
+
+func isDelimiter(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+}
+
+func equal(a, b []rune) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,49 @@
+package highlight
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestTerms(t *testing.T) {
+	require.Equal(t, []string{"getting", "started", "docs", "example", "com"}, Terms(`"Getting started" -blog docs.example.com`))
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		expected []Range
+	}{
+		{
+			name:     "no terms",
+			text:     "https://docs.example.com",
+			expected: nil,
+		},
+		{
+			name:     "case insensitive",
+			text:     "Getting Started",
+			terms:    []string{"started"},
+			expected: []Range{{Start: 8, End: 15}},
+		},
+		{
+			name:     "merge overlapping",
+			text:     "https://docs.example.com/docs",
+			terms:    []string{"docs", "ocs.ex"},
+			expected: []Range{{Start: 8, End: 15}, {Start: 25, End: 29}},
+		},
+		{
+			name:     "characters offsets",
+			text:     "Café menu",
+			terms:    []string{"menu"},
+			expected: []Range{{Start: 5, End: 9}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, Find(tt.text, tt.terms))
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package hll
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+)
+
+// Sketch of 2^10 registers estimates count with standard error about 3.25%
+const (
+	Precision = 10
+	Registers = 1 << Precision
+)
+
+// Hash of element with uniformly distributed bits, stable across processes
+func Hash(element string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(element))
+
+	// FNV leaves high bits poorly mixed, so they are finalized like in MurmurHash3
+	x := h.Sum64()
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+
+	return x
+}
+
+// Position returns register of hash and rank of its remaining bits, the position of their first set bit
+func Position(hash uint64) (int, uint8) {
+	register := int(hash >> (64 - Precision))
+
+	// Guard bit limits rank when remaining bits are zero
+	rest := hash<<Precision | 1<<(Precision-1)
+
+	return register, uint8(bits.LeadingZeros64(rest)) + 1
+}
+
+// Sketch is HyperLogLog estimating count of distinct elements by max ranks of registers.
+// Sketches of separate sets are merged into sketch of their union
+type Sketch struct {
+	registers [Registers]uint8
+}
+
+func New() *Sketch {
+	return &Sketch{}
+}
+
+// Add observes element
+func (s *Sketch) Add(element string) {
+	s.Observe(Position(Hash(element)))
+}
+
+// Observe raises register to rank, out of range registers are ignored
+func (s *Sketch) Observe(register int, rank uint8) {
+	if register < 0 || register >= Registers {
+		return
+	}
+
+	if rank > s.registers[register] {
+		s.registers[register] = rank
+	}
+}
+
+// Merge adds elements observed by other sketch
+func (s *Sketch) Merge(other *Sketch) {
+	for i, rank := range other.registers {
+		s.Observe(i, rank)
+	}
+}
+
+// Count estimates count of distinct observed elements
+func (s *Sketch) Count() uint64 {
+	m := float64(Registers)
+	alpha := 0.7213 / (1 + 1.079/m)
+
+	sum := 0.0
+	zeros := 0
+
+	for _, rank := range s.registers {
+		sum += math.Pow(2, -float64(rank))
+
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	estimate := alpha * m * m / sum
+
+	// Linear counting is more precise for small counts
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+
+	return uint64(math.Round(estimate))
+}
@@ -0,0 +1,61 @@
+package hll
+
+import (
+	"github.com/stretchr/testify/require"
+	"strconv"
+	"testing"
+)
+
+func TestSketch_Count(t *testing.T) {
+	tests := []struct {
+		name     string
+		distinct int
+	}{
+		{name: "empty", distinct: 0},
+		{name: "small", distinct: 100},
+		{name: "large", distinct: 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := New()
+
+			// Every element is observed twice
+			for i := 0; i < 2*tt.distinct; i++ {
+				s.Add(strconv.Itoa(i % tt.distinct))
+			}
+
+			require.InEpsilon(t, float64(tt.distinct)+1, float64(s.Count())+1, 0.05)
+		})
+	}
+}
+
+func TestSketch_Merge(t *testing.T) {
+	a, b, union := New(), New(), New()
+
+	for i := 0; i < 20000; i++ {
+		a.Add(strconv.Itoa(i))
+		union.Add(strconv.Itoa(i))
+	}
+
+	// Half of elements are shared
+	for i := 10000; i < 30000; i++ {
+		b.Add(strconv.Itoa(i))
+		union.Add(strconv.Itoa(i))
+	}
+
+	a.Merge(b)
+
+	require.Equal(t, union.Count(), a.Count())
+	require.InEpsilon(t, 30000, float64(a.Count()), 0.05)
+}
+
+func TestPosition(t *testing.T) {
+	register, rank := Position(0)
+	require.Equal(t, 0, register)
+	require.Equal(t, uint8(64-Precision+1), rank)
+
+	register, rank = Position(1<<63 | 1<<(63-Precision))
+	require.Equal(t, Registers/2, register)
+	require.Equal(t, uint8(1), rank)
+}
@@ -0,0 +1,233 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var ErrInvalidHeader = errors.New("header of message contains line break")
+
+// Message is plain text email to single recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender provides delivering of emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// SMTPSender sends emails through SMTP server, connection is upgraded to TLS if server supports it
+type SMTPSender struct {
+	host     string
+	addr     string
+	auth     smtp.Auth
+	from     string
+	envelope string
+}
+
+// NewSMTPSender creates sender authenticated with username and password, empty username disables authentication.
+// From may have display name, only its address is used as envelope sender
+func NewSMTPSender(host string, port int, username, password, from string) (*SMTPSender, error) {
+	address, err := netmail.ParseAddress(from)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q: %w", from, err)
+	}
+
+	s := &SMTPSender{
+		host:     host,
+		addr:     net.JoinHostPort(host, strconv.Itoa(port)),
+		from:     from,
+		envelope: address.Address,
+	}
+
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s, nil
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	data, err := format(s.from, msg, time.Now())
+
+	if err != nil {
+		return err
+	}
+
+	var d net.Dialer
+
+	conn, err := d.DialContext(ctx, "tcp", s.addr)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+	}
+
+	c, err := smtp.NewClient(conn, s.host)
+
+	if err != nil {
+		return err
+	}
+
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return err
+		}
+	}
+
+	if s.auth != nil {
+		if err := c.Auth(s.auth); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Mail(s.envelope); err != nil {
+		return err
+	}
+
+	if err := c.Rcpt(msg.To); err != nil {
+		return err
+	}
+
+	w, err := c.Data()
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// FileSender saves every email to separate .eml file in directory instead of sending, for local testing
+type FileSender struct {
+	dir  string
+	from string
+	seq  uint64
+}
+
+func NewFileSender(dir string, from string) *FileSender {
+	return &FileSender{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+
+	data, err := format(s.from, msg, now)
+
+	if err != nil {
+		return err
+	}
+
+	// Names are ordered by time of sending
+	name := fmt.Sprintf("%s-%d.eml", now.UTC().Format("20060102T150405.000000000"), atomic.AddUint64(&s.seq, 1))
+
+	return ioutil.WriteFile(filepath.Join(s.dir, name), data, 0600)
+}
+
+// WriterSender writes emails to w instead of sending, for local testing
+type WriterSender struct {
+	mu   sync.Mutex
+	w    io.Writer
+	from string
+}
+
+func NewWriterSender(w io.Writer, from string) *WriterSender {
+	return &WriterSender{
+		w:    w,
+		from: from,
+	}
+}
+
+func (s *WriterSender) Send(ctx context.Context, msg Message) error {
+	if err := checkHeaders(s.from, msg); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Body is kept readable in output
+	_, err := fmt.Fprintf(s.w, "From: %s\nTo: %s\nSubject: %s\n\n%s\n", s.from, msg.To, msg.Subject, msg.Body)
+
+	return err
+}
+
+// format encodes message in RFC 5322 format with body in quoted-printable encoding
+func format(from string, msg Message, date time.Time) ([]byte, error) {
+	if err := checkHeaders(from, msg); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString("From: " + from + "\r\n")
+	buf.WriteString("To: " + msg.To + "\r\n")
+	buf.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", msg.Subject) + "\r\n")
+	buf.WriteString("Date: " + date.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+
+	if _, err := w.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// checkHeaders prevents injection of headers through line breaks
+func checkHeaders(from string, msg Message) error {
+	for _, header := range []string{from, msg.To, msg.Subject} {
+		if strings.ContainsAny(header, "\r\n") {
+			return ErrInvalidHeader
+		}
+	}
+
+	return nil
+}
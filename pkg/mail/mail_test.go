@@ -0,0 +1,180 @@
+package mail
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"net/textproto"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFormat(t *testing.T) {
+	date := time.Date(2021, 5, 9, 15, 29, 18, 0, time.UTC)
+
+	data, err := format("noreply@tiny.url", Message{
+		To:      "sirius@gmail.com",
+		Subject: "Привет",
+		Body:    "Follow the link:\nhttps://tiny.url/verify?token=abc=",
+	}, date)
+
+	require.NoError(t, err)
+	require.Equal(t, "From: noreply@tiny.url\r\n"+
+		"To: sirius@gmail.com\r\n"+
+		"Subject: =?utf-8?q?=D0=9F=D1=80=D0=B8=D0=B2=D0=B5=D1=82?=\r\n"+
+		"Date: Sun, 09 May 2021 15:29:18 +0000\r\n"+
+		"MIME-Version: 1.0\r\n"+
+		"Content-Type: text/plain; charset=utf-8\r\n"+
+		"Content-Transfer-Encoding: quoted-printable\r\n\r\n"+
+		"Follow the link:\r\nhttps://tiny.url/verify?token=3Dabc=3D", string(data))
+}
+
+func TestFormatErrInvalidHeader(t *testing.T) {
+	_, err := format("noreply@tiny.url", Message{To: "sirius@gmail.com\r\nBcc: regulus@gmail.com"}, time.Now())
+
+	require.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestFileSender_Send(t *testing.T) {
+	dir := t.TempDir()
+	s := NewFileSender(dir, "noreply@tiny.url")
+
+	require.NoError(t, s.Send(context.Background(), Message{To: "sirius@gmail.com", Subject: "First"}))
+	require.NoError(t, s.Send(context.Background(), Message{To: "sirius@gmail.com", Subject: "Second"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+
+	data, err := ioutil.ReadFile(files[1])
+	require.NoError(t, err)
+	require.Contains(t, string(data), "Subject: Second\r\n")
+}
+
+func TestWriterSender_Send(t *testing.T) {
+	var buf bytes.Buffer
+
+	s := NewWriterSender(&buf, "noreply@tiny.url")
+
+	require.NoError(t, s.Send(context.Background(), Message{To: "sirius@gmail.com", Subject: "Hello", Body: "Text"}))
+	require.Equal(t, "From: noreply@tiny.url\nTo: sirius@gmail.com\nSubject: Hello\n\nText\n", buf.String())
+}
+
+// startSMTPServer starts minimal SMTP server recording commands and data of single session, returns its port
+func startSMTPServer(t *testing.T) (int, <-chan []string) {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := l.Accept()
+
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+
+		r := textproto.NewReader(bufio.NewReader(conn))
+		w := textproto.NewWriter(bufio.NewWriter(conn))
+
+		_ = w.PrintfLine("220 localhost ready")
+
+		var lines []string
+
+		for {
+			line, err := r.ReadLine()
+
+			if err != nil {
+				return
+			}
+
+			lines = append(lines, line)
+
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				_ = w.PrintfLine("250 localhost")
+			case line == "DATA":
+				_ = w.PrintfLine("354 go ahead")
+
+				data, err := r.ReadDotLines()
+
+				if err != nil {
+					return
+				}
+
+				lines = append(lines, data...)
+				_ = w.PrintfLine("250 accepted")
+			case line == "QUIT":
+				_ = w.PrintfLine("221 bye")
+				received <- lines
+
+				return
+			default:
+				_ = w.PrintfLine("250 ok")
+			}
+		}
+	}()
+
+	return l.Addr().(*net.TCPAddr).Port, received
+}
+
+func TestSMTPSender_Send(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   string
+		header string
+	}{
+		{
+			name:   "address",
+			from:   "noreply@tiny.url",
+			header: "From: noreply@tiny.url",
+		},
+		{
+			name:   "display name",
+			from:   "Tiny URL <noreply@tiny.url>",
+			header: "From: Tiny URL <noreply@tiny.url>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			port, received := startSMTPServer(t)
+
+			s, err := NewSMTPSender("127.0.0.1", port, "", "", tt.from)
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			require.NoError(t, s.Send(ctx, Message{To: "sirius@gmail.com", Subject: "Hello", Body: "Text"}))
+
+			lines := <-received
+
+			// Display name is kept only in header
+			require.Contains(t, lines, "MAIL FROM:<noreply@tiny.url>")
+			require.Contains(t, lines, "RCPT TO:<sirius@gmail.com>")
+			require.Contains(t, lines, tt.header)
+			require.Contains(t, lines, "Subject: Hello")
+			require.Contains(t, lines, "Text")
+		})
+	}
+}
+
+func TestNewSMTPSenderErrInvalidSender(t *testing.T) {
+	_, err := NewSMTPSender("127.0.0.1", 25, "", "", "Tiny URL")
+
+	require.Error(t, err)
+}
@@ -0,0 +1,213 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/net/html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Max size of page body to be read while looking for metadata
+const maxBodyBytes = 1 << 20
+
+var (
+	ErrBadStatus = errors.New("page responded with unexpected status")
+	ErrNotHTML   = errors.New("page is not html")
+)
+
+// Metadata describes page located by URL
+type Metadata struct {
+	Title       string
+	Description string
+	Favicon     string
+	Image       string
+}
+
+// Fetcher provides fetching metadata of pages
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (Metadata, error)
+}
+
+// HTMLFetcher downloads page and reads metadata from its head
+type HTMLFetcher struct {
+	client *http.Client
+}
+
+func NewHTMLFetcher(timeout time.Duration, transport http.RoundTripper) *HTMLFetcher {
+	return &HTMLFetcher{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(transport),
+		},
+	}
+}
+
+// Fetch downloads page by url and parses title, description, favicon and Open Graph image
+func (f *HTMLFetcher) Fetch(ctx context.Context, rawURL string) (Metadata, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	req.Header.Set("Accept", "text/html")
+
+	res, err := f.client.Do(req)
+
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return Metadata{}, fmt.Errorf("%w: %d", ErrBadStatus, res.StatusCode)
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(res.Header.Get("Content-Type")); err == nil && mediaType != "text/html" {
+		return Metadata{}, ErrNotHTML
+	}
+
+	meta, err := parse(io.LimitReader(res.Body, maxBodyBytes))
+
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	// Relative links are resolved against final location of page
+	base := res.Request.URL
+
+	if meta.Favicon == "" {
+		meta.Favicon = "/favicon.ico"
+	}
+
+	meta.Favicon = resolve(base, meta.Favicon)
+	meta.Image = resolve(base, meta.Image)
+
+	return meta, nil
+}
+
+// parse reads metadata from html document until end of head
+func parse(r io.Reader) (Metadata, error) {
+	var meta Metadata
+	var ogTitle, ogDescription string
+
+	z := html.NewTokenizer(r)
+
+	inTitle := false
+
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return merge(meta, ogTitle, ogDescription), nil
+			}
+
+			return Metadata{}, z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+
+			switch string(name) {
+			case "title":
+				inTitle = meta.Title == ""
+			case "body":
+				return merge(meta, ogTitle, ogDescription), nil
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+
+				attrs := attributes(z)
+				key := strings.ToLower(attrs["property"])
+
+				if key == "" {
+					key = strings.ToLower(attrs["name"])
+				}
+
+				switch key {
+				case "description":
+					meta.Description = attrs["content"]
+				case "og:title":
+					ogTitle = attrs["content"]
+				case "og:description":
+					ogDescription = attrs["content"]
+				case "og:image":
+					meta.Image = attrs["content"]
+				}
+			case "link":
+				if !hasAttr {
+					continue
+				}
+
+				attrs := attributes(z)
+
+				for _, rel := range strings.Fields(strings.ToLower(attrs["rel"])) {
+					if rel == "icon" && meta.Favicon == "" {
+						meta.Favicon = attrs["href"]
+					}
+				}
+			}
+		case html.TextToken:
+			if inTitle {
+				meta.Title = strings.TrimSpace(string(z.Text()))
+				inTitle = false
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				return merge(meta, ogTitle, ogDescription), nil
+			}
+		}
+	}
+}
+
+// merge fills missing fields with Open Graph values
+func merge(meta Metadata, ogTitle, ogDescription string) Metadata {
+	if meta.Title == "" {
+		meta.Title = ogTitle
+	}
+
+	if meta.Description == "" {
+		meta.Description = ogDescription
+	}
+
+	return meta
+}
+
+func attributes(z *html.Tokenizer) map[string]string {
+	attrs := make(map[string]string)
+
+	for {
+		key, val, more := z.TagAttr()
+		attrs[strings.ToLower(string(key))] = strings.TrimSpace(string(val))
+
+		if !more {
+			return attrs
+		}
+	}
+}
+
+func resolve(base *url.URL, ref string) string {
+	if ref == "" {
+		return ""
+	}
+
+	u, err := base.Parse(ref)
+
+	if err != nil {
+		return ""
+	}
+
+	return u.String()
+}
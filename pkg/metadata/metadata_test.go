@@ -0,0 +1,112 @@
+package metadata
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/pkg/safehttp"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+	<title> Example page </title>
+	<meta name="description" content="Page for tests">
+	<meta property="og:image" content="/static/preview.png">
+	<link rel="shortcut icon" href="/static/icon.png">
+</head>
+<body>
+	<title>Not a title</title>
+</body>
+</html>`
+
+const ogPage = `<html><head>
+	<meta property="og:title" content="Open Graph title">
+	<meta property="og:description" content="Open Graph description">
+</head></html>`
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	})
+	mux.HandleFunc("/og", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(ogPage))
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestHTMLFetcher_Fetch(t *testing.T) {
+	srv := newServer(t)
+	f := NewHTMLFetcher(time.Second, http.DefaultTransport)
+
+	meta, err := f.Fetch(context.Background(), srv.URL+"/page")
+
+	require.NoError(t, err)
+	require.Equal(t, Metadata{
+		Title:       "Example page",
+		Description: "Page for tests",
+		Favicon:     srv.URL + "/static/icon.png",
+		Image:       srv.URL + "/static/preview.png",
+	}, meta)
+}
+
+func TestHTMLFetcher_FetchOpenGraph(t *testing.T) {
+	srv := newServer(t)
+	f := NewHTMLFetcher(time.Second, http.DefaultTransport)
+
+	meta, err := f.Fetch(context.Background(), srv.URL+"/og")
+
+	require.NoError(t, err)
+	require.Equal(t, Metadata{
+		Title:       "Open Graph title",
+		Description: "Open Graph description",
+		Favicon:     srv.URL + "/favicon.ico",
+	}, meta)
+}
+
+func TestHTMLFetcher_FetchErrNotHTML(t *testing.T) {
+	srv := newServer(t)
+	f := NewHTMLFetcher(time.Second, http.DefaultTransport)
+
+	_, err := f.Fetch(context.Background(), srv.URL+"/json")
+
+	require.ErrorIs(t, err, ErrNotHTML)
+}
+
+func TestHTMLFetcher_FetchErrBadStatus(t *testing.T) {
+	srv := newServer(t)
+	f := NewHTMLFetcher(time.Second, http.DefaultTransport)
+
+	_, err := f.Fetch(context.Background(), srv.URL+"/missing")
+
+	require.ErrorIs(t, err, ErrBadStatus)
+}
+
+func TestHTMLFetcher_FetchErrForbiddenAddress(t *testing.T) {
+	srv := newServer(t)
+	f := NewHTMLFetcher(time.Second, safehttp.NewTransport())
+
+	_, err := f.Fetch(context.Background(), srv.URL+"/page")
+
+	require.ErrorIs(t, err, safehttp.ErrForbiddenAddress)
+}
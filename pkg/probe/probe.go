@@ -0,0 +1,143 @@
+package probe
+
+import (
+	"context"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// Max count of hosts remembered by rate limiter before pruning
+const maxHosts = 1024
+
+// Result of probing URL
+type Result struct {
+	StatusCode int
+	Latency    time.Duration
+}
+
+// Prober provides checking of URL availability
+type Prober interface {
+	Probe(ctx context.Context, url string) (Result, error)
+}
+
+// HTTPProber sends HEAD request to URL and falls back to GET if HEAD is not supported.
+// Requests to the same host are spaced out by host interval
+type HTTPProber struct {
+	client       *http.Client
+	hostInterval time.Duration
+
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+func NewHTTPProber(timeout time.Duration, hostInterval time.Duration, transport http.RoundTripper) *HTTPProber {
+	return &HTTPProber{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(transport),
+		},
+		hostInterval: hostInterval,
+		next:         make(map[string]time.Time),
+	}
+}
+
+func (p *HTTPProber) Probe(ctx context.Context, rawURL string) (Result, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	res, err := p.do(ctx, http.MethodHead, u)
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	if res.StatusCode == http.StatusMethodNotAllowed || res.StatusCode == http.StatusNotImplemented {
+		return p.do(ctx, http.MethodGet, u)
+	}
+
+	return res, nil
+}
+
+func (p *HTTPProber) do(ctx context.Context, method string, u *url.URL) (Result, error) {
+	if err := p.wait(ctx, u.Host); err != nil {
+		return Result{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	start := time.Now()
+
+	res, err := p.client.Do(req)
+
+	if err != nil {
+		return Result{}, err
+	}
+
+	latency := time.Since(start)
+
+	// Drain small part of body for connection reuse
+	_, _ = io.CopyN(ioutil.Discard, res.Body, 4<<10)
+	_ = res.Body.Close()
+
+	return Result{
+		StatusCode: res.StatusCode,
+		Latency:    latency,
+	}, nil
+}
+
+// wait blocks until request to host is allowed
+func (p *HTTPProber) wait(ctx context.Context, host string) error {
+	if p.hostInterval <= 0 {
+		return nil
+	}
+
+	p.mu.Lock()
+
+	now := time.Now()
+
+	if len(p.next) >= maxHosts {
+		for h, t := range p.next {
+			if t.Before(now) {
+				delete(p.next, h)
+			}
+		}
+	}
+
+	at := p.next[host]
+
+	if at.Before(now) {
+		at = now
+	}
+
+	p.next[host] = at.Add(p.hostInterval)
+
+	p.mu.Unlock()
+
+	delay := at.Sub(now)
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
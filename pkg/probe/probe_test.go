@@ -0,0 +1,91 @@
+package probe
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/pkg/safehttp"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPProber_Probe(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodHead, r.Method)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPProber(time.Second, 0, http.DefaultTransport)
+
+	res, err := p.Probe(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestHTTPProber_ProbeFallbackToGet(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	p := NewHTTPProber(time.Second, 0, http.DefaultTransport)
+
+	res, err := p.Probe(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestHTTPProber_ProbeHostInterval(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	interval := 100 * time.Millisecond
+	p := NewHTTPProber(time.Second, interval, http.DefaultTransport)
+
+	start := time.Now()
+
+	for i := 0; i < 3; i++ {
+		_, err := p.Probe(context.Background(), srv.URL)
+
+		require.NoError(t, err)
+	}
+
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(2*interval))
+}
+
+func TestHTTPProber_ProbeCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p := NewHTTPProber(time.Second, time.Hour, http.DefaultTransport)
+
+	_, err := p.Probe(context.Background(), srv.URL)
+
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = p.Probe(ctx, srv.URL)
+
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHTTPProber_ProbeErrForbiddenAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p := NewHTTPProber(time.Second, 0, safehttp.NewTransport())
+
+	_, err := p.Probe(context.Background(), srv.URL)
+
+	require.ErrorIs(t, err, safehttp.ErrForbiddenAddress)
+}
@@ -0,0 +1,56 @@
+package safehttp
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{ip: "8.8.8.8", public: true},
+		{ip: "2a00:1450:4001:80b::200e", public: true},
+		{ip: "127.0.0.1", public: false},
+		{ip: "10.1.2.3", public: false},
+		{ip: "172.20.0.1", public: false},
+		{ip: "192.168.1.1", public: false},
+		{ip: "169.254.169.254", public: false},
+		{ip: "0.0.0.0", public: false},
+		{ip: "::1", public: false},
+		{ip: "::ffff:127.0.0.1", public: false},
+		{ip: "fd00::1", public: false},
+		{ip: "fe80::1", public: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			require.Equal(t, tt.public, IsPublic(net.ParseIP(tt.ip)))
+		})
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached loopback server")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewTransport()}
+
+	// Loopback address is rejected given directly and through resolving of host
+	for _, url := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+		require.NoError(t, err)
+
+		_, err = client.Do(req)
+
+		require.ErrorIs(t, err, ErrForbiddenAddress)
+	}
+}
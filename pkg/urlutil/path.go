@@ -0,0 +1,61 @@
+package urlutil
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+)
+
+var ErrUnsafePath = errors.New("path cannot be joined safely")
+
+// JoinPath appends escaped relative path to path of rawURL, keeping its query and fragment.
+// Path must not contain dot segments, empty segments, backslashes or encoded slashes,
+// so result always stays under path of rawURL on the same host
+func JoinPath(rawURL string, path string) (string, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	if path == "" {
+		return rawURL, nil
+	}
+
+	lower := strings.ToLower(path)
+
+	if strings.ContainsAny(path, `\?#`) || strings.Contains(lower, "%2f") || strings.Contains(lower, "%5c") {
+		return "", ErrUnsafePath
+	}
+
+	segments := strings.Split(path, "/")
+
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+
+		if err != nil {
+			return "", ErrUnsafePath
+		}
+
+		// Only trailing slash is allowed to produce empty segment
+		if unescaped == "" && i != len(segments)-1 || unescaped == "." || unescaped == ".." {
+			return "", ErrUnsafePath
+		}
+
+		// Segments are escaped again for consistent encoding of result
+		segments[i] = url.PathEscape(unescaped)
+	}
+
+	escaped := strings.TrimSuffix(u.EscapedPath(), "/") + "/" + strings.Join(segments, "/")
+
+	unescaped, err := url.PathUnescape(escaped)
+
+	if err != nil {
+		return "", err
+	}
+
+	u.Path = unescaped
+	u.RawPath = escaped
+
+	return u.String(), nil
+}
@@ -0,0 +1,94 @@
+package urlutil
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestJoinPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		path     string
+		expected string
+	}{
+		{
+			name:     "empty path",
+			rawURL:   "https://docs.example.com",
+			expected: "https://docs.example.com",
+		},
+		{
+			name:     "without base path",
+			rawURL:   "https://docs.example.com",
+			path:     "getting-started",
+			expected: "https://docs.example.com/getting-started",
+		},
+		{
+			name:     "with base path",
+			rawURL:   "https://example.com/docs/",
+			path:     "getting-started/install",
+			expected: "https://example.com/docs/getting-started/install",
+		},
+		{
+			name:     "keep query and fragment",
+			rawURL:   "https://example.com/docs?lang=en#top",
+			path:     "getting-started",
+			expected: "https://example.com/docs/getting-started?lang=en#top",
+		},
+		{
+			name:     "trailing slash",
+			rawURL:   "https://example.com/docs",
+			path:     "guides/",
+			expected: "https://example.com/docs/guides/",
+		},
+		{
+			name:     "escaped characters",
+			rawURL:   "https://example.com/docs",
+			path:     "hello%20world/caf%C3%A9",
+			expected: "https://example.com/docs/hello%20world/caf%C3%A9",
+		},
+		{
+			name:     "host like segment",
+			rawURL:   "https://example.com",
+			path:     "evil.com",
+			expected: "https://example.com/evil.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := JoinPath(tt.rawURL, tt.path)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestJoinPathErrUnsafePath(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "parent segment", path: "../admin"},
+		{name: "nested parent segment", path: "guides/../../admin"},
+		{name: "current segment", path: "./guides"},
+		{name: "encoded parent segment", path: "%2e%2e/admin"},
+		{name: "encoded slash", path: "..%2Fadmin"},
+		{name: "lowercase encoded slash", path: "guides%2fadmin"},
+		{name: "encoded backslash", path: "..%5Cadmin"},
+		{name: "backslash", path: `..\admin`},
+		{name: "empty segment", path: "/evil.com"},
+		{name: "double slash", path: "guides//evil.com"},
+		{name: "query", path: "guides?next=https://evil.com"},
+		{name: "invalid escape", path: "guides%zz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := JoinPath("https://example.com/docs", tt.path)
+
+			require.ErrorIs(t, err, ErrUnsafePath)
+		})
+	}
+}
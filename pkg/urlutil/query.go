@@ -0,0 +1,54 @@
+package urlutil
+
+import (
+	"net/url"
+)
+
+// SetQuery replaces query parameters of rawURL with values, keeping other parameters and fragment
+func SetQuery(rawURL string, values url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(values) == 0 {
+		return rawURL, nil
+	}
+
+	query := u.Query()
+
+	for key, vals := range values {
+		query[key] = vals
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// MergeQuery adds query parameters to rawURL. Parameters already present in rawURL take precedence
+// and are never overridden
+func MergeQuery(rawURL string, values url.Values) (string, error) {
+	u, err := url.Parse(rawURL)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(values) == 0 {
+		return rawURL, nil
+	}
+
+	query := u.Query()
+
+	for key, vals := range values {
+		if _, ok := query[key]; !ok {
+			query[key] = vals
+		}
+	}
+
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
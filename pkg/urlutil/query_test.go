@@ -0,0 +1,85 @@
+package urlutil
+
+import (
+	"github.com/stretchr/testify/require"
+	"net/url"
+	"testing"
+)
+
+func TestSetQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		values   url.Values
+		expected string
+	}{
+		{
+			name:     "no values",
+			rawURL:   "https://example.com/path?b=2&a=1",
+			expected: "https://example.com/path?b=2&a=1",
+		},
+		{
+			name:     "add values",
+			rawURL:   "https://example.com/path",
+			values:   url.Values{"utm_source": {"mail"}},
+			expected: "https://example.com/path?utm_source=mail",
+		},
+		{
+			name:     "replace values and keep fragment",
+			rawURL:   "https://example.com/?utm_source=old&ref=1#top",
+			values:   url.Values{"utm_source": {"new value"}},
+			expected: "https://example.com/?ref=1&utm_source=new+value#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := SetQuery(tt.rawURL, tt.values)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestMergeQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		rawURL   string
+		values   url.Values
+		expected string
+	}{
+		{
+			name:     "no values",
+			rawURL:   "https://example.com/?a=1",
+			expected: "https://example.com/?a=1",
+		},
+		{
+			name:     "add values",
+			rawURL:   "https://example.com/?a=1",
+			values:   url.Values{"b": {"2", "3"}},
+			expected: "https://example.com/?a=1&b=2&b=3",
+		},
+		{
+			name:     "original values win",
+			rawURL:   "https://example.com/?utm_source=mail#top",
+			values:   url.Values{"utm_source": {"spoofed"}, "ref": {"x"}},
+			expected: "https://example.com/?ref=x&utm_source=mail#top",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := MergeQuery(tt.rawURL, tt.values)
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, res)
+		})
+	}
+}
+
+func TestSetQueryErr(t *testing.T) {
+	_, err := SetQuery("://", url.Values{"a": {"1"}})
+
+	require.Error(t, err)
+}
@@ -0,0 +1,93 @@
+package useragent
+
+import "strings"
+
+// Operating systems
+const (
+	OSiOS     = "ios"
+	OSAndroid = "android"
+	OSWindows = "windows"
+	OSMacOS   = "macos"
+	OSLinux   = "linux"
+	OSOther   = "other"
+)
+
+// Types of devices
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+	DeviceOther   = "other"
+)
+
+// Browsers
+const (
+	BrowserChrome  = "chrome"
+	BrowserFirefox = "firefox"
+	BrowserSafari  = "safari"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+	BrowserOther   = "other"
+)
+
+// Agent describes client by its User-Agent header
+type Agent struct {
+	OS      string
+	Device  string
+	Browser string
+}
+
+// Parse detects operating system, device type and browser from User-Agent header
+func Parse(ua string) Agent {
+	agent := platform(ua)
+	agent.Browser = browser(ua)
+
+	return agent
+}
+
+func platform(ua string) Agent {
+	switch {
+	case strings.Contains(ua, "iPad"):
+		return Agent{OS: OSiOS, Device: DeviceTablet}
+	case strings.Contains(ua, "iPhone"), strings.Contains(ua, "iPod"):
+		return Agent{OS: OSiOS, Device: DeviceMobile}
+	case strings.Contains(ua, "Android"):
+		// Android tablets do not send Mobile token
+		if strings.Contains(ua, "Mobile") {
+			return Agent{OS: OSAndroid, Device: DeviceMobile}
+		}
+
+		return Agent{OS: OSAndroid, Device: DeviceTablet}
+	case strings.Contains(ua, "Windows Phone"):
+		return Agent{OS: OSWindows, Device: DeviceMobile}
+	case strings.Contains(ua, "Windows"):
+		return Agent{OS: OSWindows, Device: DeviceDesktop}
+	case strings.Contains(ua, "Macintosh"), strings.Contains(ua, "Mac OS X"):
+		return Agent{OS: OSMacOS, Device: DeviceDesktop}
+	case strings.Contains(ua, "Linux"), strings.Contains(ua, "X11"):
+		return Agent{OS: OSLinux, Device: DeviceDesktop}
+	}
+
+	return Agent{OS: OSOther, Device: DeviceOther}
+}
+
+// browser is detected by its own token, checked before tokens of engines it claims compatibility with
+func browser(ua string) string {
+	switch {
+	case strings.Contains(ua, "Edg/"), strings.Contains(ua, "EdgA/"), strings.Contains(ua, "EdgiOS/"):
+		return BrowserEdge
+	case strings.Contains(ua, "OPR/"), strings.Contains(ua, "Opera"):
+		return BrowserOpera
+	case strings.Contains(ua, "SamsungBrowser/"):
+		return BrowserSamsung
+	case strings.Contains(ua, "Firefox/"), strings.Contains(ua, "FxiOS/"):
+		return BrowserFirefox
+	case strings.Contains(ua, "Chrome/"), strings.Contains(ua, "CriOS/"):
+		return BrowserChrome
+	case strings.Contains(ua, "Safari/"):
+		return BrowserSafari
+	}
+
+	return BrowserOther
+}
@@ -0,0 +1,56 @@
+package useragent
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		ua       string
+		expected Agent
+	}{
+		{
+			ua:       "Mozilla/5.0 (iPhone; CPU iPhone OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1",
+			expected: Agent{OS: OSiOS, Device: DeviceMobile, Browser: BrowserSafari},
+		},
+		{
+			ua:       "Mozilla/5.0 (iPad; CPU OS 14_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Mobile/15E148 Safari/604.1",
+			expected: Agent{OS: OSiOS, Device: DeviceTablet, Browser: BrowserSafari},
+		},
+		{
+			ua:       "Mozilla/5.0 (Linux; Android 11; Pixel 5) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Mobile Safari/537.36",
+			expected: Agent{OS: OSAndroid, Device: DeviceMobile, Browser: BrowserChrome},
+		},
+		{
+			ua:       "Mozilla/5.0 (Linux; Android 11; SM-T870) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.120 Safari/537.36",
+			expected: Agent{OS: OSAndroid, Device: DeviceTablet, Browser: BrowserChrome},
+		},
+		{
+			ua:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36",
+			expected: Agent{OS: OSWindows, Device: DeviceDesktop, Browser: BrowserChrome},
+		},
+		{
+			ua:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/14.1.1 Safari/605.1.15",
+			expected: Agent{OS: OSMacOS, Device: DeviceDesktop, Browser: BrowserSafari},
+		},
+		{
+			ua:       "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:89.0) Gecko/20100101 Firefox/89.0",
+			expected: Agent{OS: OSLinux, Device: DeviceDesktop, Browser: BrowserFirefox},
+		},
+		{
+			ua:       "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36 Edg/91.0.864.59",
+			expected: Agent{OS: OSWindows, Device: DeviceDesktop, Browser: BrowserEdge},
+		},
+		{
+			ua:       "curl/7.68.0",
+			expected: Agent{OS: OSOther, Device: DeviceOther, Browser: BrowserOther},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ua, func(t *testing.T) {
+			require.Equal(t, tt.expected, Parse(tt.ua))
+		})
+	}
+}
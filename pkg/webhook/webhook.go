@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	// Upper bound of delay between attempts
+	maxBackoff = time.Hour
+)
+
+var ErrBadStatus = errors.New("receiver responded with unexpected status")
+
+// Sender provides delivering of signed payloads
+type Sender interface {
+	Send(ctx context.Context, url, secret string, payload []byte) (int, error)
+}
+
+// HTTPSender posts payloads signed with HMAC-SHA256
+type HTTPSender struct {
+	client *http.Client
+}
+
+func NewHTTPSender(timeout time.Duration, transport http.RoundTripper) *HTTPSender {
+	return &HTTPSender{
+		client: &http.Client{
+			Timeout:   timeout,
+			Transport: otelhttp.NewTransport(transport),
+		},
+	}
+}
+
+// Send posts payload to url and returns status code of response. Any status except 2xx is error
+func (s *HTTPSender) Send(ctx context.Context, url, secret string, payload []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, payload))
+
+	res, err := s.client.Do(req)
+
+	if err != nil {
+		return 0, err
+	}
+
+	_, _ = io.CopyN(ioutil.Discard, res.Body, 4<<10)
+	_ = res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("%w: %d", ErrBadStatus, res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
+
+// Sign creates signature of payload sent at timestamp in format sha256=<hex>
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	_, _ = mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks signature of payload sent at timestamp
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, payload)), []byte(signature))
+}
+
+// Backoff returns exponentially growing delay after failed attempt starting from 1
+func Backoff(attempt int, base time.Duration) time.Duration {
+	delay := base
+
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+
+		if delay >= maxBackoff {
+			return maxBackoff
+		}
+	}
+
+	return delay
+}
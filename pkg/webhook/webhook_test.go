@@ -0,0 +1,79 @@
+package webhook
+
+import (
+	"context"
+	"github.com/mebr0/tiny-url/pkg/safehttp"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	signature := Sign("secret", 1620000000, []byte(`{}`))
+
+	require.Equal(t, "sha256=4de1b55993ef1e88b33397d145aef387b2cc39c5b78bd4300ced725aeffb8e0b", signature)
+	require.True(t, Verify("secret", 1620000000, []byte(`{}`), signature))
+	require.False(t, Verify("other", 1620000000, []byte(`{}`), signature))
+	require.False(t, Verify("secret", 1620000001, []byte(`{}`), signature))
+}
+
+func TestHTTPSender_Send(t *testing.T) {
+	payload := []byte(`{"type":"url.created"}`)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, payload, body)
+		require.True(t, Verify("secret", timestamp, body, r.Header.Get(SignatureHeader)))
+
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSender(time.Second, http.DefaultTransport)
+
+	status, err := s.Send(context.Background(), srv.URL, "secret", payload)
+
+	require.NoError(t, err)
+	require.Equal(t, http.StatusAccepted, status)
+}
+
+func TestHTTPSender_SendErrBadStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSender(time.Second, http.DefaultTransport)
+
+	status, err := s.Send(context.Background(), srv.URL, "secret", []byte(`{}`))
+
+	require.ErrorIs(t, err, ErrBadStatus)
+	require.Equal(t, http.StatusInternalServerError, status)
+}
+
+func TestHTTPSender_SendErrForbiddenAddress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("payload reached loopback receiver")
+	}))
+	defer srv.Close()
+
+	s := NewHTTPSender(time.Second, safehttp.NewTransport())
+
+	_, err := s.Send(context.Background(), srv.URL, "secret", []byte(`{}`))
+
+	require.ErrorIs(t, err, safehttp.ErrForbiddenAddress)
+}
+
+func TestBackoff(t *testing.T) {
+	require.Equal(t, time.Second, Backoff(1, time.Second))
+	require.Equal(t, 2*time.Second, Backoff(2, time.Second))
+	require.Equal(t, 8*time.Second, Backoff(4, time.Second))
+	require.Equal(t, time.Hour, Backoff(100, time.Second))
+}